@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+)
+
+// api calls the server's REST endpoints
+type api struct {
+	base     string // e.g. http://localhost:8080
+	adminKey string
+	client   *http.Client
+}
+
+func newAPI(base, adminKey string) *api {
+	return &api{
+		base:     strings.TrimRight(base, "/"),
+		adminKey: adminKey,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// wsURL returns the WebSocket URL for a path on the server
+func (a *api) wsURL(path string) string {
+	if rest, ok := strings.CutPrefix(a.base, "https://"); ok {
+		return "wss://" + rest + path
+	}
+	return "ws://" + strings.TrimPrefix(a.base, "http://") + path
+}
+
+// do sends a request with a JSON body and decodes a JSON response into out.
+// Returns an error for any status other than want.
+func (a *api) do(method, path string, body, out any, want int, bearer string) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// joinedTeam is what POST /events/join returns that a team needs to connect
+type joinedTeam struct {
+	ID          int    `json:"id"`
+	MemberToken string `json:"memberToken"`
+}
+
+// join registers a new team in the event with the passkey
+func (a *api) join(passkey, teamName string) (*joinedTeam, error) {
+	var team joinedTeam
+	err := a.do(http.MethodPost, "/events/join", map[string]string{
+		"teamName": teamName,
+		"passkey":  passkey,
+	}, &team, http.StatusCreated, "")
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// createDraftRoom opens the event's draft room
+func (a *api) createDraftRoom(eventID int) error {
+	return a.do(http.MethodPost, fmt.Sprintf("/events/%d/draft-room", eventID), nil, nil, http.StatusCreated, "")
+}
+
+// startDraft starts the event's draft as the commissioner
+func (a *api) startDraft(eventID int, pickOrder []int, rounds int, timer time.Duration) error {
+	return a.do(http.MethodPost, fmt.Sprintf("/events/%d/draft/start", eventID), map[string]any{
+		"pickOrder":     pickOrder,
+		"totalRounds":   rounds,
+		"timerDuration": int(timer.Seconds()),
+	}, nil, http.StatusOK, a.adminKey)
+}
+
+// createMockDraft starts a mock draft of the event against bot teams for the
+// team whose member token is memberToken
+func (a *api) createMockDraft(eventID int, memberToken string, teams, rounds int) (*draft.MockDraft, error) {
+	var mock draft.MockDraft
+	err := a.do(http.MethodPost, fmt.Sprintf("/events/%d/mock-drafts", eventID), map[string]int{
+		"teams":  teams,
+		"rounds": rounds,
+	}, &mock, http.StatusCreated, memberToken)
+	if err != nil {
+		return nil, err
+	}
+	return &mock, nil
+}
+
+// deleteMockDraft ends a mock draft
+func (a *api) deleteMockDraft(id string) error {
+	return a.do(http.MethodDelete, "/mock-drafts/"+id, nil, nil, http.StatusNoContent, "")
+}
+
+// metrics reads the server's send queue and runtime statistics as the commissioner
+func (a *api) metrics() (draft.Metrics, error) {
+	var m draft.Metrics
+	err := a.do(http.MethodGet, "/draft/metrics", nil, &m, http.StatusOK, a.adminKey)
+	return m, err
+}
@@ -0,0 +1,274 @@
+// Command loadtest drafts against a running server with fake teams over real
+// WebSocket connections, and reports how the draft room held up: pick latency
+// and broadcast fan-out percentiles, dropped messages, and the server's
+// goroutine and memory growth.
+//
+// The event must have players and no teams yet; see README.md.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// options are the load test's command-line flags
+type options struct {
+	server   string
+	eventID  int
+	passkey  string
+	adminKey string
+
+	teams  int
+	rounds int
+	timer  time.Duration
+	rooms  int
+
+	thinkMin time.Duration
+	thinkMax time.Duration
+
+	stormEvery    time.Duration
+	stormFraction float64
+
+	maxP99  time.Duration
+	timeout time.Duration
+	seed    int64
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.server, "server", "http://localhost:8080", "server to test")
+	flag.IntVar(&opts.eventID, "event", 0, "event to draft; it must have players and no teams yet")
+	flag.StringVar(&opts.passkey, "passkey", "", "the event's passkey")
+	flag.StringVar(&opts.adminKey, "admin-key", os.Getenv("ADMIN_KEY"), "the server's ADMIN_KEY, to start the draft")
+	flag.IntVar(&opts.teams, "teams", 12, "teams in each room (at most 12)")
+	flag.IntVar(&opts.rounds, "rounds", 6, "rounds in each draft")
+	flag.DurationVar(&opts.timer, "timer", 30*time.Second, "turn timer of the event draft")
+	flag.IntVar(&opts.rooms, "rooms", 1, "draft rooms at once; the server runs one event draft, so rooms after the first are mock drafts, each for one of the event's teams against bots")
+	flag.DurationVar(&opts.thinkMin, "think-min", 200*time.Millisecond, "shortest time a team takes to pick")
+	flag.DurationVar(&opts.thinkMax, "think-max", 2*time.Second, "longest time a team takes to pick; think times are uniform between the two")
+	flag.DurationVar(&opts.stormEvery, "storm-every", 0, "drop and reconnect teams this often (0 for no reconnect storms)")
+	flag.Float64Var(&opts.stormFraction, "storm-fraction", 1, "share of teams dropped in each reconnect storm")
+	flag.DurationVar(&opts.maxP99, "max-p99", 100*time.Millisecond, "fail if p99 pick latency is above this")
+	flag.DurationVar(&opts.timeout, "timeout", 15*time.Minute, "give up on drafts not finished by then")
+	flag.Int64Var(&opts.seed, "seed", 0, "seed for think times, picks and storms (0 for random)")
+	flag.Parse()
+
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := run(ctx, opts)
+	if err != nil {
+		log.Fatalf("Load test failed: %v", err)
+	}
+
+	var failures []string
+	if result.unfinished > 0 {
+		failures = append(failures, fmt.Sprintf("%d rooms didn't finish", result.unfinished))
+	}
+	if result.missed > 0 {
+		failures = append(failures, fmt.Sprintf("%d broadcasts missed", result.missed))
+	}
+	if result.p99 > opts.maxP99 {
+		failures = append(failures, fmt.Sprintf("p99 pick latency %s is over %s", result.p99, opts.maxP99))
+	}
+	for _, failure := range failures {
+		fmt.Println("FAIL:", failure)
+	}
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
+	fmt.Println("PASS")
+}
+
+func (o *options) validate() error {
+	switch {
+	case o.eventID <= 0:
+		return errors.New("-event is required")
+	case o.passkey == "":
+		return errors.New("-passkey is required")
+	case o.adminKey == "":
+		return errors.New("-admin-key or ADMIN_KEY is required")
+	case o.teams < 2 || o.teams > 12:
+		return errors.New("-teams must be between 2 and 12")
+	case o.rounds < 1:
+		return errors.New("-rounds must be at least 1")
+	case o.rooms < 1 || o.rooms > o.teams+1:
+		return errors.New("-rooms must be between 1 and one more than -teams")
+	case o.timer < time.Second:
+		return errors.New("-timer must be at least 1s")
+	case o.thinkMin < 0 || o.thinkMax < o.thinkMin:
+		return errors.New("-think-max must be at least -think-min")
+	case o.stormFraction <= 0 || o.stormFraction > 1:
+		return errors.New("-storm-fraction must be above 0 and at most 1")
+	}
+	if o.seed == 0 {
+		o.seed = time.Now().UnixNano()
+	}
+	return nil
+}
+
+// think returns a random think time
+func (o *options) think(rng *rand.Rand) time.Duration {
+	return o.thinkMin + time.Duration(rng.Int63n(int64(o.thinkMax-o.thinkMin)+1))
+}
+
+// run joins the teams, starts the drafts and waits for them to finish
+func run(ctx context.Context, opts options) (summary, error) {
+	server := newAPI(opts.server, opts.adminKey)
+	rec := &recorder{}
+	rng := rand.New(rand.NewSource(opts.seed))
+	log.Printf("Seed %d", opts.seed)
+
+	var samples serverSamples
+	before, err := server.metrics()
+	if err != nil {
+		return summary{}, fmt.Errorf("reading server metrics: %w", err)
+	}
+	samples.before = before
+	samples.sample(before)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	// The event draft: every team joins and connects before the draft starts,
+	// so the start is broadcast to the whole room
+	event := newRoom(fmt.Sprintf("event %d", opts.eventID))
+	var joinedTeams []*joinedTeam
+	var pickOrder []int
+	for i := 1; i <= opts.teams; i++ {
+		joined, err := server.join(opts.passkey, fmt.Sprintf("Load Team %d", i))
+		if err != nil {
+			return summary{}, fmt.Errorf("joining team %d (the event must have no teams yet): %w", i, err)
+		}
+		query := url.Values{"protocol": {"2"}}
+		newTeam(joined.ID, joined.MemberToken, server.wsURL("/ws/draft?"+query.Encode()), event, rec, rng.Int63(), opts.think)
+		joinedTeams = append(joinedTeams, joined)
+		pickOrder = append(pickOrder, joined.ID)
+	}
+	if err := server.createDraftRoom(opts.eventID); err != nil {
+		return summary{}, fmt.Errorf("creating the draft room: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	startTeams := func(r *room) {
+		for _, t := range r.teams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.run(ctx)
+			}()
+		}
+	}
+	startTeams(event)
+	for _, t := range event.teams {
+		select {
+		case <-t.connected:
+		case <-ctx.Done():
+			return summary{}, fmt.Errorf("connecting teams: %w", ctx.Err())
+		}
+	}
+
+	started := time.Now()
+	rng.Shuffle(len(pickOrder), func(i, j int) { pickOrder[i], pickOrder[j] = pickOrder[j], pickOrder[i] })
+	if err := server.startDraft(opts.eventID, pickOrder, opts.rounds, opts.timer); err != nil {
+		return summary{}, fmt.Errorf("starting the draft: %w", err)
+	}
+	log.Printf("Event %d draft started: %d teams, %d rounds", opts.eventID, opts.teams, opts.rounds)
+
+	// The other rooms are mock drafts, one for each of the first event teams,
+	// which start as soon as they're created
+	rooms := []*room{event}
+	var mockIDs []string
+	for i := 2; i <= opts.rooms; i++ {
+		joined := joinedTeams[i-2]
+		mock, err := server.createMockDraft(opts.eventID, joined.MemberToken, opts.teams, opts.rounds)
+		if err != nil {
+			return summary{}, fmt.Errorf("creating mock draft %d: %w", i, err)
+		}
+		mockIDs = append(mockIDs, mock.ID)
+
+		r := newRoom("mock draft " + mock.ID)
+		query := url.Values{"protocol": {"2"}, "mockID": {mock.ID}}
+		newTeam(joined.ID, joined.MemberToken, server.wsURL("/ws/draft?"+query.Encode()), r, rec, rng.Int63(), opts.think)
+		startTeams(r)
+		rooms = append(rooms, r)
+	}
+	if opts.rooms > 1 {
+		log.Printf("%d mock drafts started", opts.rooms-1)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	sampler := time.NewTicker(time.Second)
+	defer sampler.Stop()
+	var storms <-chan time.Time
+	if opts.stormEvery > 0 {
+		ticker := time.NewTicker(opts.stormEvery)
+		defer ticker.Stop()
+		storms = ticker.C
+	}
+
+wait:
+	for {
+		select {
+		case <-finished:
+			break wait
+		case <-sampler.C:
+			if m, err := server.metrics(); err == nil {
+				samples.sample(m)
+			}
+		case <-storms:
+			dropped := 0
+			for _, r := range rooms {
+				for _, t := range r.teams {
+					if rng.Float64() < opts.stormFraction {
+						t.drop()
+						dropped++
+					}
+				}
+			}
+			log.Printf("Reconnect storm: dropped %d teams", dropped)
+		}
+	}
+	elapsed := time.Since(started)
+	if ctx.Err() != nil {
+		log.Printf("Stopped before every draft finished: %v", ctx.Err())
+	}
+
+	// Finished mock drafts stay open for review; close them, then give the
+	// server a moment to clean up after the last connections
+	for _, id := range mockIDs {
+		if err := server.deleteMockDraft(id); err != nil {
+			log.Printf("Closing mock draft %s: %v", id, err)
+		}
+	}
+	time.Sleep(2 * time.Second)
+	after, err := server.metrics()
+	if err != nil {
+		return summary{}, fmt.Errorf("reading server metrics: %w", err)
+	}
+	samples.after = after
+	samples.sample(after)
+
+	return report(os.Stdout, rooms, rec, samples, elapsed), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+)
+
+// recorder collects what every team observed
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration // make_pick sent to pick_made received, once per team
+
+	received        atomic.Int64 // Messages received
+	missed          atomic.Int64 // Broadcasts skipped over in a team's seq
+	duplicates      atomic.Int64 // Broadcasts received twice
+	errors          atomic.Int64 // error messages, and messages that weren't JSON
+	picksSent       atomic.Int64
+	autoPicks       atomic.Int64 // Picks made for a fake team when its timer ran out
+	reconnects      atomic.Int64
+	snapshots       atomic.Int64 // Reconnects sent draft_state instead of resuming
+	connectFailures atomic.Int64
+}
+
+func (r *recorder) latency(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, d)
+}
+
+// serverSamples tracks the server's metrics over the run
+type serverSamples struct {
+	before, after  draft.Metrics
+	peakGoroutines int
+	peakHeap       uint64
+	peakQueueDepth int
+}
+
+func (s *serverSamples) sample(m draft.Metrics) {
+	s.peakGoroutines = max(s.peakGoroutines, m.Goroutines)
+	s.peakHeap = max(s.peakHeap, m.HeapBytes)
+	s.peakQueueDepth = max(s.peakQueueDepth, m.MaxDepth)
+}
+
+// percentile returns the pth percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p / 100)
+	return sorted[i]
+}
+
+// summary is the result of a run, checked against the latency target
+type summary struct {
+	p99        time.Duration
+	missed     int64
+	unfinished int // Rooms whose draft didn't complete
+}
+
+// report writes the results of a run and returns what the run is judged on
+func report(w io.Writer, rooms []*room, rec *recorder, server serverSamples, elapsed time.Duration) summary {
+	rec.mu.Lock()
+	latencies := slices.Clone(rec.latencies)
+	rec.mu.Unlock()
+	slices.Sort(latencies)
+
+	var spreads []time.Duration
+	var s summary
+	for _, r := range rooms {
+		spreads = append(spreads, r.spreads()...)
+		for _, t := range r.teams {
+			t.mu.Lock()
+			done := t.done
+			t.mu.Unlock()
+			if !done {
+				s.unfinished++
+				break
+			}
+		}
+	}
+	slices.Sort(spreads)
+	s.p99 = percentile(latencies, 99)
+	s.missed = rec.missed.Load()
+
+	fmt.Fprintf(w, "\nRooms: %d (%d finished) in %s\n", len(rooms), len(rooms)-s.unfinished, elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Picks sent: %d, made by the timer: %d\n", rec.picksSent.Load(), rec.autoPicks.Load())
+
+	fmt.Fprintf(w, "\nPick latency (make_pick sent to pick_made received, %d deliveries)\n", len(latencies))
+	fmt.Fprintf(w, "  p50 %s  p90 %s  p99 %s  max %s\n",
+		percentile(latencies, 50), percentile(latencies, 90), s.p99, percentile(latencies, 100))
+	fmt.Fprintf(w, "Broadcast fan-out spread (first to last team, %d broadcasts)\n", len(spreads))
+	fmt.Fprintf(w, "  p50 %s  p90 %s  p99 %s  max %s\n",
+		percentile(spreads, 50), percentile(spreads, 90), percentile(spreads, 99), percentile(spreads, 100))
+
+	fmt.Fprintf(w, "\nMessages received: %d\n", rec.received.Load())
+	fmt.Fprintf(w, "  missed (seq gaps): %d, duplicates: %d, errors: %d\n", s.missed, rec.duplicates.Load(), rec.errors.Load())
+	fmt.Fprintf(w, "  server dropped: %d, slow client disconnects: %d, peak queue depth: %d\n",
+		server.after.MessagesDropped-server.before.MessagesDropped,
+		server.after.SlowClientDisconnects-server.before.SlowClientDisconnects,
+		server.peakQueueDepth)
+	fmt.Fprintf(w, "Reconnects: %d (%d sent a snapshot instead of resuming), failed connects: %d\n",
+		rec.reconnects.Load(), rec.snapshots.Load(), rec.connectFailures.Load())
+
+	fmt.Fprintf(w, "\nServer goroutines: %d before, %d peak, %d after (%+d)\n",
+		server.before.Goroutines, server.peakGoroutines, server.after.Goroutines,
+		server.after.Goroutines-server.before.Goroutines)
+	fmt.Fprintf(w, "Server heap: %s before, %s peak, %s after (%+.1f MiB)\n",
+		mib(server.before.HeapBytes), mib(server.peakHeap), mib(server.after.HeapBytes),
+		(float64(server.after.HeapBytes)-float64(server.before.HeapBytes))/(1<<20))
+	return s
+}
+
+func mib(bytes uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
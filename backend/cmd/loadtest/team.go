@@ -0,0 +1,401 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+)
+
+// maxPickAttempts is how many players a team tries before leaving its pick to the timer
+const maxPickAttempts = 3
+
+// room is a draft room the load test has teams in, and when each broadcast reached them
+type room struct {
+	name  string
+	teams []*team
+
+	mu         sync.Mutex
+	sent       map[int]time.Time      // When a team sent make_pick, by pick number
+	broadcasts map[uint64]*deliveries // By seq
+}
+
+// deliveries is when a broadcast first and last reached the room's teams
+type deliveries struct {
+	first, last time.Time
+	count       int
+}
+
+func newRoom(name string) *room {
+	return &room{
+		name:       name,
+		sent:       make(map[int]time.Time),
+		broadcasts: make(map[uint64]*deliveries),
+	}
+}
+
+// picked notes that a team sent the pick with this number
+func (r *room) picked(pickNumber int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[pickNumber] = at
+}
+
+// delivered notes that a team received a broadcast, returning how long after
+// make_pick it arrived if it reports a pick one of the teams sent
+func (r *room) delivered(seq uint64, pickNumber int, at time.Time) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.broadcasts[seq]
+	if d == nil {
+		d = &deliveries{first: at}
+		r.broadcasts[seq] = d
+	}
+	d.last = at
+	d.count++
+
+	sent, ok := r.sent[pickNumber]
+	if pickNumber == 0 || !ok {
+		return 0, false
+	}
+	return at.Sub(sent), true
+}
+
+// spreads returns, for each broadcast every team received, the time between
+// the first and last team receiving it
+func (r *room) spreads() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.teams) < 2 {
+		return nil
+	}
+	var spreads []time.Duration
+	for _, d := range r.broadcasts {
+		if d.count >= len(r.teams) {
+			spreads = append(spreads, d.last.Sub(d.first))
+		}
+	}
+	return spreads
+}
+
+// team is a fake team: one WebSocket connection to its room, reconnecting with
+// lastSeq whenever it drops, and picking after a think time when on the clock
+type team struct {
+	userID int
+	token  string // Member token, sent as a bearer credential
+	url    string // WebSocket URL, without lastSeq
+	room   *room
+	rec    *recorder
+	think  func() time.Duration
+
+	connected chan struct{} // Closed on the first hello
+	finished  chan struct{} // Closed when the draft completes or the team gives up
+
+	mu          sync.Mutex
+	rng         *rand.Rand
+	conn        *websocket.Conn
+	connects    int
+	lastSeq     uint64
+	resyncing   bool // Sent resync_from after a gap; later broadcasts wait for the replay
+	status      draft.DraftStatus
+	currentTurn int
+	pickNumber  int   // Number of the next pick
+	available   []int // Best ranked first
+	pickTimer   *time.Timer
+	attempts    int // Players tried for the current pick
+	done        bool
+}
+
+func newTeam(userID int, token, url string, r *room, rec *recorder, seed int64, think func(*rand.Rand) time.Duration) *team {
+	t := &team{
+		userID:    userID,
+		token:     token,
+		url:       url,
+		room:      r,
+		rec:       rec,
+		connected: make(chan struct{}),
+		finished:  make(chan struct{}),
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+	t.think = func() time.Duration { return think(t.rng) }
+	r.teams = append(r.teams, t)
+	return t
+}
+
+// run connects and reconnects the team until its draft completes or ctx ends
+func (t *team) run(ctx context.Context) {
+	defer close(t.finished)
+	for ctx.Err() == nil {
+		conn, err := t.dial(ctx)
+		if err != nil {
+			t.rec.connectFailures.Add(1)
+			select {
+			case <-ctx.Done():
+			case <-time.After(500 * time.Millisecond):
+			}
+			continue
+		}
+		t.read(ctx, conn)
+
+		t.mu.Lock()
+		done := t.done
+		t.mu.Unlock()
+		if done {
+			return
+		}
+	}
+}
+
+// dial opens a connection, resuming from the last broadcast received
+func (t *team) dial(ctx context.Context) (*websocket.Conn, error) {
+	t.mu.Lock()
+	url := t.url
+	if t.lastSeq > 0 {
+		url += fmt.Sprintf("&lastSeq=%d", t.lastSeq)
+	}
+	t.mu.Unlock()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(dialCtx, url, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": {"Bearer " + t.token}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(1 << 20) // draft_state carries the whole pick history
+
+	t.mu.Lock()
+	t.conn = conn
+	t.connects++
+	if t.connects > 1 {
+		t.rec.reconnects.Add(1)
+	}
+	t.mu.Unlock()
+	return conn, nil
+}
+
+// drop closes the team's connection as a network failure would; run reconnects it
+func (t *team) drop() {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn != nil {
+		conn.CloseNow()
+	}
+}
+
+// read handles messages until the connection closes
+func (t *team) read(ctx context.Context, conn *websocket.Conn) {
+	defer conn.CloseNow()
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		now := time.Now()
+		t.rec.received.Add(1)
+		if t.handle(data, now) {
+			conn.Close(websocket.StatusNormalClosure, "draft completed")
+			return
+		}
+	}
+}
+
+// handle applies one server message. Returns true once the draft is complete.
+func (t *team) handle(data []byte, now time.Time) bool {
+	var msg struct {
+		Type string `json:"type"`
+		Seq  uint64 `json:"seq"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.rec.errors.Add(1)
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Broadcasts are applied in seq order; duplicates from a replay are skipped,
+	// and a gap means messages were dropped, so ask for them again
+	if msg.Seq != 0 {
+		switch {
+		case msg.Seq <= t.lastSeq:
+			t.rec.duplicates.Add(1)
+			return false
+		case msg.Seq > t.lastSeq+1:
+			if !t.resyncing {
+				t.rec.missed.Add(int64(msg.Seq - t.lastSeq - 1))
+				t.resyncing = true
+				t.sendLocked(draft.ResyncFromMessage{Type: draft.MsgTypeResyncFrom, Seq: t.lastSeq})
+			}
+			return false
+		}
+		t.lastSeq = msg.Seq
+		t.resyncing = false
+	}
+
+	switch msg.Type {
+	case draft.MsgTypeHello:
+		var hello draft.HelloMessage
+		json.Unmarshal(data, &hello)
+		if t.lastSeq == 0 {
+			t.lastSeq = hello.LastSeq
+		}
+		select {
+		case <-t.connected:
+		default:
+			close(t.connected)
+		}
+
+	case draft.MsgTypeDraftState:
+		var state draft.DraftStateMessage
+		json.Unmarshal(data, &state)
+		if t.connects > 1 {
+			t.rec.snapshots.Add(1) // Reconnected too far behind to resume
+		}
+		t.lastSeq = state.LastSeq
+		t.resyncing = false
+		t.status = state.Status
+		t.currentTurn = state.CurrentTurn
+		t.pickNumber = len(state.PickHistory) + 1
+		t.available = state.AvailablePlayers
+		t.turnLocked()
+		if t.status == draft.StatusCompleted {
+			t.done = true
+			return true
+		}
+
+	case draft.MsgTypeDraftStarted:
+		var started draft.DraftStartedMessage
+		json.Unmarshal(data, &started)
+		t.status = draft.StatusInProgress
+		t.currentTurn = started.CurrentTurn
+		t.pickNumber = 1
+		t.available = started.AvailablePlayers
+		t.turnLocked()
+
+	case draft.MsgTypeTurnChanged:
+		var changed draft.TurnChangedMessage
+		json.Unmarshal(data, &changed)
+		t.currentTurn = changed.CurrentTurn
+		t.turnLocked()
+
+	case draft.MsgTypeDraftResumed:
+		var resumed draft.DraftResumedMessage
+		json.Unmarshal(data, &resumed)
+		t.status = draft.StatusInProgress
+		t.currentTurn = resumed.CurrentTurn
+		t.turnLocked()
+
+	case draft.MsgTypeDraftPaused:
+		t.status = draft.StatusPaused
+		t.turnLocked()
+
+	case draft.MsgTypePickMade:
+		var pick draft.PickMadeMessage
+		json.Unmarshal(data, &pick)
+		// A sent pick that lost its connection is made by the timer instead; that's not latency
+		sentPick := pick.PickNumber
+		if pick.AutoDraft {
+			sentPick = 0
+		}
+		if latency, ok := t.room.delivered(msg.Seq, sentPick, now); ok {
+			t.rec.latency(latency)
+		}
+		if pick.UserID == t.userID && pick.AutoDraft {
+			t.rec.autoPicks.Add(1)
+		}
+		t.available = slices.DeleteFunc(t.available, func(id int) bool { return id == pick.PlayerID })
+		t.pickNumber = pick.PickNumber + 1
+
+	case draft.MsgTypeDraftCompleted:
+		t.room.delivered(msg.Seq, 0, now)
+		t.status = draft.StatusCompleted
+		t.done = true
+		t.turnLocked()
+		return true
+
+	case draft.MsgTypeError:
+		var e draft.ErrorMessage
+		json.Unmarshal(data, &e)
+		t.rec.errors.Add(1)
+		// A rejected pick is retried with another player, if there's time
+		if t.currentTurn == t.userID && t.pickTimer == nil && t.attempts < maxPickAttempts {
+			pickNumber := t.pickNumber
+			t.pickTimer = time.AfterFunc(0, func() { t.pick(pickNumber) })
+		}
+	}
+
+	if msg.Seq != 0 && msg.Type != draft.MsgTypePickMade {
+		t.room.delivered(msg.Seq, 0, now)
+	}
+	return false
+}
+
+// turnLocked starts the think time when the team comes on the clock, and
+// cancels a pending pick when it goes off it
+func (t *team) turnLocked() {
+	if t.pickTimer != nil {
+		t.pickTimer.Stop()
+		t.pickTimer = nil
+	}
+	if t.status != draft.StatusInProgress || t.currentTurn != t.userID {
+		return
+	}
+	t.attempts = 0
+	pickNumber := t.pickNumber
+	t.pickTimer = time.AfterFunc(t.think(), func() { t.pick(pickNumber) })
+}
+
+// pick sends make_pick for pickNumber if the team is still on the clock for it,
+// choosing at random among the best few available players
+func (t *team) pick(pickNumber int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pickTimer = nil
+	if t.done || t.status != draft.StatusInProgress || t.currentTurn != t.userID || t.pickNumber != pickNumber || len(t.available) == 0 {
+		return
+	}
+	t.attempts++
+	playerID := t.available[t.rng.Intn(min(5, len(t.available)))]
+
+	t.room.picked(pickNumber, time.Now())
+	t.rec.picksSent.Add(1)
+	t.sendLocked(draft.MakePickMessage{
+		Type:       draft.MsgTypeMakePick,
+		UserID:     t.userID,
+		PlayerID:   playerID,
+		PickNumber: pickNumber,
+	})
+}
+
+// sendLocked writes a message on the current connection. A failed write
+// closes the connection, and run reconnects.
+func (t *team) sendLocked(msg any) {
+	if t.conn == nil {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	conn := t.conn
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+			conn.CloseNow()
+		}
+	}()
+}
@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/database"
 	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
 	"github.com/sblackwood23/fantasy-draft-app/internal/handlers"
@@ -33,11 +35,23 @@ func main() {
 	eventRepo := repository.NewEventRepository(db.Pool)
 	playerRepo := repository.NewPlayerRepository(db.Pool)
 	userRepo := repository.NewUserRepository(db.Pool)
+	memberRepo := repository.NewTeamMemberRepository(db.Pool)
 	eventPlayerRepo := repository.NewEventPlayerRepository(db.Pool)
 	draftResultRepo := repository.NewDraftResultRepository(db.Pool)
+	inviteRepo := repository.NewInviteRepository(db.Pool)
+	draftChatRepo := repository.NewDraftChatRepository(db.Pool)
+	draftEventRepo := repository.NewDraftEventRepository(db.Pool)
+
+	// Initialize invite token signer
+	inviteSigner, err := newInviteSigner()
+	if err != nil {
+		log.Fatalf("Failed to create invite signer: %v", err)
+	}
 
 	// Initialize services
-	draftService := draft.NewDraftService(draftResultRepo, eventRepo)
+	draftConfig := draftConfigFromEnv()
+	draftService := draft.NewDraftService(draftResultRepo, draftEventRepo, eventRepo, draftChatRepo, eventRepo, userRepo, draftConfig)
+	mockDrafts := draft.NewMockDrafts(draftConfig, userRepo)
 
 	// Initialize dependencies
 	deps := &Dependencies{
@@ -45,7 +59,14 @@ func main() {
 		Player:      handlers.NewPlayerHandler(playerRepo),
 		User:        handlers.NewUserHandler(userRepo),
 		EventPlayer: handlers.NewEventPlayerHandler(eventPlayerRepo),
-		DraftRoom:   handlers.NewDraftRoomHandler(eventPlayerRepo, eventRepo, userRepo, draftService),
+		DraftRoom:   handlers.NewDraftRoomHandler(eventPlayerRepo, eventRepo, userRepo, memberRepo, inviteRepo, draftEventRepo, inviteSigner, draftService),
+		DraftAction: handlers.NewDraftActionHandler(userRepo, draftResultRepo, draftEventRepo, draftService),
+		DraftResult: handlers.NewDraftResultHandler(draftResultRepo, eventRepo, userRepo),
+		Export:      handlers.NewExportHandler(draftResultRepo, eventRepo, userRepo),
+		Replay:      handlers.NewReplayHandler(draftResultRepo, eventRepo, eventPlayerRepo, draftService),
+		Analytics:   handlers.NewAnalyticsHandler(draftResultRepo, eventRepo, userRepo, eventPlayerRepo),
+		Invite:      handlers.NewInviteHandler(inviteRepo, eventRepo, inviteSigner),
+		MockDraft:   handlers.NewMockDraftHandler(eventRepo, eventPlayerRepo, userRepo, mockDrafts),
 		Draft:       draftService,
 	}
 
@@ -95,6 +116,100 @@ func main() {
 	fmt.Println("Server stopped gracefully")
 }
 
+// newInviteSigner creates the invite token signer from INVITE_SIGNING_KEY
+// Falls back to a random key, which invalidates outstanding invites on restart
+func newInviteSigner() (*auth.Signer, error) {
+	if key := os.Getenv("INVITE_SIGNING_KEY"); key != "" {
+		return auth.NewSigner([]byte(key)), nil
+	}
+
+	key, err := auth.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("INVITE_SIGNING_KEY not set - using a random key, invite links will not survive a restart")
+	return auth.NewSigner([]byte(key)), nil
+}
+
+// draftConfigFromEnv reads draft connection settings, using defaults for unset values:
+// WS_PING_INTERVAL, WS_PING_TIMEOUT, IDLE_AFTER, PICK_SAVE_RETRY_AFTER,
+// PAUSE_AUTO_RESUME_AFTER, AUTO_PILOT_PICK_DELAY, MOCK_DRAFT_TIMER and
+// MOCK_DRAFT_BOT_DELAY (Go durations such as "15s"), SEND_QUEUE_SIZE,
+// PICK_SAVE_ATTEMPTS, AUTO_PILOT_AFTER_TIMEOUTS, MOCK_DRAFT_MAX_ROOMS,
+// MOCK_DRAFT_MAX_PER_TEAM, and BACKPRESSURE_POLICY ("drop_oldest" or "disconnect")
+func draftConfigFromEnv() draft.Config {
+	cfg := draft.DefaultConfig()
+	cfg.Heartbeat.PingInterval = envDuration("WS_PING_INTERVAL", cfg.Heartbeat.PingInterval)
+	cfg.Heartbeat.PingTimeout = envDuration("WS_PING_TIMEOUT", cfg.Heartbeat.PingTimeout)
+	cfg.Heartbeat.IdleAfter = envDuration("IDLE_AFTER", cfg.Heartbeat.IdleAfter)
+
+	if value := os.Getenv("SEND_QUEUE_SIZE"); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			cfg.Queue.Size = size
+		} else {
+			log.Printf("Invalid SEND_QUEUE_SIZE %q, using %d", value, cfg.Queue.Size)
+		}
+	}
+	switch policy := draft.BackpressurePolicy(os.Getenv("BACKPRESSURE_POLICY")); policy {
+	case "":
+	case draft.PolicyDropOldest, draft.PolicyDisconnect:
+		cfg.Queue.Policy = policy
+	default:
+		log.Printf("Invalid BACKPRESSURE_POLICY %q, using %s", policy, cfg.Queue.Policy)
+	}
+
+	if value := os.Getenv("PICK_SAVE_ATTEMPTS"); value != "" {
+		if attempts, err := strconv.Atoi(value); err == nil && attempts > 0 {
+			cfg.Persistence.MaxAttempts = attempts
+		} else {
+			log.Printf("Invalid PICK_SAVE_ATTEMPTS %q, using %d", value, cfg.Persistence.MaxAttempts)
+		}
+	}
+	cfg.Persistence.RetryAfter = envDuration("PICK_SAVE_RETRY_AFTER", cfg.Persistence.RetryAfter)
+	cfg.Pause.AutoResumeAfter = envDuration("PAUSE_AUTO_RESUME_AFTER", cfg.Pause.AutoResumeAfter)
+
+	if value := os.Getenv("AUTO_PILOT_AFTER_TIMEOUTS"); value != "" {
+		if timeouts, err := strconv.Atoi(value); err == nil && timeouts >= 0 {
+			cfg.AutoPilot.AfterTimeouts = timeouts
+		} else {
+			log.Printf("Invalid AUTO_PILOT_AFTER_TIMEOUTS %q, using %d", value, cfg.AutoPilot.AfterTimeouts)
+		}
+	}
+	cfg.AutoPilot.PickDelay = envDuration("AUTO_PILOT_PICK_DELAY", cfg.AutoPilot.PickDelay)
+
+	cfg.Mock.TimerDuration = envDuration("MOCK_DRAFT_TIMER", cfg.Mock.TimerDuration)
+	cfg.Mock.BotPickDelay = envDuration("MOCK_DRAFT_BOT_DELAY", cfg.Mock.BotPickDelay)
+	if value := os.Getenv("MOCK_DRAFT_MAX_ROOMS"); value != "" {
+		if rooms, err := strconv.Atoi(value); err == nil && rooms >= 0 {
+			cfg.Mock.MaxRooms = rooms
+		} else {
+			log.Printf("Invalid MOCK_DRAFT_MAX_ROOMS %q, using %d", value, cfg.Mock.MaxRooms)
+		}
+	}
+	if value := os.Getenv("MOCK_DRAFT_MAX_PER_TEAM"); value != "" {
+		if rooms, err := strconv.Atoi(value); err == nil && rooms >= 0 {
+			cfg.Mock.MaxPerTeam = rooms
+		} else {
+			log.Printf("Invalid MOCK_DRAFT_MAX_PER_TEAM %q, using %d", value, cfg.Mock.MaxPerTeam)
+		}
+	}
+	return cfg
+}
+
+// envDuration parses a duration environment variable, falling back if unset or invalid
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s: %v", name, value, fallback, err)
+		return fallback
+	}
+	return d
+}
+
 // healthCheckHandler returns server and database health status
 func healthCheckHandler(db *database.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
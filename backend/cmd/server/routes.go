@@ -21,6 +21,13 @@ type Dependencies struct {
 	User        *handlers.UserHandler
 	EventPlayer *handlers.EventPlayerHandler
 	DraftRoom   *handlers.DraftRoomHandler
+	DraftAction *handlers.DraftActionHandler
+	DraftResult *handlers.DraftResultHandler
+	Export      *handlers.ExportHandler
+	Replay      *handlers.ReplayHandler
+	Analytics   *handlers.AnalyticsHandler
+	Invite      *handlers.InviteHandler
+	MockDraft   *handlers.MockDraftHandler
 	Draft       *draft.DraftService
 }
 
@@ -35,7 +42,7 @@ func setupRoutes(r *chi.Mux, db *database.DB, deps *Dependencies) {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Admin-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -44,8 +51,9 @@ func setupRoutes(r *chi.Mux, db *database.DB, deps *Dependencies) {
 	// Health check
 	r.Get("/health", healthCheckHandler(db))
 
-	// WebSocket route for draft
-	r.Get("/ws/draft", deps.Draft.HandleWebSocket)
+	// WebSocket route for draft; ?mockID= connects to a mock draft instead
+	r.With(deps.MockDraft.RouteMockDrafts).Get("/ws/draft", deps.Draft.HandleWebSocket)
+	r.Get("/draft/metrics", deps.Draft.HandleMetrics)
 
 	// Events routes
 	r.Get("/events/next", deps.Event.GetNextEvent)
@@ -54,6 +62,7 @@ func setupRoutes(r *chi.Mux, db *database.DB, deps *Dependencies) {
 	r.Post("/events", deps.Event.CreateEvent)
 	r.Put("/events/{id}", deps.Event.UpdateEvent)
 	r.Delete("/events/{id}", deps.Event.DeleteEvent)
+	r.Post("/events/{id}/spectator-link", deps.Event.CreateSpectatorLink)
 
 	// Players routes
 	r.Get("/players/{id}", deps.Player.GetPlayer)
@@ -81,6 +90,33 @@ func setupRoutes(r *chi.Mux, db *database.DB, deps *Dependencies) {
 	r.Post("/events/{id}/draft-room", deps.DraftRoom.CreateDraftRoom)
 	r.Get("/events/{id}/draft-room", deps.DraftRoom.GetDraftRoom)
 	r.Post("/events/join", deps.DraftRoom.JoinEvent)
+	r.Post("/events/{id}/teams/{userID}/rejoin-token", deps.DraftRoom.IssueRejoinToken)
+
+	// Mock draft routes: practice drafts against bots, never saved
+	r.Post("/events/{id}/mock-drafts", deps.MockDraft.CreateMockDraft)
+	r.Delete("/mock-drafts/{mockID}", deps.MockDraft.DeleteMockDraft)
+
+	// Draft routes for scripts and clients without WebSocket (SSE stream + REST actions)
+	r.Get("/events/{id}/draft/stream", deps.DraftAction.Stream)
+	r.Post("/events/{id}/draft/stream-ticket", deps.DraftAction.StreamTicket)
+	r.Post("/events/{id}/draft/start", deps.DraftAction.StartDraft)
+	r.Post("/events/{id}/draft/pick", deps.DraftAction.MakePick)
+	r.Post("/events/{id}/draft/pause", deps.DraftAction.PauseDraft)
+	r.Post("/events/{id}/draft/resume", deps.DraftAction.ResumeDraft)
+	r.Get("/events/{id}/draft/reconcile", deps.DraftAction.Reconcile)
+	r.Get("/events/{id}/draft/log", deps.DraftAction.Log)
+
+	// Draft results routes
+	r.Get("/events/{id}/results", deps.DraftResult.GetResults)
+	r.Get("/events/{id}/teams/{userID}/roster", deps.DraftResult.GetRoster)
+	r.Get("/events/{id}/board", deps.DraftResult.GetBoard)
+	r.Get("/events/{id}/export", deps.Export.ExportDraft)
+	r.Get("/events/{id}/replay", deps.Replay.Replay)
+	r.Get("/events/{id}/analytics", deps.Analytics.GetAnalytics)
+
+	// Invite routes
+	r.Post("/events/{id}/invites", deps.Invite.CreateInvite)
+	r.Get("/invites/{token}", deps.Invite.GetInvite)
 
 	// Serve static frontend files in production
 	if staticDir := os.Getenv("STATIC_DIR"); staticDir != "" {
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// tokenBytes is the number of random bytes in a generated token
+const tokenBytes = 24
+
+// HashSecret returns the hex-encoded SHA-256 hash of a passkey or token.
+// Hashes are deterministic so they can be looked up directly in the database.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySecret reports whether secret hashes to the stored hash, using a constant-time comparison
+func VerifySecret(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
+
+// GenerateToken returns a new random, URL-safe token
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsAdminKey reports whether key matches the ADMIN_KEY environment variable,
+// which authenticates the commissioner. Always false if ADMIN_KEY is unset.
+func IsAdminKey(key string) bool {
+	adminKey := os.Getenv("ADMIN_KEY")
+	return adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1
+}
+
+// Signer issues and verifies HMAC-signed tokens, so forged tokens can be
+// rejected before touching the database
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a Signer using the given secret key
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// NewToken returns a random token of the form "<id>.<signature>"
+func (s *Signer) NewToken() (string, error) {
+	id, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	return id + "." + s.sign(id), nil
+}
+
+// Verify reports whether the token carries a valid signature
+func (s *Signer) Verify(token string) bool {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(s.sign(id)))
+}
+
+// sign returns the URL-safe HMAC-SHA256 signature of value
+func (s *Signer) sign(value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
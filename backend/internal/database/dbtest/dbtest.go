@@ -0,0 +1,99 @@
+// Package dbtest gives tests their own PostgreSQL schema with every migration
+// applied. Tests that use it are skipped unless TEST_DATABASE_URL is set.
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// New connects to TEST_DATABASE_URL, creates a fresh schema, applies the
+// migrations to it and returns a pool whose connections use it. The schema is
+// dropped when the test ends.
+func New(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connecting to TEST_DATABASE_URL: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), databaseURL)
+		if err != nil {
+			t.Logf("dropping schema %s: %v", schema, err)
+			return
+		}
+		defer conn.Close(context.Background())
+		conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("parsing TEST_DATABASE_URL: %v", err)
+	}
+	// Extensions such as pgcrypto live in public
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("creating pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrate(t, ctx, pool)
+	return pool
+}
+
+// migrate applies every up migration in order. Packages run their tests in
+// parallel, so migrations are serialized on an advisory lock: creating an
+// extension isn't safe to race.
+func migrate(t testing.TB, ctx context.Context, pool *pgxpool.Pool) {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations", "*.up.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("finding migrations: %v", err)
+	}
+	slices.Sort(files)
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquiring connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock(hashtext('dbtest.migrate'))"); err != nil {
+		t.Fatalf("locking migrations: %v", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext('dbtest.migrate'))")
+
+	for _, name := range files {
+		sql, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("reading %s: %v", filepath.Base(name), err)
+		}
+		if _, err := conn.Exec(ctx, string(sql)); err != nil {
+			t.Fatalf("applying %s: %v", filepath.Base(name), err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package draft
+
+import (
+	"log"
+	"time"
+)
+
+// ActionResult describes the draft right after an action taken over REST
+type ActionResult struct {
+	EventID          int         `json:"eventID"`
+	Status           DraftStatus `json:"status"`
+	CurrentTurn      int         `json:"currentTurn"`
+	RoundNumber      int         `json:"roundNumber"`
+	CurrentPickIndex int         `json:"currentPickIndex"`
+	TurnDeadline     int64       `json:"turnDeadline"`
+	RemainingTime    float64     `json:"remainingTime"`
+	LastSeq          uint64      `json:"lastSeq"` // Broadcasts up to here reflect the action
+	Pick             *PickResult `json:"pick,omitempty"`
+}
+
+// StartDraft starts the given event's draft; the REST counterpart of start_draft
+func (s *DraftService) StartDraft(eventID int, pickOrder []int, totalRounds int, timerDuration time.Duration, breaks []ScheduledBreak) (*ActionResult, error) {
+	state, err := s.roomForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.startDraft(state, pickOrder, totalRounds, timerDuration, breaks); err != nil {
+		return nil, err
+	}
+	return actionResult(state, nil), nil
+}
+
+// MakePick makes a pick for userID in the given event's draft room.
+// It's the REST counterpart of make_pick, for clients without a WebSocket.
+func (s *DraftService) MakePick(eventID, userID, playerID, pickNumber int) (*ActionResult, error) {
+	state, err := s.roomForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.manager.TouchTeam(userID, s.clock.Now())
+	state.ClearAutoPilot(userID)
+	pick, err := state.MakePick(userID, playerID, pickNumber)
+	if err != nil {
+		return nil, err
+	}
+	return actionResult(state, &pick), nil
+}
+
+// PauseDraft pauses the given event's draft; the REST counterpart of pause_draft
+func (s *DraftService) PauseDraft(eventID int, reason string, duration time.Duration) (*ActionResult, error) {
+	state, err := s.roomForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := state.PauseDraft(reason, duration); err != nil {
+		return nil, err
+	}
+	log.Printf("Draft paused for event %d", eventID)
+	return actionResult(state, nil), nil
+}
+
+// ResumeDraft resumes the given event's draft; the REST counterpart of resume_draft
+func (s *DraftService) ResumeDraft(eventID int) (*ActionResult, error) {
+	state, err := s.roomForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := state.ResumeDraft(); err != nil {
+		return nil, err
+	}
+	log.Printf("Draft resumed for event %d", eventID)
+	return actionResult(state, nil), nil
+}
+
+// roomForEvent returns the active draft room if it belongs to eventID
+func (s *DraftService) roomForEvent(eventID int) (*DraftState, error) {
+	state := s.GetRoom()
+	if state == nil || state.GetEventID() != eventID {
+		return nil, newError(ErrCodeDraftNotActive, "no draft room for event %d", eventID)
+	}
+	return state, nil
+}
+
+// actionResult summarizes the room's current state after an action
+func actionResult(state *DraftState, pick *PickResult) *ActionResult {
+	snapshot := state.GetSnapshot()
+	return &ActionResult{
+		EventID:          snapshot.EventID,
+		Status:           snapshot.Status,
+		CurrentTurn:      snapshot.CurrentTurn,
+		RoundNumber:      snapshot.RoundNumber,
+		CurrentPickIndex: snapshot.CurrentPickIndex,
+		TurnDeadline:     snapshot.TurnDeadline,
+		RemainingTime:    snapshot.RemainingTime,
+		LastSeq:          snapshot.LastSeq,
+		Pick:             pick,
+	}
+}
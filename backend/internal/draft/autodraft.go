@@ -0,0 +1,252 @@
+package draft
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// Auto-draft strategies, recorded on each pick made for a team
+const (
+	StrategyRandom        = "random"         // Any available player
+	StrategyQueue         = "queue"          // The team's preference queue, in order
+	StrategyBestAvailable = "best_available" // Best world ranking
+	StrategyRosterNeed    = "roster_need"    // Fills unmet stipulations first, then best available
+)
+
+// Stipulation keys the draft understands. Other keys are left alone.
+const (
+	StipulationAutoDraftStrategy = "autoDraftStrategy" // The event's default strategy for timeouts
+	StipulationMinAmateurs       = "minAmateurs"       // Amateurs each team must draft
+	StipulationMinInternational  = "minInternational"  // Players from outside the USA each team must draft
+)
+
+// AutoDraftInput is what an AutoDrafter knows about the pick it's making
+type AutoDraftInput struct {
+	Available    []int                 // Player IDs still available
+	Ranked       []int                 // The event's players, best ranked first
+	Players      map[int]models.Player // The event's players by ID
+	Queue        []int                 // The team's preference queue
+	Roster       []int                 // Players the team has drafted
+	Requirements []RosterRequirement   // The event's roster stipulations
+	Rand         *rand.Rand            // The room's random source
+}
+
+// AutoDrafter chooses a player for a team whose pick is made for it.
+// ok is false if the strategy has no player to suggest; the draft then falls
+// back to best available, then random.
+type AutoDrafter interface {
+	Choose(in AutoDraftInput) (playerID int, ok bool)
+}
+
+// autoDrafters are the strategies by name
+var autoDrafters = map[string]AutoDrafter{
+	StrategyRandom:        randomDrafter{},
+	StrategyQueue:         queueDrafter{},
+	StrategyBestAvailable: bestAvailableDrafter{},
+	StrategyRosterNeed:    rosterNeedDrafter{},
+}
+
+// AutoDrafterFor returns the strategy with the given name
+func AutoDrafterFor(name string) (AutoDrafter, bool) {
+	drafter, ok := autoDrafters[name]
+	return drafter, ok
+}
+
+// validateStrategy checks a strategy name; empty means the default
+func validateStrategy(name string) error {
+	if _, ok := autoDrafters[name]; name != "" && !ok {
+		return newError(ErrCodeRuleViolation, "unknown auto-draft strategy %q", name)
+	}
+	return nil
+}
+
+// randomDrafter picks any available player
+type randomDrafter struct{}
+
+func (randomDrafter) Choose(in AutoDraftInput) (int, bool) {
+	if len(in.Available) == 0 {
+		return 0, false
+	}
+	return in.Available[in.Rand.Intn(len(in.Available))], true
+}
+
+// queueDrafter picks the first player in the team's queue who's still available
+type queueDrafter struct{}
+
+func (queueDrafter) Choose(in AutoDraftInput) (int, bool) {
+	return firstAvailable(in.Queue, in.Available, nil)
+}
+
+// bestAvailableDrafter picks the best ranked available player
+type bestAvailableDrafter struct{}
+
+func (bestAvailableDrafter) Choose(in AutoDraftInput) (int, bool) {
+	return firstAvailable(in.Ranked, in.Available, nil)
+}
+
+// rosterNeedDrafter picks the best ranked available player who fills a
+// stipulation the team hasn't met, in the order the stipulations are listed,
+// and the best available once they're all met
+type rosterNeedDrafter struct{}
+
+func (rosterNeedDrafter) Choose(in AutoDraftInput) (int, bool) {
+	for _, req := range in.Requirements {
+		if req.filled(in.Roster, in.Players) {
+			continue
+		}
+		if playerID, ok := firstAvailable(in.Ranked, in.Available, func(id int) bool {
+			return req.Matches(in.Players[id])
+		}); ok {
+			return playerID, true
+		}
+	}
+	return bestAvailableDrafter{}.Choose(in)
+}
+
+// firstAvailable returns the first of candidates that's available and, if
+// match is set, matches it
+func firstAvailable(candidates, available []int, match func(int) bool) (int, bool) {
+	for _, playerID := range candidates {
+		if slices.Contains(available, playerID) && (match == nil || match(playerID)) {
+			return playerID, true
+		}
+	}
+	return 0, false
+}
+
+// RosterRequirement is a stipulation that each team draft at least Count
+// players matching it
+type RosterRequirement struct {
+	Stipulation string // The stipulation key, e.g. StipulationMinAmateurs
+	Count       int
+	Matches     func(models.Player) bool
+}
+
+// filled reports whether a roster meets the requirement
+func (r RosterRequirement) filled(roster []int, players map[int]models.Player) bool {
+	have := 0
+	for _, playerID := range roster {
+		if r.Matches(players[playerID]) {
+			have++
+		}
+	}
+	return have >= r.Count
+}
+
+// rosterRequirementMatchers are the stipulations that require players of a kind
+var rosterRequirementMatchers = []struct {
+	stipulation string
+	matches     func(models.Player) bool
+}{
+	{StipulationMinAmateurs, func(p models.Player) bool { return p.Status == "amateur" }},
+	{StipulationMinInternational, func(p models.Player) bool { return p.CountryCode != "" && p.CountryCode != "USA" }},
+}
+
+// parseStipulations reads the event's auto-draft strategy and roster
+// requirements from its stipulations
+func parseStipulations(stipulations models.Stipulations) (string, []RosterRequirement, error) {
+	var strategy string
+	if value, ok := stipulations[StipulationAutoDraftStrategy]; ok {
+		name, isString := value.(string)
+		if !isString {
+			return "", nil, newError(ErrCodeRuleViolation, "stipulation %s must be a strategy name", StipulationAutoDraftStrategy)
+		}
+		if err := validateStrategy(name); err != nil {
+			return "", nil, err
+		}
+		strategy = name
+	}
+
+	var requirements []RosterRequirement
+	for _, m := range rosterRequirementMatchers {
+		value, ok := stipulations[m.stipulation]
+		if !ok {
+			continue
+		}
+		// JSONB numbers decode as float64
+		count, isNumber := value.(float64)
+		if !isNumber || count < 0 || count != float64(int(count)) {
+			return "", nil, newError(ErrCodeRuleViolation, "stipulation %s must be a whole number", m.stipulation)
+		}
+		if count > 0 {
+			requirements = append(requirements, RosterRequirement{
+				Stipulation: m.stipulation,
+				Count:       int(count),
+				Matches:     m.matches,
+			})
+		}
+	}
+	return strategy, requirements, nil
+}
+
+// autoDraftStrategy returns the strategy for a pick made for a team: its own
+// choice, else its queue if it's on auto-pilot, else the event's, else random.
+// Must be called on the room's loop
+func (d *DraftState) autoDraftStrategy(userID int, autoPilot bool) string {
+	switch {
+	case d.strategies[userID] != "":
+		return d.strategies[userID]
+	case autoPilot:
+		return StrategyQueue
+	case d.eventStrategy != "":
+		return d.eventStrategy
+	default:
+		return StrategyRandom
+	}
+}
+
+// chooseAutoDraft picks for a team whose pick is made for it, returning the player
+// and the strategy that chose them. A strategy with nothing to suggest falls back
+// to best available, then random.
+// Must be called on the room's loop
+func (d *DraftState) chooseAutoDraft(userID int, autoPilot bool) (int, string) {
+	in := AutoDraftInput{
+		Available:    d.availablePlayers,
+		Ranked:       d.rankedPlayers,
+		Players:      d.players,
+		Queue:        d.queues[userID],
+		Requirements: d.requirements,
+		Rand:         d.rng,
+	}
+	for _, pick := range d.pickHistory {
+		if pick.UserID == userID {
+			in.Roster = append(in.Roster, pick.PlayerID)
+		}
+	}
+
+	for _, strategy := range []string{d.autoDraftStrategy(userID, autoPilot), StrategyBestAvailable, StrategyRandom} {
+		if playerID, ok := autoDrafters[strategy].Choose(in); ok {
+			return playerID, strategy
+		}
+	}
+	return 0, ""
+}
+
+// SetStrategy sets the strategy used when a team's picks are made for it;
+// empty goes back to the default
+func (d *DraftState) SetStrategy(userID int, strategy string) error {
+	return d.exec(func() error {
+		if d.draftStatus == StatusCompleted {
+			return newError(ErrCodeDraftNotActive, "draft is completed")
+		}
+		if err := validateStrategy(strategy); err != nil {
+			return err
+		}
+		if d.strategies[userID] == strategy {
+			return nil
+		}
+
+		return d.record(DraftEventStrategySet, strategySetPayload{
+			UserID:   userID,
+			Strategy: strategy,
+		})
+	})
+}
+
+// GetStrategy returns the strategy a team chose, or empty for the default
+func (d *DraftState) GetStrategy(userID int) (strategy string) {
+	d.do(func() { strategy = d.strategies[userID] })
+	return strategy
+}
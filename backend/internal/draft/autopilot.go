@@ -0,0 +1,151 @@
+package draft
+
+import (
+	"log"
+	"slices"
+	"time"
+)
+
+// Why a team's auto-pilot was turned on or off, as recorded in the draft log
+const (
+	AutoPilotRequested = "requested" // The team asked for it
+	AutoPilotTimeouts  = "timeouts"  // The team's timer ran out too many turns in a row
+	AutoPilotActivity  = "activity"  // The team sent something, so someone is there to pick
+	AutoPilotBot       = "bot"       // A bot team in a mock draft
+)
+
+// maxQueueLength limits a team's preference queue
+const maxQueueLength = 500
+
+// AutoPilotConfig controls when teams go on auto-pilot and how quickly it picks
+type AutoPilotConfig struct {
+	// AfterTimeouts puts a team on auto-pilot once its timer has run out this
+	// many turns in a row; 0 leaves auto-pilot to the teams
+	AfterTimeouts int
+	// PickDelay is how long a team on auto-pilot stays on the clock before
+	// its pick is made
+	PickDelay time.Duration
+}
+
+// DefaultAutoPilotConfig returns the auto-pilot settings used when none are configured
+func DefaultAutoPilotConfig() AutoPilotConfig {
+	return AutoPilotConfig{
+		AfterTimeouts: 2,
+		PickDelay:     2 * time.Second,
+	}
+}
+
+// pickDue returns when the pick on the clock is made for the team if it doesn't
+// pick itself: at its deadline, or after PickDelay if it's on auto-pilot.
+// Must be called on the room's loop
+func (d *DraftState) pickDue() time.Time {
+	if !d.autoPilot[d.currentTurnID] {
+		return d.turnDeadline
+	}
+	due := d.turnStartedAt.Add(d.autoPilotConfig.PickDelay)
+	if due.After(d.turnDeadline) {
+		return d.turnDeadline
+	}
+	return due
+}
+
+// setAutoPilot records a change to a team's auto-pilot and announces it in
+// presence. The caller restarts the timer if the team is on the clock.
+// Must be called on the room's loop
+func (d *DraftState) setAutoPilot(userID int, enabled bool, reason string) error {
+	if err := d.record(DraftEventAutoPilotChanged, autoPilotChangedPayload{
+		UserID:  userID,
+		Enabled: enabled,
+		Reason:  reason,
+	}); err != nil {
+		return err
+	}
+	d.publisher.PublishAutoPilot(userID, enabled)
+	log.Printf("Auto-pilot %s for user %d in event %d (%s)", onOff(enabled), userID, d.eventID, reason)
+	return nil
+}
+
+// retime restarts the pick timer after a change to when the pick on the clock is due
+// Must be called on the room's loop
+func (d *DraftState) retime(userID int) {
+	if d.draftStatus == StatusInProgress && d.currentTurnID == userID {
+		d.startTimer()
+	}
+}
+
+// SetAutoPilot turns a team's auto-pilot on or off at its request
+func (d *DraftState) SetAutoPilot(userID int, enabled bool) error {
+	return d.exec(func() error {
+		if d.draftStatus == StatusCompleted {
+			return newError(ErrCodeDraftNotActive, "draft is completed")
+		}
+		if d.draftStatus != StatusNotStarted && !slices.Contains(d.pickOrder, userID) {
+			return newError(ErrCodeRuleViolation, "user %d is not in the draft", userID)
+		}
+		if d.autoPilot[userID] == enabled {
+			return nil
+		}
+
+		if err := d.setAutoPilot(userID, enabled, AutoPilotRequested); err != nil {
+			return err
+		}
+		d.retime(userID)
+		return nil
+	})
+}
+
+// ClearAutoPilot turns a team's auto-pilot off because it did something, giving
+// it until its deadline if it's picking. Does nothing if it's off.
+func (d *DraftState) ClearAutoPilot(userID int) {
+	d.do(func() {
+		if !d.autoPilot[userID] || d.draftStatus == StatusCompleted {
+			return
+		}
+		if err := d.setAutoPilot(userID, false, AutoPilotActivity); err != nil {
+			return
+		}
+		d.retime(userID)
+	})
+}
+
+// SetQueue replaces a team's preference queue, the players auto-pilot picks
+// first, in order. Players already drafted are skipped when picking.
+func (d *DraftState) SetQueue(userID int, playerIDs []int) error {
+	return d.exec(func() error {
+		if d.draftStatus == StatusCompleted {
+			return newError(ErrCodeDraftNotActive, "draft is completed")
+		}
+		if len(playerIDs) > maxQueueLength {
+			return newError(ErrCodeRuleViolation, "queue cannot exceed %d players", maxQueueLength)
+		}
+		seen := make(map[int]bool, len(playerIDs))
+		for _, playerID := range playerIDs {
+			if seen[playerID] {
+				return newError(ErrCodeRuleViolation, "player %d is queued more than once", playerID)
+			}
+			if _, ok := d.players[playerID]; !ok {
+				return newError(ErrCodeRuleViolation, "player %d is not in this event", playerID)
+			}
+			seen[playerID] = true
+		}
+
+		return d.record(DraftEventQueueSet, queueSetPayload{
+			UserID:    userID,
+			PlayerIDs: playerIDs,
+		})
+	})
+}
+
+// GetQueue returns a team's preference queue
+func (d *DraftState) GetQueue(userID int) (queue []int) {
+	d.do(func() { queue = slices.Clone(d.queues[userID]) })
+	return queue
+}
+
+// onOff describes a flag for logging
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,56 @@
+package draft
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock is a draft room's source of time. DraftState reads the time and runs
+// its timers through its Clock, so simulations can control time.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a call scheduled by a Clock
+type Timer interface {
+	Stop() bool
+}
+
+// SystemClock is the wall clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// every calls f with the time every interval on clock until closed is closed.
+// Each call is scheduled when the last returns, so calls never overlap; on a
+// fake clock they run while it's advanced.
+func every(clock Clock, interval time.Duration, closed <-chan struct{}, f func(now time.Time)) {
+	var tick func()
+	tick = func() {
+		select {
+		case <-closed:
+			return
+		default:
+		}
+		f(clock.Now())
+		clock.AfterFunc(interval, tick)
+	}
+	clock.AfterFunc(interval, tick)
+}
+
+// newRand returns a room's random source: seeded with seed, or from the clock if seed is 0
+func newRand(seed int64, clock Clock) *rand.Rand {
+	if seed == 0 {
+		seed = clock.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
@@ -0,0 +1,130 @@
+package draft
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when told to. Timers run on the goroutine
+// that advances the clock, in the order they come due, so a draft run on one
+// does the same thing every time.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer // Pending, in no particular order
+	created int
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	order int // Breaks ties between timers due at the same time
+	f     func()
+	done  bool // Stopped or fired; guarded by clock.mu
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.created++
+	t := &fakeTimer{clock: c, at: c.now.Add(d), order: c.created, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.done {
+		return false
+	}
+	t.done = true
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(other *fakeTimer) bool { return other == t })
+	return true
+}
+
+// Advance moves the clock forward by d, running every timer that comes due on
+// the way at its own time
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.advanceTo(target)
+}
+
+// AdvanceToNext moves the clock to the next pending timer and runs it.
+// Returns false if no timer is pending.
+func (c *fakeClock) AdvanceToNext() bool {
+	c.mu.Lock()
+	next := c.nextLocked()
+	c.mu.Unlock()
+	if next == nil {
+		return false
+	}
+	c.advanceTo(next.at)
+	return true
+}
+
+// FireLater moves the clock to the next pending timer and fires it, but returns
+// its callback instead of running it, as if it were slow to get to the room.
+// Returns nil if no timer is pending.
+func (c *fakeClock) FireLater() func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := c.nextLocked()
+	if next == nil {
+		return nil
+	}
+	next.done = true
+	c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool { return t == next })
+	if next.at.After(c.now) {
+		c.now = next.at
+	}
+	return next.f
+}
+
+// advanceTo runs timers due by target in order, then sets the time to target
+func (c *fakeClock) advanceTo(target time.Time) {
+	for {
+		c.mu.Lock()
+		next := c.nextLocked()
+		if next == nil || next.at.After(target) {
+			if target.After(c.now) {
+				c.now = target
+			}
+			c.mu.Unlock()
+			return
+		}
+		next.done = true
+		c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool { return t == next })
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		c.mu.Unlock()
+
+		// Outside the lock: the callback waits for the room's loop, which sets new timers
+		next.f()
+	}
+}
+
+// nextLocked returns the pending timer due first, or nil
+func (c *fakeClock) nextLocked() *fakeTimer {
+	var next *fakeTimer
+	for _, t := range c.timers {
+		if next == nil || t.at.Before(next.at) || (t.at.Equal(next.at) && t.order < next.order) {
+			next = t
+		}
+	}
+	return next
+}
@@ -0,0 +1,407 @@
+package draft
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+func TestRandomDrafts(t *testing.T) {
+	for seed := int64(1); seed <= 8; seed++ {
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			runRandomDraft(t, seed)
+		})
+	}
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	first := runRandomDraft(t, 42)
+	second := runRandomDraft(t, 42)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("the same seed drafted differently")
+	}
+}
+
+// startSimulation starts a draft of 3 teams with a 60 second timer
+func startSimulation(t *testing.T, rounds int, breaks ...ScheduledBreak) (*simulation, *simClient) {
+	s := newSimulation(t, 1, 20, nil)
+	commissioner := s.connect(0, 0)
+	for userID := 1; userID <= 3; userID++ {
+		s.connect(userID, 0)
+	}
+	s.mustSend(commissioner, StartDraftMessage{
+		Type:            MsgTypeStartDraft,
+		PickOrder:       []int{1, 2, 3},
+		TotalRounds:     rounds,
+		TimerDuration:   60,
+		ScheduledBreaks: breaks,
+	})
+	return s, commissioner
+}
+
+// pickBest makes the pick on the clock with the best ranked available player
+func (s *simulation) pickBest() {
+	s.t.Helper()
+	snapshot := s.snapshot()
+	s.mustSend(s.clients[snapshot.CurrentTurn], MakePickMessage{
+		Type:     MsgTypeMakePick,
+		UserID:   snapshot.CurrentTurn,
+		PlayerID: snapshot.AvailablePlayers[0],
+	})
+}
+
+func TestScriptedSnakeDraft(t *testing.T) {
+	s, commissioner := startSimulation(t, 4)
+	for range 12 {
+		s.pickBest()
+	}
+	s.checkCompleted([]int{1, 2, 3}, 4, commissioner)
+
+	history := s.snapshot().PickHistory
+	if history[0].PlayerID != 101 || history[11].PlayerID != 112 {
+		t.Fatalf("best available picks went %d ... %d", history[0].PlayerID, history[11].PlayerID)
+	}
+	s.expectError(s.send(s.clients[1], MakePickMessage{Type: MsgTypeMakePick, UserID: 1, PlayerID: 113}), ErrCodeDraftNotActive)
+}
+
+func TestTimerExpiryAutoDrafts(t *testing.T) {
+	s, _ := startSimulation(t, 1)
+	start := s.clock.Now()
+
+	s.clock.Advance(59 * time.Second)
+	if picks := len(s.snapshot().PickHistory); picks != 0 {
+		t.Fatalf("%d picks before the timer ran out", picks)
+	}
+	s.clock.Advance(time.Second)
+
+	snapshot := s.snapshot()
+	if len(snapshot.PickHistory) != 1 {
+		t.Fatalf("%d picks after the timer ran out, want 1", len(snapshot.PickHistory))
+	}
+	pick := snapshot.PickHistory[0]
+	if pick.UserID != 1 || !pick.AutoDraft || pick.Strategy != StrategyRandom {
+		t.Fatalf("timer expiry made %+v", pick)
+	}
+	if !pick.PickedAt.Equal(start.Add(60 * time.Second)) {
+		t.Fatalf("picked at %s, want at the deadline", pick.PickedAt.Sub(start))
+	}
+	if snapshot.CurrentTurn != 2 || snapshot.TurnDeadline != start.Add(120*time.Second).Unix() {
+		t.Fatalf("user %d on the clock until %d", snapshot.CurrentTurn, snapshot.TurnDeadline)
+	}
+}
+
+func TestPickBeforeExpiryReachesTheRoom(t *testing.T) {
+	s, _ := startSimulation(t, 1)
+
+	// The timer runs out as team 1 picks, and the pick gets to the room first
+	expired := s.clock.FireLater()
+	s.pickBest()
+	expired()
+
+	snapshot := s.snapshot()
+	if len(snapshot.PickHistory) != 1 || snapshot.PickHistory[0].AutoDraft || snapshot.CurrentTurn != 2 {
+		t.Fatalf("picks = %+v with user %d on the clock, want team 1's own pick only", snapshot.PickHistory, snapshot.CurrentTurn)
+	}
+
+	// Team 2's timer still runs its full 60 seconds
+	s.clock.Advance(59 * time.Second)
+	if picks := len(s.snapshot().PickHistory); picks != 1 {
+		t.Fatalf("%d picks before team 2's timer ran out", picks)
+	}
+}
+
+func TestClosedRoomsLeaveNoGoroutines(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	before := runtime.NumGoroutine()
+
+	// Each new room replaces the last, which stops it
+	first := s.room()
+	for range 10 {
+		if err := s.service.CreateRoom(simEventID, simMaxRounds, s.pool, nil); err != nil {
+			t.Fatalf("CreateRoom: %v", err)
+		}
+	}
+	s.waitFor("the replaced rooms' goroutines to exit", func() bool { return runtime.NumGoroutine() <= before })
+
+	if err := first.SetStrategy(1, StrategyRandom); ErrorCodeOf(err) != ErrCodeDraftNotActive {
+		t.Fatalf("SetStrategy on a closed room: %v, want %s", err, ErrCodeDraftNotActive)
+	}
+}
+
+func TestTimeoutsPutTeamOnAutoPilot(t *testing.T) {
+	s, _ := startSimulation(t, 3)
+	config := DefaultAutoPilotConfig()
+
+	// Team 1 times out in rounds 1 and 2; the second timeout puts it on auto-pilot
+	s.clock.Advance(60 * time.Second) // 1 times out
+	s.pickBest()                      // 2
+	s.pickBest()                      // 3
+	s.pickBest()                      // 3
+	s.pickBest()                      // 2
+	if autoPilot := s.snapshot().AutoPilotUserIDs; len(autoPilot) != 0 {
+		t.Fatalf("on auto-pilot after one timeout: %v", autoPilot)
+	}
+	s.clock.Advance(60 * time.Second) // 1 times out again
+	if autoPilot := s.snapshot().AutoPilotUserIDs; !reflect.DeepEqual(autoPilot, []int{1}) {
+		t.Fatalf("auto-pilot = %v after two timeouts, want [1]", autoPilot)
+	}
+
+	// Round 3 opens with team 1, and auto-pilot picks after PickDelay
+	onClock := s.clock.Now()
+	s.clock.Advance(config.PickDelay)
+	snapshot := s.snapshot()
+	if len(snapshot.PickHistory) != 7 {
+		t.Fatalf("%d picks, want auto-pilot's pick 7 after %s", len(snapshot.PickHistory), config.PickDelay)
+	}
+	pick := snapshot.PickHistory[6]
+	if pick.UserID != 1 || !pick.AutoDraft || !pick.PickedAt.Equal(onClock.Add(config.PickDelay)) {
+		t.Fatalf("pick 7 = %+v, want auto-pilot's for user 1", pick)
+	}
+	s.pickBest() // 2
+	s.pickBest() // 3
+	s.checkCompleted([]int{1, 2, 3}, 3)
+}
+
+func TestAnyMessageTakesTeamOffAutoPilot(t *testing.T) {
+	s, _ := startSimulation(t, 2)
+	s.mustSend(s.clients[2], SetAutoPilotMessage{Type: MsgTypeSetAutoPilot, Enabled: true})
+	s.mustSend(s.clients[2], SetQueueMessage{Type: MsgTypeSetQueue, PlayerIDs: []int{105}})
+	if autoPilot := s.snapshot().AutoPilotUserIDs; len(autoPilot) != 0 {
+		t.Fatalf("auto-pilot = %v after the team sent a message", autoPilot)
+	}
+
+	s.mustSend(s.clients[2], SetAutoPilotMessage{Type: MsgTypeSetAutoPilot, Enabled: true})
+	s.pickBest() // 1
+	s.clock.Advance(DefaultAutoPilotConfig().PickDelay)
+	if pick := s.snapshot().PickHistory[1]; pick.UserID != 2 || pick.PlayerID != 105 {
+		t.Fatalf("auto-pilot picked %+v, want the queued player 105", pick)
+	}
+}
+
+func TestPauseKeepsRemainingTime(t *testing.T) {
+	s, commissioner := startSimulation(t, 1)
+
+	s.clock.Advance(20 * time.Second)
+	s.mustSend(commissioner, PauseDraftMessage{Type: MsgTypePauseDraft})
+	if remaining := s.snapshot().RemainingTime; remaining != 40 {
+		t.Fatalf("paused with %vs left, want 40", remaining)
+	}
+
+	// Nothing happens while paused, however long it lasts
+	s.clock.Advance(time.Hour)
+	if snapshot := s.snapshot(); snapshot.Status != StatusPaused || len(snapshot.PickHistory) != 0 {
+		t.Fatalf("%s with %d picks after an hour paused", snapshot.Status, len(snapshot.PickHistory))
+	}
+
+	s.mustSend(commissioner, map[string]string{"type": MsgTypeResumeDraft})
+	s.clock.Advance(39 * time.Second)
+	if picks := len(s.snapshot().PickHistory); picks != 0 {
+		t.Fatalf("%d picks before the remaining time ran out", picks)
+	}
+	s.clock.Advance(time.Second)
+	if picks := len(s.snapshot().PickHistory); picks != 1 {
+		t.Fatalf("%d picks once the remaining time ran out, want 1", picks)
+	}
+}
+
+func TestScheduledBreakResumesOnItsOwn(t *testing.T) {
+	s, _ := startSimulation(t, 2, ScheduledBreak{AfterRound: 1, Duration: 300})
+	for range 3 {
+		s.pickBest()
+	}
+
+	snapshot := s.snapshot()
+	if snapshot.Status != StatusPaused || snapshot.PauseReason != "Break after round 1" {
+		t.Fatalf("%s (%q) after round 1, want the break", snapshot.Status, snapshot.PauseReason)
+	}
+	s.clock.Advance(299 * time.Second)
+	if status := s.snapshot().Status; status != StatusPaused {
+		t.Fatalf("%s before the break ended", status)
+	}
+	s.clock.Advance(time.Second)
+	snapshot = s.snapshot()
+	if snapshot.Status != StatusInProgress || snapshot.CurrentTurn != 3 || snapshot.RemainingTime != 60 {
+		t.Fatalf("after the break: %s, user %d on the clock with %vs", snapshot.Status, snapshot.CurrentTurn, snapshot.RemainingTime)
+	}
+}
+
+func TestReconnectResumesFromLastSeq(t *testing.T) {
+	s, commissioner := startSimulation(t, 2)
+	s.pickBest() // 1
+	s.pickBest() // 2
+	s.drain()
+
+	team := s.clients[1]
+	s.disconnect(team)
+	s.pickBest() // 3
+	s.pickBest() // 3
+	s.pickBest() // 2
+	s.drain(commissioner)
+
+	// Resuming replays exactly what was missed; receive fails on any gap
+	resumed := s.connect(1, team.lastSeq)
+	if resumed.picksSeen != 3 {
+		t.Fatalf("resumed client replayed %d picks, want the 3 it missed", resumed.picksSeen)
+	}
+	if room := s.service.manager.LastSeq(); resumed.lastSeq != room {
+		t.Fatalf("resumed client at seq %d, room at %d", resumed.lastSeq, room)
+	}
+}
+
+func TestSimultaneousPicksForTheSamePick(t *testing.T) {
+	s, _ := startSimulation(t, 1)
+	manager := s.connect(1, 0)
+	coManager := s.connect(1, 0)
+
+	// Both co-managers submit pick 1 at once; exactly one wins
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, c := range []*simClient{manager, coManager} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.send(c, MakePickMessage{Type: MsgTypeMakePick, UserID: 1, PlayerID: 101 + i, PickNumber: 1})
+		}()
+	}
+	wg.Wait()
+
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("both or neither pick succeeded: %v, %v", errs[0], errs[1])
+	}
+	for _, err := range errs {
+		if err != nil && ErrorCodeOf(err) != ErrCodeRuleViolation {
+			t.Fatalf("losing pick failed with %v", err)
+		}
+	}
+	if picks := s.snapshot().PickHistory; len(picks) != 1 || picks[0].UserID != 1 {
+		t.Fatalf("picks = %+v", picks)
+	}
+}
+
+func TestRestoredRoomPausesWithTimeLeft(t *testing.T) {
+	s, _ := startSimulation(t, 2)
+	s.pickBest()
+	s.clock.Advance(25 * time.Second)
+	s.pickBest() // The last change: user 3 has 60 seconds from here
+
+	room := s.room()
+	var seq int
+	room.do(func() { seq = room.seq })
+	s.waitFor("the draft log to be saved", func() bool { return len(s.store.DraftEvents()) == seq })
+
+	// The server restarts 10 minutes later
+	room.stop()
+	s.clock.Advance(10 * time.Minute)
+	restored := newSimulation(t, 1, 20, nil)
+	restored.clock = s.clock
+	restored.service.clock = s.clock
+	if err := restored.service.RestoreRoom(simEventID, simMaxRounds, restored.pool, nil, s.store.DraftEvents()); err != nil {
+		t.Fatalf("RestoreRoom: %v", err)
+	}
+
+	snapshot := restored.snapshot()
+	if snapshot.Status != StatusPaused || snapshot.PauseReason != PauseReasonRestored {
+		t.Fatalf("restored as %s (%q), want paused", snapshot.Status, snapshot.PauseReason)
+	}
+	if snapshot.RemainingTime != 60 || snapshot.CurrentTurn != 3 || len(snapshot.PickHistory) != 2 {
+		t.Fatalf("restored with user %d on the clock, %vs left and %d picks", snapshot.CurrentTurn, snapshot.RemainingTime, len(snapshot.PickHistory))
+	}
+}
+
+func TestRoundsCannotExceedPicksPerTeam(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	commissioner := s.connect(0, 0)
+	s.expectError(s.send(commissioner, StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2, 3}, TotalRounds: simMaxRounds + 1, TimerDuration: 60}), ErrCodeRuleViolation)
+	_, err := s.service.StartDraft(simEventID, []int{1, 2, 3}, simMaxRounds+1, time.Minute, nil)
+	s.expectError(err, ErrCodeRuleViolation)
+	s.expectError(s.service.CreateRoom(simEventID, 0, s.pool, nil), ErrCodeRuleViolation)
+
+	// A log drafting more rounds than the event now allows isn't restored
+	s, _ = startSimulation(t, 2)
+	s.pickBest()
+	room := s.room()
+	var seq int
+	room.do(func() { seq = room.seq })
+	s.waitFor("the draft log to be saved", func() bool { return len(s.store.DraftEvents()) == seq })
+	restored := newSimulation(t, 1, 20, nil)
+	s.expectError(restored.service.RestoreRoom(simEventID, 1, restored.pool, nil, s.store.DraftEvents()), ErrCodeRuleViolation)
+
+	mocks := NewMockDrafts(Config{
+		Mock:      MockConfig{TimerDuration: time.Minute, MaxRooms: 1, MaxPerTeam: 1, Lifetime: time.Minute},
+		Queue:     DefaultQueueConfig(),
+		AutoPilot: s.service.autoPilotConfig,
+	}, s.store)
+	_, err = mocks.Create(simEventID, 2, s.pool, nil, 5, MockDraftOptions{Teams: 4, Rounds: 3})
+	s.expectError(err, ErrCodeRuleViolation)
+	if len(mocks.rooms) != 0 {
+		t.Fatal("refused mock draft left a room open")
+	}
+}
+
+func TestMockDraftSavesNothing(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	config := s.service.autoPilotConfig
+	mocks := NewMockDrafts(Config{
+		Mock:      MockConfig{TimerDuration: 50 * time.Millisecond, BotPickDelay: time.Millisecond, MaxRooms: 1, MaxPerTeam: 1, Lifetime: time.Minute},
+		Queue:     DefaultQueueConfig(),
+		AutoPilot: config,
+	}, s.store)
+
+	mock, err := mocks.Create(simEventID, simMaxRounds, s.pool, models.Stipulations{}, 5, MockDraftOptions{Teams: 4, Rounds: 3, Position: 2})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	t.Cleanup(func() { mocks.Close(mock.ID) })
+	if _, err := mocks.Create(simEventID, simMaxRounds, s.pool, nil, 5, MockDraftOptions{Teams: 4, Rounds: 3}); ErrorCodeOf(err) != ErrCodeRateLimited {
+		t.Fatalf("second mock draft: %v, want RATE_LIMITED", err)
+	}
+
+	room := mocks.rooms[mock.ID]
+	select {
+	case <-room.service.GetRoom().Completed():
+	case <-time.After(5 * time.Second):
+		t.Fatal("mock draft didn't finish")
+	}
+	for _, pick := range room.service.GetRoom().GetSnapshot().PickHistory {
+		if pick.UserID < 0 && pick.Strategy != StrategyBestAvailable && pick.Strategy != StrategyRosterNeed {
+			t.Fatalf("bot pick %+v", pick)
+		}
+	}
+
+	// The real event's store saw nothing
+	if picks, statuses := s.store.Picks(), s.store.Statuses(); len(picks) != 0 || len(statuses) != 0 {
+		t.Fatalf("mock draft saved %d picks and statuses %v", len(picks), statuses)
+	}
+	if !mocks.Close(mock.ID) || mocks.Close(mock.ID) {
+		t.Fatal("a mock draft closes once")
+	}
+}
+
+func TestMockDraftsArePerTeam(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	mocks := NewMockDrafts(Config{
+		Mock:      MockConfig{TimerDuration: time.Minute, BotPickDelay: time.Minute, MaxRooms: 3, MaxPerTeam: 1, Lifetime: time.Minute},
+		Queue:     DefaultQueueConfig(),
+		AutoPilot: s.service.autoPilotConfig,
+	}, s.store)
+	create := func(userID int) error {
+		mock, err := mocks.Create(simEventID, simMaxRounds, s.pool, nil, userID, MockDraftOptions{Teams: 2, Rounds: 1})
+		if err == nil {
+			t.Cleanup(func() { mocks.Close(mock.ID) })
+		}
+		return err
+	}
+
+	if err := create(5); err != nil {
+		t.Fatalf("team 5's mock draft: %v", err)
+	}
+	s.expectError(create(5), ErrCodeRateLimited)
+	if err := create(6); err != nil {
+		t.Fatalf("team 6's mock draft: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package draft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable, machine-readable reason a client request failed.
+// Clients should branch on the code; the message is for display only.
+type ErrorCode string
+
+const (
+	ErrCodeNotYourTurn       ErrorCode = "NOT_YOUR_TURN"
+	ErrCodePlayerUnavailable ErrorCode = "PLAYER_UNAVAILABLE"
+	ErrCodeDraftNotActive    ErrorCode = "DRAFT_NOT_ACTIVE"
+	ErrCodeRuleViolation     ErrorCode = "RULE_VIOLATION"
+	ErrCodeForbidden         ErrorCode = "FORBIDDEN"
+	ErrCodeRateLimited       ErrorCode = "RATE_LIMITED"
+	ErrCodeInvalidMessage    ErrorCode = "INVALID_MESSAGE" // Malformed or unknown message
+	ErrCodeInternal          ErrorCode = "INTERNAL_ERROR"  // Server-side failure, e.g. the database
+)
+
+// Error is a request failure with a code the client can act on
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError creates an Error with a formatted message
+func newError(code ErrorCode, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorCodeOf returns the code of err, or ErrCodeInternal if err is not an *Error
+func ErrorCodeOf(err error) ErrorCode {
+	var draftErr *Error
+	if errors.As(err, &draftErr) {
+		return draftErr.Code
+	}
+	return ErrCodeInternal
+}
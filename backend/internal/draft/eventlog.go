@@ -0,0 +1,366 @@
+package draft
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// Draft log entry types. Every change to a draft is appended to the event's log
+// as one of these, and a room's state is what applying its log in order produces.
+const (
+	DraftEventStarted      = "started"
+	DraftEventPickMade     = "pick_made"
+	DraftEventTimerExpired = "timer_expired" // Followed by the auto-draft pick
+	DraftEventPaused       = "paused"
+	DraftEventResumed      = "resumed"
+
+	DraftEventAutoPilotChanged = "auto_pilot_changed"
+	DraftEventQueueSet         = "queue_set"
+	DraftEventStrategySet      = "strategy_set"
+)
+
+// draftStartedPayload is the payload of a started entry
+type draftStartedPayload struct {
+	PickOrder        []int   `json:"pickOrder"`
+	TotalRounds      int     `json:"totalRounds"`
+	TimerDuration    float64 `json:"timerDuration"` // Seconds per pick
+	AvailablePlayers []int   `json:"availablePlayers"`
+
+	ScheduledBreaks []ScheduledBreak `json:"scheduledBreaks,omitempty"`
+}
+
+// A pick_made entry's payload is the PickResult, without its times: the pick was
+// made at the entry's time, against the deadline the log gives its turn.
+
+// timerExpiredPayload is the payload of a timer_expired entry
+type timerExpiredPayload struct {
+	PickNumber int `json:"pickNumber"`
+	UserID     int `json:"userID"`
+}
+
+// draftPausedPayload is the payload of a paused entry
+type draftPausedPayload struct {
+	RemainingTime float64    `json:"remainingTime"` // Seconds left on the clock
+	Reason        string     `json:"reason"`
+	ResumeAt      *time.Time `json:"resumeAt,omitempty"` // When the draft resumes on its own, if it does
+}
+
+// autoPilotChangedPayload is the payload of an auto_pilot_changed entry
+type autoPilotChangedPayload struct {
+	UserID  int    `json:"userID"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"` // AutoPilotRequested, AutoPilotTimeouts or AutoPilotActivity
+}
+
+// queueSetPayload is the payload of a queue_set entry, replacing a team's preference queue
+type queueSetPayload struct {
+	UserID    int   `json:"userID"`
+	PlayerIDs []int `json:"playerIDs"`
+}
+
+// strategySetPayload is the payload of a strategy_set entry; an empty strategy is the default
+type strategySetPayload struct {
+	UserID   int    `json:"userID"`
+	Strategy string `json:"strategy"`
+}
+
+// projection is the draft state that's folded from the log. Applying an entry
+// only ever changes the projection, using the entry's time in place of the
+// clock, so the same log always produces the same state.
+type projection struct {
+	eventID          int           // ID of the event for which the draft is occurring
+	seq              int           // Seq of the last applied log entry
+	draftStatus      DraftStatus   // Status of the draft
+	currentTurnID    int           // ID of the user whose turn it currently is
+	roundNumber      int           // The number of what round it is
+	pickOrder        []int         // Order of user IDs for drafting
+	currentPickIndex int           // Current position in the draft (picks made so far)
+	timerDuration    time.Duration // How long each user has to pick
+	turnDeadline     time.Time     // When the current turn expires (for client countdown)
+	turnStartedAt    time.Time     // When the current turn's timer last (re)started
+	remainingTime    time.Duration // Time remaining when paused (for resume)
+	totalRounds      int           // Total rounds in the draft (picks per team)
+	availablePlayers []int         // Player IDs available to draft
+	pickHistory      []PickResult  // All picks made in order (for reconnection sync)
+	pauseReason      string        // Why the draft is paused
+	resumeAt         time.Time     // When the pause ends on its own; zero if it doesn't
+	scheduledBreaks  []ScheduledBreak
+	autoPilot        map[int]bool   // User IDs of teams on auto-pilot
+	missedTurns      map[int]int    // Turns in a row each team's timer has run out
+	queues           map[int][]int  // Each team's preference queue, by user ID
+	strategies       map[int]string // Each team's auto-draft strategy, if it chose one
+}
+
+// apply folds log entries into the projection in order. They're applied to a
+// single copy of the projection, which replaces it only once every entry has
+// applied, so an entry that doesn't follow from the state before it returns an
+// error and leaves the projection unchanged.
+func (p *projection) apply(events ...models.DraftEvent) error {
+	next := p.clone()
+	for _, event := range events {
+		if event.Seq != next.seq+1 {
+			return fmt.Errorf("draft event %d does not follow %d", event.Seq, next.seq)
+		}
+		if err := next.applyChange(event.Type, event.Payload, event.CreatedAt); err != nil {
+			return fmt.Errorf("draft event %d (%s): %w", event.Seq, event.Type, err)
+		}
+		next.seq = event.Seq
+	}
+	*p = next
+	return nil
+}
+
+// applyChange makes the state change an entry describes, as of at
+func (p *projection) applyChange(eventType string, payload json.RawMessage, at time.Time) error {
+	switch eventType {
+	case DraftEventStarted:
+		var started draftStartedPayload
+		if err := json.Unmarshal(payload, &started); err != nil {
+			return err
+		}
+		if p.draftStatus != StatusNotStarted {
+			return fmt.Errorf("draft already started")
+		}
+		if len(started.PickOrder) == 0 || started.TotalRounds < 1 || started.TimerDuration <= 0 {
+			return fmt.Errorf("invalid draft settings")
+		}
+		p.pickOrder = started.PickOrder
+		p.totalRounds = started.TotalRounds
+		p.timerDuration = fromSeconds(started.TimerDuration)
+		p.availablePlayers = started.AvailablePlayers
+		p.scheduledBreaks = started.ScheduledBreaks
+		p.currentPickIndex = 0
+		p.draftStatus = StatusInProgress
+		p.setTurn()
+		p.startTurn(at, p.timerDuration)
+
+	case DraftEventPickMade:
+		var pick PickResult
+		if err := json.Unmarshal(payload, &pick); err != nil {
+			return err
+		}
+		if p.draftStatus != StatusInProgress && p.draftStatus != StatusPaused {
+			return fmt.Errorf("draft is not active")
+		}
+		if pick.PickNumber != p.currentPickIndex+1 || pick.Round != p.roundNumber || pick.UserID != p.currentTurnID {
+			return fmt.Errorf("pick %d by user %d is not the pick on the clock", pick.PickNumber, pick.UserID)
+		}
+		if !slices.Contains(p.availablePlayers, pick.PlayerID) {
+			return fmt.Errorf("player %d is not available", pick.PlayerID)
+		}
+
+		// The deadline moves out by any time spent paused, so the turn
+		// effectively started a full timer before it
+		pick.EventID = p.eventID
+		pick.PickedAt = at
+		pick.TurnStartedAt = p.turnDeadline.Add(-p.timerDuration)
+		pick.TurnDeadline = p.turnDeadline
+
+		p.availablePlayers = slices.DeleteFunc(p.availablePlayers, func(id int) bool {
+			return id == pick.PlayerID
+		})
+		p.pickHistory = append(p.pickHistory, pick)
+		p.currentPickIndex++
+		if !pick.AutoDraft {
+			delete(p.missedTurns, pick.UserID)
+		}
+
+		// Picking while paused resumes the draft
+		p.draftStatus = StatusInProgress
+		p.clearPause()
+		if p.currentPickIndex >= p.totalPicks() {
+			p.draftStatus = StatusCompleted
+			return nil
+		}
+		p.setTurn()
+		p.startTurn(at, p.timerDuration)
+
+	case DraftEventTimerExpired:
+		var expired timerExpiredPayload
+		if err := json.Unmarshal(payload, &expired); err != nil {
+			return err
+		}
+		if p.draftStatus != StatusInProgress || expired.PickNumber != p.currentPickIndex+1 {
+			return fmt.Errorf("pick %d is not on the clock", expired.PickNumber)
+		}
+		p.missedTurns = mapWith(p.missedTurns, expired.UserID, p.missedTurns[expired.UserID]+1)
+
+	case DraftEventPaused:
+		var paused draftPausedPayload
+		if err := json.Unmarshal(payload, &paused); err != nil {
+			return err
+		}
+		if p.draftStatus != StatusInProgress {
+			return fmt.Errorf("can only pause an in-progress draft")
+		}
+		p.remainingTime = fromSeconds(paused.RemainingTime)
+		p.draftStatus = StatusPaused
+		p.pauseReason = paused.Reason
+		if paused.ResumeAt != nil {
+			p.resumeAt = *paused.ResumeAt
+		}
+
+	case DraftEventResumed:
+		if p.draftStatus != StatusPaused {
+			return fmt.Errorf("can only resume a paused draft")
+		}
+		p.draftStatus = StatusInProgress
+		p.clearPause()
+		p.startTurn(at, p.remainingTime)
+
+	case DraftEventAutoPilotChanged:
+		var changed autoPilotChangedPayload
+		if err := json.Unmarshal(payload, &changed); err != nil {
+			return err
+		}
+		if p.draftStatus == StatusCompleted {
+			return fmt.Errorf("draft is completed")
+		}
+		if changed.Enabled {
+			p.autoPilot = mapWith(p.autoPilot, changed.UserID, true)
+		} else {
+			delete(p.autoPilot, changed.UserID)
+		}
+		// Either way the count starts over, so a team taken off auto-pilot gets
+		// the full number of timeouts before it's put back on
+		delete(p.missedTurns, changed.UserID)
+
+	case DraftEventQueueSet:
+		var set queueSetPayload
+		if err := json.Unmarshal(payload, &set); err != nil {
+			return err
+		}
+		if p.draftStatus == StatusCompleted {
+			return fmt.Errorf("draft is completed")
+		}
+		p.queues = mapWith(p.queues, set.UserID, set.PlayerIDs)
+
+	case DraftEventStrategySet:
+		var set strategySetPayload
+		if err := json.Unmarshal(payload, &set); err != nil {
+			return err
+		}
+		if p.draftStatus == StatusCompleted {
+			return fmt.Errorf("draft is completed")
+		}
+		if set.Strategy == "" {
+			delete(p.strategies, set.UserID)
+		} else {
+			p.strategies = mapWith(p.strategies, set.UserID, set.Strategy)
+		}
+
+	default:
+		return fmt.Errorf("unknown draft event type %q", eventType)
+	}
+	return nil
+}
+
+// clone copies the projection so a failed apply can't leave it half-changed
+func (p *projection) clone() projection {
+	next := *p
+	next.pickOrder = slices.Clone(p.pickOrder)
+	next.availablePlayers = slices.Clone(p.availablePlayers)
+	next.pickHistory = slices.Clone(p.pickHistory)
+	next.scheduledBreaks = slices.Clone(p.scheduledBreaks)
+	next.autoPilot = maps.Clone(p.autoPilot)
+	next.missedTurns = maps.Clone(p.missedTurns)
+	next.queues = maps.Clone(p.queues) // Queues are replaced whole, never changed in place
+	next.strategies = maps.Clone(p.strategies)
+	return next
+}
+
+// mapWith sets m[key] to value, making the map if it's nil, and returns it
+func mapWith[V any](m map[int]V, key int, value V) map[int]V {
+	if m == nil {
+		m = make(map[int]V)
+	}
+	m[key] = value
+	return m
+}
+
+// clearPause forgets the reason and resume time of a pause that has ended
+func (p *projection) clearPause() {
+	p.pauseReason = ""
+	p.resumeAt = time.Time{}
+}
+
+// totalPicks is the number of picks in the whole draft
+func (p *projection) totalPicks() int {
+	return len(p.pickOrder) * p.totalRounds
+}
+
+// setTurn sets the round and the team on the clock for currentPickIndex.
+// Uses snake draft: 1→2→3→4→4→3→2→1→1→2→3→4...
+func (p *projection) setTurn() {
+	numPlayers := len(p.pickOrder)
+	p.roundNumber = p.currentPickIndex/numPlayers + 1
+
+	// Snake draft logic: odd rounds go forward, even rounds go backward
+	positionInRound := p.currentPickIndex % numPlayers
+	if p.roundNumber%2 == 0 {
+		positionInRound = numPlayers - 1 - positionInRound
+	}
+	p.currentTurnID = p.pickOrder[positionInRound]
+}
+
+// startTurn puts the current turn on the clock at at, with d left to pick
+func (p *projection) startTurn(at time.Time, d time.Duration) {
+	p.turnStartedAt = at
+	p.turnDeadline = at.Add(d)
+}
+
+// snapshot returns the projection as of now, for client synchronization
+func (p *projection) snapshot(now time.Time) DraftSnapshot {
+	// Calculate remaining time for paused state, or time until deadline for active state
+	var remainingTime float64
+	switch p.draftStatus {
+	case StatusPaused:
+		remainingTime = p.remainingTime.Seconds()
+	case StatusInProgress:
+		remainingTime = max(p.turnDeadline.Sub(now).Seconds(), 0)
+	}
+
+	var resumeAt int64
+	if !p.resumeAt.IsZero() {
+		resumeAt = p.resumeAt.Unix()
+	}
+
+	// Copy slices to avoid data races
+	return DraftSnapshot{
+		EventID:          p.eventID,
+		Status:           p.draftStatus,
+		CurrentTurn:      p.currentTurnID,
+		RoundNumber:      p.roundNumber,
+		CurrentPickIndex: p.currentPickIndex,
+		TotalRounds:      p.totalRounds,
+		PickOrder:        append([]int{}, p.pickOrder...),
+		AvailablePlayers: append([]int{}, p.availablePlayers...),
+		TurnDeadline:     p.turnDeadline.Unix(),
+		RemainingTime:    remainingTime,
+		PickHistory:      append([]PickResult{}, p.pickHistory...),
+		PauseReason:      p.pauseReason,
+		ResumeAt:         resumeAt,
+		ScheduledBreaks:  slices.Clone(p.scheduledBreaks),
+		AutoPilotUserIDs: slices.Sorted(maps.Keys(p.autoPilot)),
+	}
+}
+
+// Rebuild folds an event's draft log into the state it describes, as of now.
+// LastSeq is zero: the snapshot isn't tied to a room's broadcasts.
+func Rebuild(eventID int, events []models.DraftEvent) (DraftSnapshot, error) {
+	p := projection{eventID: eventID, draftStatus: StatusNotStarted}
+	if err := p.apply(events...); err != nil {
+		return DraftSnapshot{}, err
+	}
+	return p.snapshot(time.Now()), nil
+}
+
+// fromSeconds converts seconds as stored in the log to a duration
+func fromSeconds(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
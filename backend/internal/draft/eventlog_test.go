@@ -0,0 +1,80 @@
+package draft
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// logEntry makes the draft log entry with seq, a minute after the draft started
+func logEntry(t *testing.T, seq int, eventType string, payload any) models.DraftEvent {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 4, 9, 18, 0, 0, 0, time.UTC).Add(time.Duration(seq) * time.Minute)
+	return models.DraftEvent{EventID: simEventID, Seq: seq, Type: eventType, Payload: data, CreatedAt: at}
+}
+
+func TestFailedFoldLeavesProjectionUnchanged(t *testing.T) {
+	p := projection{eventID: simEventID, draftStatus: StatusNotStarted}
+	started := logEntry(t, 1, DraftEventStarted, draftStartedPayload{PickOrder: []int{1, 2}, TotalRounds: 2, TimerDuration: 60, AvailablePlayers: []int{8, 9, 10, 11}})
+	if err := p.apply(started); err != nil {
+		t.Fatalf("applying started: %v", err)
+	}
+	before := p.snapshot(started.CreatedAt)
+
+	for name, tail := range map[string][]models.DraftEvent{
+		"seq gap": {
+			logEntry(t, 2, DraftEventPickMade, PickResult{UserID: 1, PlayerID: 8, PickNumber: 1, Round: 1}),
+			logEntry(t, 4, DraftEventPickMade, PickResult{UserID: 2, PlayerID: 9, PickNumber: 2, Round: 1}),
+		},
+		"player taken": {
+			logEntry(t, 2, DraftEventPickMade, PickResult{UserID: 1, PlayerID: 8, PickNumber: 1, Round: 1}),
+			logEntry(t, 3, DraftEventPickMade, PickResult{UserID: 2, PlayerID: 8, PickNumber: 2, Round: 1}),
+		},
+		"removed entry type": {
+			logEntry(t, 2, DraftEventPickMade, PickResult{UserID: 1, PlayerID: 8, PickNumber: 1, Round: 1}),
+			logEntry(t, 3, "pick_undone", map[string]int{"pickNumber": 1}),
+		},
+	} {
+		if err := p.apply(tail...); err == nil {
+			t.Fatalf("%s: applied", name)
+		}
+		// The first pick applied to the copy, not to the projection or the slices it shares
+		if after := p.snapshot(started.CreatedAt); p.seq != 1 || len(after.PickHistory) != 0 || !slices.Equal(after.AvailablePlayers, before.AvailablePlayers) {
+			t.Fatalf("%s: projection at seq %d with %d picks and players %v, want it unchanged", name, p.seq, len(after.PickHistory), after.AvailablePlayers)
+		}
+	}
+}
+
+func TestRebuildFoldsTheWholeLog(t *testing.T) {
+	events := []models.DraftEvent{
+		logEntry(t, 1, DraftEventStarted, draftStartedPayload{PickOrder: []int{1, 2}, TotalRounds: 1, TimerDuration: 60, AvailablePlayers: []int{8, 9, 10}}),
+		logEntry(t, 2, DraftEventPickMade, PickResult{UserID: 1, PlayerID: 9, PickNumber: 1, Round: 1}),
+		logEntry(t, 3, DraftEventPaused, draftPausedPayload{RemainingTime: 30, Reason: "Dinner"}),
+		logEntry(t, 4, DraftEventResumed, struct{}{}),
+		logEntry(t, 5, DraftEventTimerExpired, timerExpiredPayload{PickNumber: 2, UserID: 2}),
+		logEntry(t, 6, DraftEventPickMade, PickResult{UserID: 2, PlayerID: 8, PickNumber: 2, Round: 1, AutoDraft: true, Strategy: StrategyBestAvailable}),
+	}
+	snapshot, err := Rebuild(simEventID, events)
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if snapshot.Status != StatusCompleted || len(snapshot.PickHistory) != 2 || !slices.Equal(snapshot.AvailablePlayers, []int{10}) {
+		t.Fatalf("rebuilt as %s with picks %+v and players %v", snapshot.Status, snapshot.PickHistory, snapshot.AvailablePlayers)
+	}
+	// Pick 2's turn restarted on resume with the 30 seconds left
+	if deadline := snapshot.PickHistory[1].TurnDeadline; !deadline.Equal(events[3].CreatedAt.Add(30 * time.Second)) {
+		t.Fatalf("pick 2 deadline %v, want 30s after the resume", deadline)
+	}
+
+	if _, err := Rebuild(simEventID, append(events, logEntry(t, 7, "config_changed", map[string]int{"totalRounds": 2}))); err == nil || !strings.Contains(err.Error(), "unknown draft event type") {
+		t.Fatalf("Rebuild with a config_changed entry: %v, want an unknown type", err)
+	}
+}
@@ -0,0 +1,78 @@
+package draft
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Presence statuses broadcast in presence_changed
+const (
+	PresenceActive = "active"
+	PresenceIdle   = "idle"
+)
+
+// idleCheckInterval is how often the idle monitor checks the team on the clock
+const idleCheckInterval = time.Second
+
+// HeartbeatConfig controls WebSocket liveness checks and idle detection
+type HeartbeatConfig struct {
+	PingInterval time.Duration // How often each client is pinged
+	PingTimeout  time.Duration // How long to wait for a pong before dropping the client
+	IdleAfter    time.Duration // Inactivity on the clock before a team is marked idle; 0 disables
+}
+
+// DefaultHeartbeatConfig returns the heartbeat settings used when none are configured
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		PingInterval: 15 * time.Second,
+		PingTimeout:  10 * time.Second,
+		IdleAfter:    30 * time.Second,
+	}
+}
+
+// heartbeat pings the client until ctx is done, closing the connection if a pong
+// doesn't arrive in time. Closing makes readPump fail, which unregisters the client.
+func (s *DraftService) heartbeat(ctx context.Context, c *Client) {
+	tick := make(chan struct{}, 1)
+	every(s.clock, s.heartbeatConfig.PingInterval, ctx.Done(), func(time.Time) {
+		select {
+		case tick <- struct{}{}:
+		default:
+		}
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, s.heartbeatConfig.PingTimeout)
+		err := c.Conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return // Connection already closing
+			}
+			log.Printf("Heartbeat failed, dropping stale client (userID: %d, memberID: %d): %v", c.UserID, c.MemberID, err)
+			c.Conn.CloseNow()
+			return
+		}
+	}
+}
+
+// monitorIdle periodically marks the team on the clock idle once none of its
+// connected members has interacted for IdleAfter
+func (s *DraftService) monitorIdle() {
+	every(s.clock, idleCheckInterval, s.closed, func(now time.Time) {
+		state := s.GetRoom()
+		if state == nil {
+			return
+		}
+		if userID, since, ok := state.OnTheClock(); ok {
+			s.manager.CheckIdle(userID, since, s.heartbeatConfig.IdleAfter, now)
+		}
+	})
+}
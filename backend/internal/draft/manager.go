@@ -1,148 +1,473 @@
 package draft
 
 import (
-	"encoding/json"
 	"log"
 	"sync"
+	"time"
+
+	"github.com/coder/websocket"
 )
 
+// Manager tracks the clients connected to the draft room and fans out broadcasts.
+// Every broadcast is assigned the next sequence number and fanned out under mu,
+// so clients always receive broadcasts in sequence order.
 type Manager struct {
 	// clients is keyed by *Client (pointer), so each connection gets its own
 	// entry even if multiple connections share the same UserID (e.g. multi-tab).
 	// Map lookups/deletes compare pointer addresses, not struct contents.
 	clients map[*Client]bool
-	mu         sync.Mutex
-	register   chan *Client
-	unregister chan *Client
-	broadcast  chan []byte // Channel for broadcasting messages to clients
+	mu      sync.Mutex
+	seq     uint64             // Sequence number of the latest broadcast
+	log     []sequencedMessage // Most recent broadcasts, oldest first, for resync
+	idle    map[int]bool       // User IDs of teams currently marked idle
+
+	autoPilot map[int]bool // User IDs of teams on auto-pilot, mirrored from the draft room
+	eventID   int          // Event of the current room; clients that joined for another event are turned away
+
+	queueConfig QueueConfig
+	metrics     queueMetrics
+	clock       Clock // Times clients' activity for the idle monitor
+}
+
+// sequencedMessage is an encoded broadcast kept in the replay log
+type sequencedMessage struct {
+	seq  uint64
+	data []byte
+}
+
+// MemberPresence identifies one connected co-manager of a team
+type MemberPresence struct {
+	MemberID int    `json:"memberID"`
+	Name     string `json:"name"`
 }
 
-func NewManager() *Manager {
+func NewManager(queueConfig QueueConfig, clock Clock) *Manager {
 	return &Manager{
-		clients:    make(map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan []byte),
-	}
-}
-
-func (m *Manager) Run() {
-	for {
-		select {
-		case client := <-m.register:
-			m.mu.Lock()
-			// Check if this userID already has a connection (multi-tab)
-			alreadyConnected := false
-			for c := range m.clients {
-				if c.UserID == client.UserID {
-					alreadyConnected = true
-					break
-				}
-			}
-
-			m.clients[client] = true
-			log.Printf("Connected new client (userID: %d)", client.UserID)
-
-			// Send user_joined for all already-connected users to the new client
-			// so their lobby shows who's already in the room.
-			seen := make(map[int]bool)
-			for c := range m.clients {
-				if c == client || seen[c.UserID] {
-					continue
-				}
-				seen[c.UserID] = true
-				existingMsg, _ := json.Marshal(map[string]interface{}{
-					"type":     MsgTypeUserJoined,
-					"userID":   c.UserID,
-					"username": c.Username,
-				})
-				select {
-				case client.Send <- existingMsg:
-				default:
-				}
-			}
-
-			// Only broadcast user_joined if this is the first connection for this userID
-			if !alreadyConnected {
-				joinMsg, _ := json.Marshal(map[string]interface{}{
-					"type":     MsgTypeUserJoined,
-					"userID":   client.UserID,
-					"username": client.Username,
-				})
-				for c := range m.clients {
-					if c == client {
-						continue
-					}
-					select {
-					case c.Send <- joinMsg:
-					default:
-						close(c.Send)
-						delete(m.clients, c)
-						log.Println("Removed dead client (send failed)")
-					}
-				}
-			}
-			m.mu.Unlock()
-		case client := <-m.unregister:
-			m.mu.Lock()
-			if m.clients[client] {
-				delete(m.clients, client)
-				close(client.Send)
-				log.Printf("Disconnected client (userID: %d)", client.UserID)
-
-				// Only broadcast user_left if no other connections remain for this userID
-				stillConnected := false
-				for c := range m.clients {
-					if c.UserID == client.UserID {
-						stillConnected = true
-						break
-					}
-				}
-				if !stillConnected {
-					leaveMsg, _ := json.Marshal(map[string]interface{}{
-						"type":   MsgTypeUserLeft,
-						"userID": client.UserID,
-					})
-					for c := range m.clients {
-						select {
-						case c.Send <- leaveMsg:
-						default:
-							close(c.Send)
-							delete(m.clients, c)
-							log.Println("Removed dead client (send failed)")
-						}
-					}
-				}
-			}
-			m.mu.Unlock()
-		case message := <-m.broadcast:
-			m.mu.Lock()
-			for client := range m.clients {
-				select {
-				case client.Send <- message:
-					// Message sent successfully
-				default:
-					// Channel full or closed - remove dead client
-					close(client.Send)
-					delete(m.clients, client)
-					log.Println("Removed dead client (send failed)")
-				}
-			}
-			m.mu.Unlock()
-		}
+		clients:     make(map[*Client]bool),
+		idle:        make(map[int]bool),
+		autoPilot:   make(map[int]bool),
+		queueConfig: queueConfig,
+		clock:       clock,
+	}
+}
+
+// initQueue gives a new client its bounded send queue.
+// ProtocolVersion must already be set, since it decides the backpressure policy.
+func (m *Manager) initQueue(client *Client) {
+	client.Send = make(chan []byte, m.queueConfig.Size)
+	client.policy = m.queueConfig.Policy
+	if client.ProtocolVersion < 2 {
+		client.policy = PolicyDisconnect // No seq numbers to detect a dropped message
 	}
+	client.metrics = &m.metrics
 }
 
-func (m *Manager) Register(client *Client) {
-	m.register <- client
+// Register adds a client to the room and announces it.
+// If resumeFrom is non-zero, broadcasts after that sequence number are replayed to
+// the client first; the return value reports whether the replay was possible.
+func (m *Manager) Register(client *Client, resumeFrom uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The room moved on to another event after the client was identified
+	if m.eventID != 0 && client.EventID != m.eventID {
+		m.disconnectLocked(client)
+		return false
+	}
+
+	resumed := resumeFrom > 0 && m.replayLocked(client, resumeFrom)
+
+	// Check if this member already has a connection (multi-tab)
+	memberAlreadyConnected := false
+	for c := range m.clients {
+		if !c.IsSpectator && c.UserID == client.UserID && c.MemberID == client.MemberID {
+			memberAlreadyConnected = true
+			break
+		}
+	}
+
+	client.lastActivity = m.clock.Now()
+	m.clients[client] = true
+	log.Printf("Connected new client (userID: %d, memberID: %d)", client.UserID, client.MemberID)
+
+	// Send user_joined for all already-connected teams to the new client
+	// so their lobby shows who's already in the room.
+	seen := make(map[int]bool)
+	for c := range m.clients {
+		if c == client || c.IsSpectator || seen[c.UserID] {
+			continue
+		}
+		seen[c.UserID] = true
+		existingMsg := encodeMessage(&UserJoinedMessage{
+			Envelope: Envelope{Type: MsgTypeUserJoined},
+			UserID:   c.UserID,
+			Username: c.Username,
+			Members:  m.connectedMembersLocked(c.UserID),
+		})
+		client.enqueue(existingMsg)
+	}
+
+	// Only broadcast user_joined if this is the first connection for this member,
+	// so co-managers joining an already-connected team update its member list.
+	// Spectators never appear in presence.
+	if !client.IsSpectator && !memberAlreadyConnected {
+		m.publishLocked(&UserJoinedMessage{
+			Envelope: Envelope{Type: MsgTypeUserJoined},
+			UserID:   client.UserID,
+			Username: client.Username,
+			Members:  m.connectedMembersLocked(client.UserID),
+		})
+	}
+
+	// Connecting counts as interacting
+	if !client.IsSpectator {
+		m.clearIdleLocked(client.UserID)
+	}
+
+	return resumed
 }
 
+// Unregister removes a client from the room and announces it if it was the member's last connection
 func (m *Manager) Unregister(client *Client) {
-	m.unregister <- client
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.clients[client] {
+		return
+	}
+	delete(m.clients, client)
+	client.closeSend()
+	log.Printf("Disconnected client (userID: %d, memberID: %d)", client.UserID, client.MemberID)
+
+	// Only broadcast user_left if no other connections remain for this member.
+	// An empty members list means the whole team has left. Spectators never send one.
+	stillConnected := client.IsSpectator
+	for c := range m.clients {
+		if !c.IsSpectator && c.UserID == client.UserID && c.MemberID == client.MemberID {
+			stillConnected = true
+			break
+		}
+	}
+	if !stillConnected {
+		if !m.teamConnectedLocked(client.UserID) {
+			delete(m.idle, client.UserID) // user_left with no members implies not present at all
+		}
+		m.publishLocked(&UserLeftMessage{
+			Envelope: Envelope{Type: MsgTypeUserLeft},
+			UserID:   client.UserID,
+			MemberID: client.MemberID,
+			Members:  m.connectedMembersLocked(client.UserID),
+		})
+	}
+}
+
+// disconnectAll closes every client's connection; each unregisters as its pump exits
+func (m *Manager) disconnectAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for c := range m.clients {
+		m.disconnectLocked(c)
+	}
+}
+
+// SetEvent records the event of a new draft room and disconnects the clients
+// that joined for any other event, so they don't receive its broadcasts
+func (m *Manager) SetEvent(eventID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.eventID = eventID
+	for c := range m.clients {
+		if c.EventID != eventID {
+			m.disconnectLocked(c)
+		}
+	}
+}
+
+// disconnectLocked closes a client's queue and connection; a registered client
+// unregisters as its pump exits. SSE streams end once their queue is closed.
+// Must be called while holding the mutex
+func (m *Manager) disconnectLocked(c *Client) {
+	c.closeSend()
+	if c.Conn != nil {
+		go c.Conn.Close(websocket.StatusGoingAway, "draft room closed")
+	}
+}
+
+// Publish assigns the next sequence number to a message and broadcasts it to all clients
+func (m *Manager) Publish(msg ServerMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.publishLocked(msg)
+}
+
+// publishLocked sequences, logs and fans out a broadcast
+// Must be called while holding the mutex
+func (m *Manager) publishLocked(msg ServerMessage) {
+	m.seq++
+	msg.setSeq(m.seq)
+	data := encodeMessage(msg)
+
+	m.log = append(m.log, sequencedMessage{seq: m.seq, data: data})
+	if len(m.log) > replayLogSize {
+		m.log = m.log[len(m.log)-replayLogSize:]
+	}
+
+	// enqueue never blocks, so a slow client can't stall the draft.
+	// Clients disconnected for overflow stay registered until their read pump
+	// exits, so Unregister still announces user_left.
+	for client := range m.clients {
+		client.enqueue(data)
+	}
+}
+
+// SendToCommissioners sends a message to every connected commissioner.
+// It isn't a broadcast, so it carries no seq and isn't replayed on resync.
+func (m *Manager) SendToCommissioners(msg ServerMessage) {
+	data := encodeMessage(msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for client := range m.clients {
+		if client.IsCommissioner {
+			client.enqueue(data)
+		}
+	}
+}
+
+// SendToTeam sends a message to every connection of a team.
+// It isn't a broadcast, so it carries no seq and isn't replayed on resync.
+func (m *Manager) SendToTeam(userID int, msg ServerMessage) {
+	data := encodeMessage(msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for client := range m.clients {
+		if !client.IsSpectator && client.UserID == userID {
+			client.enqueue(data)
+		}
+	}
+}
+
+// SetCommissioner marks a client as the commissioner's once it authenticates.
+// The flag is read under the mutex by the commissioner-only sends.
+func (m *Manager) SetCommissioner(client *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client.IsCommissioner = true
+}
+
+// CommissionerConnected reports whether any commissioner is connected
+func (m *Manager) CommissionerConnected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for client := range m.clients {
+		if client.IsCommissioner {
+			return true
+		}
+	}
+	return false
+}
+
+// Resync replays broadcasts after the given sequence number to a client.
+// Returns false if the replay log no longer reaches back that far, in which
+// case the client needs a full snapshot instead.
+func (m *Manager) Resync(client *Client, since uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.replayLocked(client, since)
+}
+
+// replayLocked enqueues logged broadcasts newer than since to the client
+// Must be called while holding the mutex
+func (m *Manager) replayLocked(client *Client, since uint64) bool {
+	if since > m.seq {
+		return false // From a previous server process
+	}
+	if since == m.seq {
+		return true // Nothing missed
+	}
+	if len(m.log) == 0 || m.log[0].seq > since+1 {
+		return false
+	}
+
+	// Entries are contiguous, so the missed ones are the tail of the log
+	missed := m.log[len(m.log)-int(m.seq-since):]
+	if len(missed) > client.queueSpace() {
+		return false // Too far behind to replay; fall back to a snapshot
+	}
+	for _, entry := range missed {
+		client.enqueue(entry.data)
+	}
+	return true
+}
+
+// Touch records that a client interacted, clearing its team's idle status
+func (m *Manager) Touch(client *Client, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	client.lastActivity = now
+	if !client.IsSpectator {
+		m.clearIdleLocked(client.UserID)
+	}
+}
+
+// TouchTeam records an interaction by a team that didn't come through one of its
+// connections (e.g. a REST pick), clearing its idle status
+func (m *Manager) TouchTeam(userID int, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for c := range m.clients {
+		if !c.IsSpectator && c.UserID == userID {
+			c.lastActivity = now
+		}
+	}
+	m.clearIdleLocked(userID)
 }
 
-func (m *Manager) Broadcast(message []byte) {
-	m.broadcast <- message
+// CheckIdle marks the team on the clock idle if none of its connected members has
+// interacted for idleAfter, counting from when its turn began at the earliest.
+// A team stays idle until one of its members interacts or it disconnects.
+func (m *Manager) CheckIdle(userID int, onClockSince time.Time, idleAfter time.Duration, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if idleAfter <= 0 || m.idle[userID] {
+		return
+	}
+
+	lastActivity := onClockSince
+	connected := false
+	for c := range m.clients {
+		if c.IsSpectator || c.UserID != userID {
+			continue
+		}
+		connected = true
+		if c.lastActivity.After(lastActivity) {
+			lastActivity = c.lastActivity
+		}
+	}
+	if !connected || now.Sub(lastActivity) < idleAfter {
+		return
+	}
+
+	m.idle[userID] = true
+	m.publishPresenceLocked(userID)
+}
+
+// PublishAutoPilot records whether a team is on auto-pilot and broadcasts its presence
+func (m *Manager) PublishAutoPilot(userID int, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled {
+		m.autoPilot[userID] = true
+	} else {
+		delete(m.autoPilot, userID)
+	}
+	m.publishPresenceLocked(userID)
+}
+
+// publishPresenceLocked broadcasts a team's idle status and auto-pilot
+// Must be called while holding the mutex
+func (m *Manager) publishPresenceLocked(userID int) {
+	status := PresenceActive
+	if m.idle[userID] {
+		status = PresenceIdle
+	}
+	m.publishLocked(&PresenceChangedMessage{
+		Envelope:  Envelope{Type: MsgTypePresenceChanged},
+		UserID:    userID,
+		Status:    status,
+		AutoPilot: m.autoPilot[userID],
+	})
+}
+
+// clearIdleLocked marks an idle team active again
+// Must be called while holding the mutex
+func (m *Manager) clearIdleLocked(userID int) {
+	if !m.idle[userID] {
+		return
+	}
+	delete(m.idle, userID)
+	m.publishPresenceLocked(userID)
+}
+
+// teamConnectedLocked reports whether any member of the team is still connected
+// Must be called while holding the mutex
+func (m *Manager) teamConnectedLocked(userID int) bool {
+	for c := range m.clients {
+		if !c.IsSpectator && c.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIdleUserIDs returns the user IDs of teams currently marked idle
+func (m *Manager) GetIdleUserIDs() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int, 0, len(m.idle))
+	for id := range m.idle {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// LastSeq returns the sequence number of the latest broadcast
+func (m *Manager) LastSeq() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.seq
+}
+
+// ResetLog clears the replay log when a new draft room is created,
+// so resync requests from before it fall back to a snapshot. Auto-pilot
+// belongs to the old room too.
+func (m *Manager) ResetLog() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.log = nil
+	clear(m.autoPilot)
+}
+
+// connectedMembersLocked returns the deduplicated connected members of a team
+// Must be called while holding the mutex
+func (m *Manager) connectedMembersLocked(userID int) []MemberPresence {
+	seen := make(map[int]bool)
+	members := []MemberPresence{}
+	for c := range m.clients {
+		if c.IsSpectator || c.UserID != userID || seen[c.MemberID] {
+			continue
+		}
+		seen[c.MemberID] = true
+		members = append(members, MemberPresence{MemberID: c.MemberID, Name: c.MemberName})
+	}
+	return members
+}
+
+// QueueStats reports send queue depths and drop counters
+func (m *Manager) QueueStats() QueueStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := QueueStats{
+		Clients:               len(m.clients),
+		QueueSize:             m.queueConfig.Size,
+		MessagesSent:          m.metrics.sent.Load(),
+		MessagesDropped:       m.metrics.dropped.Load(),
+		SlowClientDisconnects: m.metrics.disconnects.Load(),
+	}
+	for c := range m.clients {
+		depth := len(c.Send)
+		stats.TotalDepth += depth
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+	}
+	return stats
 }
 
 func (m *Manager) GetClientCount() int {
@@ -152,16 +477,30 @@ func (m *Manager) GetClientCount() int {
 }
 
 // GetConnectedUserIDs returns a deduplicated slice of user IDs for all connected clients.
+// Spectators are not included.
 func (m *Manager) GetConnectedUserIDs() []int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	seen := make(map[int]bool)
 	ids := make([]int, 0, len(m.clients))
 	for c := range m.clients {
-		if !seen[c.UserID] {
+		if !c.IsSpectator && !seen[c.UserID] {
 			seen[c.UserID] = true
 			ids = append(ids, c.UserID)
 		}
 	}
 	return ids
 }
+
+// GetConnectedMembers returns the connected members of every connected team, keyed by user ID
+func (m *Manager) GetConnectedMembers() map[int][]MemberPresence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	members := make(map[int][]MemberPresence)
+	for c := range m.clients {
+		if _, ok := members[c.UserID]; !ok && !c.IsSpectator {
+			members[c.UserID] = m.connectedMembersLocked(c.UserID)
+		}
+	}
+	return members
+}
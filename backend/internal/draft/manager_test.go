@@ -0,0 +1,124 @@
+package draft
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestDropOldestOnlyDropsBroadcasts(t *testing.T) {
+	broadcast := func(seq uint64) []byte {
+		return encodeMessage(&TurnChangedMessage{Envelope: Envelope{Type: MsgTypeTurnChanged, Seq: seq}})
+	}
+	ack := encodeMessage(&AckMessage{Envelope: Envelope{Type: MsgTypeAck}, RequestID: "r1"})
+
+	m := NewManager(QueueConfig{Size: 2, Policy: PolicyDropOldest}, newFakeClock())
+	c := &Client{ProtocolVersion: ProtocolVersion}
+	m.initQueue(c)
+	c.enqueue(broadcast(1))
+	c.enqueue(ack)
+	if !c.enqueue(broadcast(2)) || c.overflowed {
+		t.Fatal("dropping the oldest broadcast disconnected the client")
+	}
+	if stats := m.QueueStats(); stats.MessagesDropped != 1 {
+		t.Fatalf("%d messages dropped, want 1", stats.MessagesDropped)
+	}
+
+	// The ack is now the oldest; it can't be replayed, so the client is disconnected
+	if c.enqueue(broadcast(3)) || !c.overflowed {
+		t.Fatal("the ack was dropped instead of disconnecting the client")
+	}
+}
+
+// benchmarkQueueSize is the per-client queue used by the benchmarks.
+// Publishes come in bursts of half a queue, then the timer pauses while
+// clients that keep up catch up, as they would between picks.
+const benchmarkQueueSize = 64
+
+// benchmarkClients registers n simulated version 2 spectators, so large rooms
+// don't flood every queue with user_joined on setup. Clients beyond drained
+// never read their queue, like a browser tab that has stopped responding.
+func benchmarkClients(b *testing.B, m *Manager, n, drained int) []*Client {
+	b.Helper()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	clients := make([]*Client, n)
+	for i := range clients {
+		c := &Client{IsSpectator: true, ProtocolVersion: ProtocolVersion}
+		m.initQueue(c)
+		m.Register(c, 0)
+		if i < drained {
+			go func() {
+				for range c.Send {
+				}
+			}()
+		}
+		clients[i] = c
+	}
+	b.Cleanup(func() {
+		for _, c := range clients {
+			m.Unregister(c)
+		}
+	})
+	return clients
+}
+
+// waitForDrain spins until the given clients have emptied their queues
+func waitForDrain(clients []*Client) {
+	for _, c := range clients {
+		for len(c.Send) > 0 {
+			runtime.Gosched()
+		}
+	}
+}
+
+func benchmarkPublish(b *testing.B, policy BackpressurePolicy, n, drained int) {
+	m := NewManager(QueueConfig{Size: benchmarkQueueSize, Policy: policy}, SystemClock)
+	clients := benchmarkClients(b, m, n, drained)
+	waitForDrain(clients[:drained])
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Publish(&TurnChangedMessage{
+			Envelope:    Envelope{Type: MsgTypeTurnChanged},
+			CurrentTurn: i%12 + 1,
+			RoundNumber: 1,
+		})
+		if (i+1)%(benchmarkQueueSize/2) == 0 {
+			b.StopTimer()
+			waitForDrain(clients[:drained])
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+
+	stats := m.QueueStats()
+	b.ReportMetric(float64(stats.MessagesDropped)/float64(b.N), "drops/op")
+	b.ReportMetric(float64(stats.SlowClientDisconnects), "disconnects")
+}
+
+// BenchmarkPublish fans out to clients that all keep up
+func BenchmarkPublish(b *testing.B) {
+	for _, n := range []int{100, 500, 1000} {
+		b.Run(fmt.Sprintf("clients=%d", n), func(b *testing.B) {
+			benchmarkPublish(b, PolicyDropOldest, n, n)
+		})
+	}
+}
+
+// BenchmarkPublishStalledClients checks that a tenth of the room never reading
+// doesn't block publishing under either policy
+func BenchmarkPublishStalledClients(b *testing.B) {
+	for _, policy := range []BackpressurePolicy{PolicyDropOldest, PolicyDisconnect} {
+		for _, n := range []int{100, 500} {
+			b.Run(fmt.Sprintf("policy=%s/clients=%d", policy, n), func(b *testing.B) {
+				benchmarkPublish(b, policy, n, n-n/10)
+			})
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package draft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// MemoryStore keeps an event's picks, draft log, status changes, chat and
+// teams in memory, in place of the database. It backs mock drafts, which are
+// never saved, and simulations, which check what a draft saved. Like the
+// database, saving a pick or log entry that's already stored succeeds.
+type MemoryStore struct {
+	mu       sync.Mutex
+	picks    map[int]models.DraftResult // By pick number
+	events   map[int]models.DraftEvent
+	statuses []string // Every status the event was set to, in order
+	chat     []models.ChatMessage
+	chatID   int
+	teams    map[string]models.User       // By rejoin token
+	members  map[string]models.TeamMember // By member token
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		picks:   make(map[int]models.DraftResult),
+		events:  make(map[int]models.DraftEvent),
+		teams:   make(map[string]models.User),
+		members: make(map[string]models.TeamMember),
+	}
+}
+
+// storedConflict is a different pick or log entry already stored under the same number
+type storedConflict struct {
+	what string
+}
+
+func (e storedConflict) Error() string {
+	return fmt.Sprintf("a different %s is already stored", e.what)
+}
+
+func (storedConflict) Permanent() bool {
+	return true
+}
+
+func (s *MemoryStore) SavePick(ctx context.Context, pick *models.DraftResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.picks[pick.PickNumber]; ok {
+		if stored.UserID != pick.UserID || stored.PlayerID != pick.PlayerID || stored.Round != pick.Round {
+			return storedConflict{what: fmt.Sprintf("pick %d", pick.PickNumber)}
+		}
+		return nil
+	}
+	s.picks[pick.PickNumber] = *pick
+	return nil
+}
+
+func (s *MemoryStore) AppendDraftEvent(ctx context.Context, event *models.DraftEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.events[event.Seq]; ok {
+		if stored.Type != event.Type {
+			return storedConflict{what: fmt.Sprintf("draft event %d", event.Seq)}
+		}
+		return nil
+	}
+	s.events[event.Seq] = *event
+	return nil
+}
+
+func (s *MemoryStore) UpdateStatus(ctx context.Context, eventID int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *MemoryStore) SaveChatMessage(ctx context.Context, msg *models.ChatMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatID++
+	msg.ID = s.chatID
+	msg.CreatedAt = time.Now()
+	s.chat = append(s.chat, *msg)
+	return nil
+}
+
+func (s *MemoryStore) GetRecentChatMessages(ctx context.Context, eventID, limit int) ([]models.ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.chat[max(len(s.chat)-limit, 0):]), nil
+}
+
+func (s *MemoryStore) DeleteChatMessage(ctx context.Context, eventID, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chat = slices.DeleteFunc(s.chat, func(msg models.ChatMessage) bool {
+		return msg.ID == id
+	})
+	return nil
+}
+
+func (s *MemoryStore) GetEventIDBySpectatorToken(ctx context.Context, token string) (int, error) {
+	return 0, errors.New("no spectator links in memory")
+}
+
+func (s *MemoryStore) GetUserByRejoinToken(ctx context.Context, token string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.teams[token]
+	if !ok {
+		return nil, errors.New("no team with that rejoin token")
+	}
+	return &user, nil
+}
+
+func (s *MemoryStore) GetUserByMemberToken(ctx context.Context, token string) (*models.User, *models.TeamMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member, ok := s.members[token]
+	if !ok {
+		return nil, nil, errors.New("no team member with that token")
+	}
+	for _, user := range s.teams {
+		if user.ID == member.UserID {
+			return &user, &member, nil
+		}
+	}
+	return nil, nil, errors.New("team member's team isn't stored")
+}
+
+// AddMember lets member, of a team added with AddTeam, connect with memberToken
+func (s *MemoryStore) AddMember(member models.TeamMember, memberToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[memberToken] = member
+}
+
+// AddTeam lets the team user connect with rejoinToken
+func (s *MemoryStore) AddTeam(user models.User, rejoinToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[rejoinToken] = user
+}
+
+// Picks returns the stored picks in pick order
+func (s *MemoryStore) Picks() []models.DraftResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	picks := make([]models.DraftResult, 0, len(s.picks))
+	for _, number := range slices.Sorted(maps.Keys(s.picks)) {
+		picks = append(picks, s.picks[number])
+	}
+	return picks
+}
+
+// DraftEvents returns the stored draft log in order
+func (s *MemoryStore) DraftEvents() []models.DraftEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]models.DraftEvent, 0, len(s.events))
+	for _, seq := range slices.Sorted(maps.Keys(s.events)) {
+		events = append(events, s.events[seq])
+	}
+	return events
+}
+
+// Statuses returns every status the event was set to, in order
+func (s *MemoryStore) Statuses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.statuses)
+}
@@ -4,21 +4,52 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
 // Incoming message types (from client)
 const (
-	MsgTypeStartDraft = "start_draft"
-	MsgTypeMakePick   = "make_pick"
-	MsgTypePauseDraft = "pause_draft"
+	MsgTypeStartDraft  = "start_draft"
+	MsgTypeMakePick    = "make_pick"
+	MsgTypePauseDraft  = "pause_draft"
 	MsgTypeResumeDraft = "resume_draft"
+
+	MsgTypeSetAutoPilot = "set_auto_pilot"
+	MsgTypeSetQueue     = "set_queue"
+	MsgTypeSetStrategy  = "set_auto_draft_strategy"
+
+	MsgTypeDeleteChatMessage = "delete_chat_message" // Commissioner only
+	MsgTypeResyncFrom        = "resync_from"
+	MsgTypeAuthenticate      = "authenticate"
+)
+
+// stateChangingMessages are the incoming message types refused from spectators
+var stateChangingMessages = map[string]bool{
+	MsgTypeStartDraft:        true,
+	MsgTypeMakePick:          true,
+	MsgTypePauseDraft:        true,
+	MsgTypeResumeDraft:       true,
+	MsgTypeSetAutoPilot:      true,
+	MsgTypeSetQueue:          true,
+	MsgTypeSetStrategy:       true,
+	MsgTypeChatMessage:       true,
+	MsgTypeDeleteChatMessage: true,
+}
+
+// Bidirectional message types
+const (
+	MsgTypeChatMessage = "chat_message"
 )
 
 // Outgoing message types (to client)
 const (
+	MsgTypeHello          = "hello"
 	MsgTypeDraftStarted   = "draft_started"
 	MsgTypeDraftPaused    = "draft_paused"
 	MsgTypeDraftResumed   = "draft_resumed"
@@ -27,8 +58,26 @@ const (
 	MsgTypePickMade       = "pick_made"
 	MsgTypeTurnChanged    = "turn_changed"
 	MsgTypeError          = "error"
+	MsgTypeAck            = "ack"
 	MsgTypeUserJoined     = "user_joined"
 	MsgTypeUserLeft       = "user_left"
+
+	MsgTypePresenceChanged = "presence_changed"
+
+	MsgTypeChatMessageDeleted = "chat_message_deleted"
+
+	MsgTypeQueueUpdated = "queue_updated" // Sent to the team whose queue changed
+
+	MsgTypePersistenceFailed    = "persistence_failed"    // Commissioners only
+	MsgTypePersistenceRecovered = "persistence_recovered" // Commissioners only
+)
+
+// Chat limits
+const (
+	maxChatMessageLength = 500 // runes; well under the 32KB read limit
+	chatHistoryLimit     = 50  // messages included in draft_state
+	chatRateLimit        = 5   // messages allowed per chatRateWindow
+	chatRateWindow       = 10 * time.Second
 )
 
 // StartDraftMessage represents the payload for starting a draft
@@ -38,42 +87,99 @@ type StartDraftMessage struct {
 	PickOrder     []int  `json:"pickOrder"`
 	TotalRounds   int    `json:"totalRounds"`
 	TimerDuration int    `json:"timerDuration"` // in seconds
+
+	ScheduledBreaks []ScheduledBreak `json:"scheduledBreaks"` // Optional
+}
+
+// PauseDraftMessage represents the payload for pausing a draft
+// Duration is optional; when set, the draft resumes on its own after that many seconds
+type PauseDraftMessage struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Duration int    `json:"duration"`
 }
 
 // MakePickMessage represents the payload for making a pick
+// PickNumber is optional; when set, the pick is rejected if that pick has already been made,
+// so simultaneous submissions from co-managers can't consume two consecutive picks
 type MakePickMessage struct {
+	Type       string `json:"type"`
+	UserID     int    `json:"userID"` // Optional; only the commissioner may pick for another team
+	PlayerID   int    `json:"playerID"`
+	PickNumber int    `json:"pickNumber"`
+}
+
+// SetAutoPilotMessage turns the sending team's auto-pilot on or off
+type SetAutoPilotMessage struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// SetQueueMessage replaces the sending team's preference queue
+type SetQueueMessage struct {
+	Type      string `json:"type"`
+	PlayerIDs []int  `json:"playerIDs"`
+}
+
+// SetStrategyMessage sets the strategy used when the sending team's picks are
+// made for it; an empty strategy goes back to the default
+type SetStrategyMessage struct {
+	Type     string `json:"type"`
+	Strategy string `json:"strategy"`
+}
+
+// SendChatMessage represents the payload for sending a chat message
+type SendChatMessage struct {
+	Type string `json:"type"`
+	Body string `json:"body"`
+}
+
+// ResyncFromMessage requests replay of every broadcast after Seq
+type ResyncFromMessage struct {
+	Type string `json:"type"`
+	Seq  uint64 `json:"seq"`
+}
+
+// DeleteChatMessage represents the payload for deleting a chat message
+type DeleteChatMessage struct {
 	Type      string `json:"type"`
-	UserID    int    `json:"userID"`
-	PlayerID  int    `json:"playerID"`
-	AutoDraft bool   `json:"autoDraft"`
+	MessageID int    `json:"messageID"`
+}
+
+// AuthenticateMessage makes the sending connection the commissioner's.
+// The key is sent as a message so it never appears in a URL or access log.
+type AuthenticateMessage struct {
+	Type     string `json:"type"`
+	AdminKey string `json:"adminKey"`
 }
 
-// handleStartDraft initializes and starts the draft
+// handleStartDraft initializes and starts the draft; only the commissioner may do this
 // Requires CreateRoom to have been called first (via HTTP endpoint)
-func (s *DraftService) handleStartDraft(c *Client, data []byte) {
+func (s *DraftService) handleStartDraft(c *Client, data []byte) error {
+	if !c.IsCommissioner {
+		return newError(ErrCodeForbidden, "only the commissioner can start the draft")
+	}
+
 	var msg StartDraftMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
-		c.SendError("invalid start_draft message format")
-		return
+		return newError(ErrCodeInvalidMessage, "invalid start_draft message format")
 	}
 
-	s.mu.Lock()
-	state := s.state
+	state := s.GetRoom()
 	if state == nil {
-		s.mu.Unlock()
-		c.SendError("no draft room created - call CreateRoom first")
-		return
+		return newError(ErrCodeDraftNotActive, "no draft room created - call CreateRoom first")
 	}
 
+	return s.startDraft(state, msg.PickOrder, msg.TotalRounds, time.Duration(msg.TimerDuration)*time.Second, msg.ScheduledBreaks)
+}
+
+// startDraft starts a room's draft and the goroutines that persist it
+func (s *DraftService) startDraft(state *DraftState, pickOrder []int, totalRounds int, timerDuration time.Duration, breaks []ScheduledBreak) error {
 	// Start the draft using existing state (which has available players from CreateRoom)
-	timerDuration := time.Duration(msg.TimerDuration) * time.Second
 	availablePlayers := state.GetAvailablePlayers()
-	if err := state.StartDraft(msg.PickOrder, msg.TotalRounds, timerDuration, availablePlayers); err != nil {
-		s.mu.Unlock()
-		c.SendError(err.Error())
-		return
+	if err := state.StartDraft(pickOrder, totalRounds, timerDuration, availablePlayers, breaks); err != nil {
+		return err
 	}
-	s.mu.Unlock()
 
 	// Update event status to in_progress
 	eventID := state.GetEventID()
@@ -81,106 +187,309 @@ func (s *DraftService) handleStartDraft(c *Client, data []byte) {
 		log.Printf("Failed to update event status to in_progress: %v", err)
 	}
 
-	// Start the bridge goroutine to broadcast outgoing messages
-	go s.startOutgoingBridge(state)
-
-	// Start the persistence goroutine to save picks to database
+	// Start the persistence goroutines to save picks and the draft log to database
 	go s.startPickPersistence(state)
+	go s.startLogPersistence(state)
 
 	// Start the completion handler to update event status when draft ends
 	go s.startCompletionHandler(state)
 
 	log.Printf("Draft started for event %d", eventID)
+	return nil
 }
 
 // handleMakePick processes a pick from a user
-func (s *DraftService) handleMakePick(c *Client, data []byte) {
+func (s *DraftService) handleMakePick(c *Client, data []byte) error {
 	s.mu.RLock()
 	state := s.state
 	s.mu.RUnlock()
 
 	if state == nil {
-		c.SendError("no draft in progress")
-		return
+		return newError(ErrCodeDraftNotActive, "no draft in progress")
 	}
 
 	var msg MakePickMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
-		c.SendError("invalid make_pick message format")
-		return
+		return newError(ErrCodeInvalidMessage, "invalid make_pick message format")
 	}
 
-	if err := state.MakePick(msg.UserID, msg.PlayerID, msg.AutoDraft); err != nil {
-		c.SendError(err.Error())
-		return
+	// Teams pick for themselves; the commissioner may pick for any team
+	userID := c.UserID
+	if msg.UserID != 0 && msg.UserID != c.UserID {
+		if !c.IsCommissioner {
+			return newError(ErrCodeForbidden, "you can only pick for your own team")
+		}
+		userID = msg.UserID
 	}
+
+	_, err := state.MakePick(userID, msg.PlayerID, msg.PickNumber)
+	return err
 }
 
-// handlePauseDraft pauses an in-progress draft
-func (s *DraftService) handlePauseDraft(c *Client) {
+// handlePauseDraft pauses an in-progress draft; only the commissioner may do this
+func (s *DraftService) handlePauseDraft(c *Client, data []byte) error {
+	if !c.IsCommissioner {
+		return newError(ErrCodeForbidden, "only the commissioner can pause the draft")
+	}
+
 	s.mu.RLock()
 	state := s.state
 	s.mu.RUnlock()
 
 	if state == nil {
-		c.SendError("no draft in progress")
-		return
+		return newError(ErrCodeDraftNotActive, "no draft in progress")
 	}
 
-	if err := state.PauseDraft(); err != nil {
-		c.SendError(err.Error())
-		return
+	var msg PauseDraftMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid pause_draft message format")
+	}
+
+	if err := state.PauseDraft(msg.Reason, time.Duration(msg.Duration)*time.Second); err != nil {
+		return err
 	}
 
 	log.Printf("Draft paused for event %d", state.GetEventID())
+	return nil
 }
 
-// handleResumeDraft resumes a paused draft
-func (s *DraftService) handleResumeDraft(c *Client) {
+// handleResumeDraft resumes a paused draft; only the commissioner may do this
+func (s *DraftService) handleResumeDraft(c *Client) error {
+	if !c.IsCommissioner {
+		return newError(ErrCodeForbidden, "only the commissioner can resume the draft")
+	}
+
 	s.mu.RLock()
 	state := s.state
 	s.mu.RUnlock()
 
 	if state == nil {
-		c.SendError("no draft in progress")
-		return
+		return newError(ErrCodeDraftNotActive, "no draft in progress")
 	}
 
 	if err := state.ResumeDraft(); err != nil {
-		c.SendError(err.Error())
-		return
+		return err
 	}
 
 	log.Printf("Draft resumed for event %d", state.GetEventID())
+	return nil
 }
 
-// startOutgoingBridge reads from the draft state's outgoing channel and broadcasts to all clients
-func (s *DraftService) startOutgoingBridge(state *DraftState) {
-	for msg := range state.Outgoing() {
-		s.manager.Broadcast(msg)
+// handleSetAutoPilot turns the sending team's auto-pilot on or off
+func (s *DraftService) handleSetAutoPilot(c *Client, data []byte) error {
+	state := s.GetRoom()
+	if state == nil {
+		return newError(ErrCodeDraftNotActive, "no draft room created")
 	}
+
+	var msg SetAutoPilotMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid set_auto_pilot message format")
+	}
+
+	return state.SetAutoPilot(c.UserID, msg.Enabled)
 }
 
-// startPickPersistence reads from the draft state's pick results channel and saves to database
-func (s *DraftService) startPickPersistence(state *DraftState) {
-	for pick := range state.PickResults() {
-		ctx := context.Background()
-		if err := s.pickSaver.SavePick(ctx, pick.EventID, pick.UserID, pick.PlayerID, pick.PickNumber, pick.Round, pick.AutoDraft); err != nil {
-			log.Printf("Failed to persist pick: %v", err)
-		} else {
-			log.Printf("Persisted pick: event=%d user=%d player=%d pick#=%d round=%d auto=%v",
-				pick.EventID, pick.UserID, pick.PlayerID, pick.PickNumber, pick.Round, pick.AutoDraft)
-		}
+// handleSetQueue replaces the sending team's preference queue and shows the
+// new queue to all of the team's connections, so co-managers stay in step
+func (s *DraftService) handleSetQueue(c *Client, data []byte) error {
+	state := s.GetRoom()
+	if state == nil {
+		return newError(ErrCodeDraftNotActive, "no draft room created")
+	}
+
+	var msg SetQueueMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid set_queue message format")
+	}
+
+	if err := state.SetQueue(c.UserID, msg.PlayerIDs); err != nil {
+		return err
 	}
+	s.manager.SendToTeam(c.UserID, &QueueUpdatedMessage{
+		Envelope:  Envelope{Type: MsgTypeQueueUpdated},
+		UserID:    c.UserID,
+		PlayerIDs: state.GetQueue(c.UserID),
+	})
+	return nil
 }
 
-// startCompletionHandler waits for the draft to complete and updates event status
+// handleSetStrategy sets the sending team's auto-draft strategy
+func (s *DraftService) handleSetStrategy(c *Client, data []byte) error {
+	state := s.GetRoom()
+	if state == nil {
+		return newError(ErrCodeDraftNotActive, "no draft room created")
+	}
+
+	var msg SetStrategyMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid set_auto_draft_strategy message format")
+	}
+
+	return state.SetStrategy(c.UserID, msg.Strategy)
+}
+
+// startCompletionHandler waits for the draft to complete and updates event status.
+// The event isn't marked completed until every pick and log entry is saved, so
+// a completed event always has its full results.
 func (s *DraftService) startCompletionHandler(state *DraftState) {
-	<-state.Completed()
 	eventID := state.GetEventID()
+	for _, done := range []<-chan struct{}{state.Completed(), state.outbox.done(), state.logOutbox.done()} {
+		select {
+		case <-done:
+		case <-state.stopped:
+			return
+		case <-s.closed:
+			return
+		}
+	}
 	if err := s.eventUpdater.UpdateStatus(context.Background(), eventID, models.EventStatusCompleted); err != nil {
 		log.Printf("Failed to update event status to completed: %v", err)
 	} else {
 		log.Printf("Event %d marked as completed", eventID)
 	}
 }
+
+// handleChatMessage persists a chat message and broadcasts it to the room
+func (s *DraftService) handleChatMessage(c *Client, data []byte) error {
+	s.mu.RLock()
+	state := s.state
+	s.mu.RUnlock()
+
+	if state == nil {
+		return newError(ErrCodeDraftNotActive, "no draft room created")
+	}
+
+	var msg SendChatMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid chat_message message format")
+	}
+
+	body := strings.TrimSpace(msg.Body)
+	if body == "" {
+		return newError(ErrCodeInvalidMessage, "chat message cannot be empty")
+	}
+	if utf8.RuneCountInString(body) > maxChatMessageLength {
+		return newError(ErrCodeInvalidMessage, "chat message cannot exceed %d characters", maxChatMessageLength)
+	}
+
+	if !c.chatLimiter.allow(s.clock.Now()) {
+		return newError(ErrCodeRateLimited, "sending chat messages too quickly")
+	}
+
+	chat := &models.ChatMessage{
+		EventID:    state.GetEventID(),
+		UserID:     c.UserID,
+		SenderName: c.MemberName,
+		Body:       body,
+	}
+	if c.MemberID > 0 {
+		chat.MemberID = &c.MemberID
+	}
+	if err := s.chatStore.SaveChatMessage(context.Background(), chat); err != nil {
+		log.Printf("Failed to persist chat message: %v", err)
+		return newError(ErrCodeInternal, "failed to send chat message")
+	}
+
+	s.manager.Publish(&ChatPostedMessage{
+		Envelope: Envelope{Type: MsgTypeChatMessage},
+		Message:  chat,
+	})
+	return nil
+}
+
+// handleDeleteChatMessage removes a chat message; only the commissioner may do this
+func (s *DraftService) handleDeleteChatMessage(c *Client, data []byte) error {
+	if !c.IsCommissioner {
+		return newError(ErrCodeForbidden, "only the commissioner can delete chat messages")
+	}
+
+	s.mu.RLock()
+	state := s.state
+	s.mu.RUnlock()
+
+	if state == nil {
+		return newError(ErrCodeDraftNotActive, "no draft room created")
+	}
+
+	var msg DeleteChatMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid delete_chat_message message format")
+	}
+
+	eventID := state.GetEventID()
+	if err := s.chatStore.DeleteChatMessage(context.Background(), eventID, msg.MessageID); err != nil {
+		log.Printf("Failed to delete chat message %d: %v", msg.MessageID, err)
+		return newError(ErrCodeInternal, "failed to delete chat message")
+	}
+
+	s.manager.Publish(&ChatMessageDeletedMessage{
+		Envelope:  Envelope{Type: MsgTypeChatMessageDeleted},
+		MessageID: msg.MessageID,
+	})
+	return nil
+}
+
+// handleAuthenticate makes the client a commissioner if it sends the server's ADMIN_KEY
+func (s *DraftService) handleAuthenticate(c *Client, data []byte) error {
+	if c.IsSpectator {
+		return newError(ErrCodeForbidden, "spectators cannot authenticate as the commissioner")
+	}
+
+	var msg AuthenticateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid authenticate message format")
+	}
+	if !auth.IsAdminKey(msg.AdminKey) {
+		return newError(ErrCodeForbidden, "invalid admin key")
+	}
+
+	s.manager.SetCommissioner(c)
+	return nil
+}
+
+// handleResyncFrom replays broadcasts the client missed, or sends a full snapshot
+// if the replay log no longer reaches back to the requested sequence number
+func (s *DraftService) handleResyncFrom(c *Client, data []byte) error {
+	if c.ProtocolVersion < 2 {
+		return newError(ErrCodeInvalidMessage, "resync_from requires protocol version 2")
+	}
+
+	var msg ResyncFromMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return newError(ErrCodeInvalidMessage, "invalid resync_from message format")
+	}
+
+	if !s.manager.Resync(c, msg.Seq) {
+		s.sendStateToClient(c)
+	}
+	return nil
+}
+
+// recentChat loads recent chat history for the draft_state snapshot
+func (s *DraftService) recentChat(eventID int) []models.ChatMessage {
+	messages, err := s.chatStore.GetRecentChatMessages(context.Background(), eventID, chatHistoryLimit)
+	if err != nil {
+		log.Printf("Failed to load chat history: %v", err)
+		return []models.ChatMessage{}
+	}
+	return messages
+}
+
+// chatLimiter is a sliding-window rate limiter for a single client's chat messages
+type chatLimiter struct {
+	sent []time.Time
+}
+
+// allow reports whether a message sent at now is within the rate limit, and records it if so
+func (l *chatLimiter) allow(now time.Time) bool {
+	cutoff := now.Add(-chatRateWindow)
+	l.sent = slices.DeleteFunc(l.sent, func(t time.Time) bool {
+		return !t.After(cutoff)
+	})
+	if len(l.sent) >= chatRateLimit {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
@@ -0,0 +1,303 @@
+package draft
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// Mock draft limits
+const (
+	DefaultMockTeams = 8
+	maxMockTeams     = 12 // Same as a real draft room
+
+	// mockKeptAfterCompletion is how long a finished mock draft stays open for review
+	mockKeptAfterCompletion = 10 * time.Minute
+)
+
+// MockConfig controls mock drafts
+type MockConfig struct {
+	TimerDuration time.Duration // The real team's turn timer
+	BotPickDelay  time.Duration // How long bot teams take to pick
+	MaxRooms      int           // Mock drafts open at once, across all events
+	MaxPerTeam    int           // Mock drafts one team may have open at once
+	Lifetime      time.Duration // How long a mock draft stays open if it isn't finished
+}
+
+// DefaultMockConfig returns the mock draft settings used when none are configured
+func DefaultMockConfig() MockConfig {
+	return MockConfig{
+		TimerDuration: 30 * time.Second,
+		BotPickDelay:  time.Second,
+		MaxRooms:      20,
+		MaxPerTeam:    1,
+		Lifetime:      time.Hour,
+	}
+}
+
+// MockDraftOptions describe the mock draft a team asks for
+type MockDraftOptions struct {
+	Teams       int    // Teams in the draft, including the real one; 0 for DefaultMockTeams
+	Rounds      int    // Must be at least 1
+	Position    int    // The real team's first-round slot, from 1; 0 for a random slot
+	BotStrategy string // Every bot's auto-draft strategy; empty alternates best_available and roster_need
+}
+
+// MockDraft is a practice draft for one real team against bot teams. It runs
+// on the same engine and protocol as a real draft room, but nothing it does
+// is saved and the event itself is never touched.
+type MockDraft struct {
+	ID            string    `json:"id"`
+	EventID       int       `json:"eventID"`
+	UserID        int       `json:"userID"` // The real team
+	PickOrder     []int     `json:"pickOrder"`
+	TotalRounds   int       `json:"totalRounds"`
+	TimerDuration int       `json:"timerDuration"` // in seconds
+	Bots          []MockBot `json:"bots"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// MockBot is a bot team in a mock draft. Bot user IDs are negative, so they
+// never collide with a real team.
+type MockBot struct {
+	UserID   int    `json:"userID"`
+	Username string `json:"username"`
+	Strategy string `json:"strategy"`
+}
+
+// mockRoom is an open mock draft and the service running it
+type mockRoom struct {
+	draft   MockDraft
+	service *DraftService
+	expiry  Timer // Closes the mock draft; guarded by the MockDrafts mutex
+}
+
+// MockDrafts holds the open mock drafts. Each runs in its own DraftService,
+// backed by a MemoryStore instead of the database.
+type MockDrafts struct {
+	mu       sync.Mutex
+	rooms    map[string]*mockRoom
+	config   Config
+	teamAuth TeamAuthenticator
+	rng      *rand.Rand // Random draft positions; guarded by mu
+}
+
+// NewMockDrafts creates the mock draft registry; each mock draft's service uses
+// config with the mock settings applied, and teamAuth to authenticate its team.
+// Mock drafts expire on config's clock, and random positions come from its seed.
+func NewMockDrafts(config Config, teamAuth TeamAuthenticator) *MockDrafts {
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &MockDrafts{
+		rooms:    make(map[string]*mockRoom),
+		config:   config,
+		teamAuth: teamAuth,
+		rng:      newRand(config.Seed, config.Clock),
+	}
+}
+
+// mockTeamAuth only admits the team a mock draft is for
+type mockTeamAuth struct {
+	TeamAuthenticator
+	userID int
+}
+
+func (a mockTeamAuth) GetUserByRejoinToken(ctx context.Context, token string) (*models.User, error) {
+	user, err := a.TeamAuthenticator.GetUserByRejoinToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != a.userID {
+		return nil, fmt.Errorf("mock draft is for user %d, not %d", a.userID, user.ID)
+	}
+	return user, nil
+}
+
+func (a mockTeamAuth) GetUserByMemberToken(ctx context.Context, token string) (*models.User, *models.TeamMember, error) {
+	user, member, err := a.TeamAuthenticator.GetUserByMemberToken(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.ID != a.userID {
+		return nil, nil, fmt.Errorf("mock draft is for user %d, not %d", a.userID, user.ID)
+	}
+	return user, member, nil
+}
+
+// serviceConfig returns the settings for a mock draft's service. Idle presence
+// and unattended pauses only matter with other people in the room, and bots
+// pick after BotPickDelay.
+func (m *MockDrafts) serviceConfig() Config {
+	cfg := m.config
+	cfg.Heartbeat.IdleAfter = 0
+	cfg.Pause.AutoResumeAfter = 0
+	cfg.AutoPilot.PickDelay = cfg.Mock.BotPickDelay
+	return cfg
+}
+
+// Create opens and starts a mock draft of an event's players for the team
+// userID. The draft starts at once; the team connects with HandleWebSocket.
+// Like a real draft, it can't have more rounds than maxRounds, the event's max
+// picks per team.
+func (m *MockDrafts) Create(eventID, maxRounds int, players []models.Player, stipulations models.Stipulations, userID int, opts MockDraftOptions) (*MockDraft, error) {
+	if opts.Teams == 0 {
+		opts.Teams = DefaultMockTeams
+	}
+	if opts.Teams < 2 || opts.Teams > maxMockTeams {
+		return nil, newError(ErrCodeRuleViolation, "teams must be between 2 and %d", maxMockTeams)
+	}
+	if opts.Rounds < 1 {
+		return nil, newError(ErrCodeRuleViolation, "rounds must be at least 1")
+	}
+	if opts.Teams*opts.Rounds > len(players) {
+		return nil, newError(ErrCodeRuleViolation, "event has %d players, not enough for %d teams of %d", len(players), opts.Teams, opts.Rounds)
+	}
+	if opts.Position < 0 || opts.Position > opts.Teams {
+		return nil, newError(ErrCodeRuleViolation, "position must be between 1 and %d", opts.Teams)
+	}
+	if err := validateStrategy(opts.BotStrategy); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if opts.Position == 0 {
+		opts.Position = m.rng.Intn(opts.Teams) + 1
+	}
+
+	if len(m.rooms) >= m.config.Mock.MaxRooms {
+		return nil, newError(ErrCodeRateLimited, "too many mock drafts in progress, try again later")
+	}
+	open := 0
+	for _, room := range m.rooms {
+		if room.draft.EventID == eventID && room.draft.UserID == userID {
+			open++
+		}
+	}
+	if open >= m.config.Mock.MaxPerTeam {
+		return nil, newError(ErrCodeRateLimited, "a team can have %d mock drafts open at once; end one to start another", m.config.Mock.MaxPerTeam)
+	}
+	id, err := auth.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewMemoryStore()
+	service := NewDraftService(store, store, store, store, store, mockTeamAuth{m.teamAuth, userID}, m.serviceConfig())
+	if err := service.CreateRoom(eventID, maxRounds, players, stipulations); err != nil {
+		service.Close()
+		return nil, err
+	}
+	state := service.GetRoom()
+
+	mock := MockDraft{
+		ID:            id,
+		EventID:       eventID,
+		UserID:        userID,
+		TotalRounds:   opts.Rounds,
+		TimerDuration: int(m.config.Mock.TimerDuration.Seconds()),
+		ExpiresAt:     m.config.Clock.Now().Add(m.config.Mock.Lifetime),
+	}
+	for slot := 1; slot <= opts.Teams; slot++ {
+		if slot == opts.Position {
+			mock.PickOrder = append(mock.PickOrder, userID)
+			continue
+		}
+		bot := MockBot{
+			UserID:   -(len(mock.Bots) + 1),
+			Username: fmt.Sprintf("Bot %d", len(mock.Bots)+1),
+			Strategy: opts.BotStrategy,
+		}
+		if bot.Strategy == "" {
+			bot.Strategy = []string{StrategyBestAvailable, StrategyRosterNeed}[len(mock.Bots)%2]
+		}
+		if err := state.addBot(bot.UserID, bot.Strategy); err != nil {
+			service.Close()
+			return nil, err
+		}
+		mock.Bots = append(mock.Bots, bot)
+		mock.PickOrder = append(mock.PickOrder, bot.UserID)
+	}
+
+	if err := service.startDraft(state, mock.PickOrder, mock.TotalRounds, m.config.Mock.TimerDuration, nil); err != nil {
+		service.Close()
+		return nil, err
+	}
+
+	room := &mockRoom{draft: mock, service: service}
+	room.expiry = m.config.Clock.AfterFunc(m.config.Mock.Lifetime, func() { m.Close(id) })
+	m.rooms[id] = room
+
+	// A finished mock draft only needs to stay open long enough to look over
+	go func() {
+		select {
+		case <-state.Completed():
+			m.mu.Lock()
+			if m.rooms[id] == room {
+				room.expiry.Stop()
+				room.expiry = m.config.Clock.AfterFunc(mockKeptAfterCompletion, func() { m.Close(id) })
+			}
+			m.mu.Unlock()
+		case <-service.closed:
+		}
+	}()
+
+	log.Printf("Mock draft %s started for user %d in event %d (%d teams, %d rounds)", id, userID, eventID, opts.Teams, opts.Rounds)
+	return &mock, nil
+}
+
+// Close ends a mock draft, disconnecting its team. Returns false if there is
+// no such mock draft.
+func (m *MockDrafts) Close(id string) bool {
+	m.mu.Lock()
+	room, ok := m.rooms[id]
+	delete(m.rooms, id)
+	if ok {
+		room.expiry.Stop()
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	room.service.Close()
+	log.Printf("Mock draft %s closed", id)
+	return true
+}
+
+// HandleWebSocket connects the mock draft's team to its room. The room speaks
+// the same protocol as /ws/draft; only the team the mock draft is for may
+// connect, with a member or rejoin token.
+func (m *MockDrafts) HandleWebSocket(w http.ResponseWriter, r *http.Request, id string) {
+	m.mu.Lock()
+	room, ok := m.rooms[id]
+	m.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "mock draft not found", http.StatusNotFound)
+		return
+	}
+	room.service.HandleWebSocket(w, r)
+}
+
+// addBot makes a team a bot: on auto-pilot from the start, drafting with strategy
+func (d *DraftState) addBot(userID int, strategy string) error {
+	return d.exec(func() error {
+		if err := d.record(DraftEventStrategySet, strategySetPayload{
+			UserID:   userID,
+			Strategy: strategy,
+		}); err != nil {
+			return err
+		}
+		return d.setAutoPilot(userID, true, AutoPilotBot)
+	})
+}
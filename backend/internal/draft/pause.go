@@ -0,0 +1,211 @@
+package draft
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+)
+
+// Reasons given for pauses the server makes on its own
+const (
+	PauseReasonCommissioner      = "Paused by the commissioner"
+	PauseReasonPersistenceFailed = "Picks can't be saved"
+	PauseReasonRestored          = "Server restarted"
+)
+
+// maxPauseReasonLength limits the reason a commissioner gives for a pause, in runes
+const maxPauseReasonLength = 200
+
+// pauseCheckInterval is how often the pause monitor checks for an unattended pause
+const pauseCheckInterval = time.Second
+
+// PauseConfig controls when a paused draft resumes without the commissioner
+type PauseConfig struct {
+	// AutoResumeAfter resumes a pause that has no end time or reason once no commissioner
+	// has been connected for this long; 0 disables
+	AutoResumeAfter time.Duration
+}
+
+// DefaultPauseConfig returns the pause settings used when none are configured
+func DefaultPauseConfig() PauseConfig {
+	return PauseConfig{
+		AutoResumeAfter: 15 * time.Minute,
+	}
+}
+
+// ScheduledBreak pauses the draft for Duration seconds once round AfterRound is complete
+type ScheduledBreak struct {
+	AfterRound int    `json:"afterRound"`
+	Duration   int    `json:"duration"`         // Seconds
+	Reason     string `json:"reason,omitempty"` // Defaults to "Break after round N"
+}
+
+// reason returns the break's reason, or the default for its round
+func (b ScheduledBreak) reason() string {
+	if b.Reason != "" {
+		return b.Reason
+	}
+	return fmt.Sprintf("Break after round %d", b.AfterRound)
+}
+
+// validateBreaks checks scheduled breaks against the draft's rounds: each must
+// follow a round that has another after it, at most one per round
+func validateBreaks(breaks []ScheduledBreak, totalRounds int) error {
+	seen := make(map[int]bool, len(breaks))
+	for _, b := range breaks {
+		if b.AfterRound < 1 || b.AfterRound >= totalRounds {
+			return newError(ErrCodeRuleViolation, "breaks must be after rounds 1 to %d", totalRounds-1)
+		}
+		if seen[b.AfterRound] {
+			return newError(ErrCodeRuleViolation, "more than one break after round %d", b.AfterRound)
+		}
+		if b.Duration <= 0 {
+			return newError(ErrCodeRuleViolation, "break after round %d needs a positive duration", b.AfterRound)
+		}
+		if utf8.RuneCountInString(b.Reason) > maxPauseReasonLength {
+			return newError(ErrCodeRuleViolation, "break reasons cannot exceed %d characters", maxPauseReasonLength)
+		}
+		seen[b.AfterRound] = true
+	}
+	return nil
+}
+
+// breakAfter returns the break scheduled after round, if any
+func (p *projection) breakAfter(round int) (ScheduledBreak, bool) {
+	for _, b := range p.scheduledBreaks {
+		if b.AfterRound == round {
+			return b, true
+		}
+	}
+	return ScheduledBreak{}, false
+}
+
+// pause pauses an in-progress draft, stopping the timer and saving remaining time.
+// A positive duration resumes the draft on its own once it has passed.
+// Must be called on the room's loop
+func (d *DraftState) pause(reason string, duration time.Duration) error {
+	if d.draftStatus != StatusInProgress {
+		return newError(ErrCodeDraftNotActive, "can only pause an in-progress draft")
+	}
+
+	// Calculate remaining time before stopping timer
+	payload := draftPausedPayload{
+		RemainingTime: max(d.turnDeadline.Sub(d.clock.Now()), 0).Seconds(),
+		Reason:        reason,
+	}
+	if duration > 0 {
+		resumeAt := d.clock.Now().Add(duration)
+		payload.ResumeAt = &resumeAt
+	}
+	if err := d.record(DraftEventPaused, payload); err != nil {
+		return err
+	}
+
+	// Stop the timer
+	d.stopTimer()
+	d.scheduleResume()
+
+	d.publishPaused()
+	return nil
+}
+
+// publishPaused emits the draft paused message for the current pause
+// Must be called on the room's loop
+func (d *DraftState) publishPaused() {
+	msg := &DraftPausedMessage{
+		Envelope:      Envelope{Type: MsgTypeDraftPaused},
+		EventID:       d.eventID,
+		RemainingTime: d.remainingTime.Seconds(),
+		Reason:        d.pauseReason,
+	}
+	if !d.resumeAt.IsZero() {
+		msg.ResumeAt = d.resumeAt.Unix()
+	}
+	d.publisher.Publish(msg)
+}
+
+// scheduleResume arms the timer that ends the current pause at its resume time,
+// if it has one. Must be called on the room's loop
+func (d *DraftState) scheduleResume() {
+	d.stopResumeTimer()
+	if d.draftStatus == StatusPaused && !d.resumeAt.IsZero() {
+		id := d.resumeTimerID
+		d.resumeTimer = d.clock.AfterFunc(d.resumeAt.Sub(d.clock.Now()), func() {
+			d.do(func() { d.handleResumeTime(id) })
+		})
+	}
+}
+
+// stopResumeTimer stops the resume timer; a firing already waiting for the loop does nothing.
+// Must be called on the room's loop
+func (d *DraftState) stopResumeTimer() {
+	if d.resumeTimer != nil {
+		d.resumeTimer.Stop()
+		d.resumeTimer = nil
+	}
+	d.resumeTimerID++
+}
+
+// handleResumeTime is called when a pause's resume time arrives. id is the
+// timer that fired; the pause ending or changing since then replaced it.
+// Must be called on the room's loop
+func (d *DraftState) handleResumeTime(id int) {
+	if id != d.resumeTimerID || d.draftStatus != StatusPaused {
+		return
+	}
+
+	if err := d.resume(); err != nil {
+		log.Printf("Failed to resume draft for event %d after break: %v", d.eventID, err)
+		return
+	}
+	log.Printf("Draft resumed for event %d after break", d.eventID)
+}
+
+// ResumeUnattended resumes a pause that has no resume time, no reason the
+// commissioner gave, and isn't waiting on picks to be saved. It reports whether
+// the draft was resumed.
+func (d *DraftState) ResumeUnattended() (resumed bool) {
+	d.do(func() {
+		if d.draftStatus != StatusPaused || !d.resumeAt.IsZero() {
+			return
+		}
+		if d.pauseReason != PauseReasonCommissioner && d.pauseReason != PauseReasonRestored {
+			return
+		}
+		if _, failure := d.outbox.status(); failure != nil {
+			return
+		}
+		resumed = d.resume() == nil
+	})
+	return resumed
+}
+
+// monitorUnattendedPause resumes a paused draft once no commissioner has been
+// connected for AutoResumeAfter, so a commissioner who disconnects mid-pause
+// doesn't leave the draft paused forever. This includes pauses made over REST,
+// where the commissioner may never connect. Pauses with a resume time end on
+// their own; pauses with a reason the commissioner gave, and pauses for picks
+// that can't be saved, wait for the commissioner.
+func (s *DraftService) monitorUnattendedPause() {
+	var unattendedSince time.Time
+	every(s.clock, pauseCheckInterval, s.closed, func(now time.Time) {
+		state := s.GetRoom()
+		if state == nil || state.GetStatus() != StatusPaused || s.manager.CommissionerConnected() {
+			unattendedSince = time.Time{}
+			return
+		}
+		if unattendedSince.IsZero() {
+			unattendedSince = now
+		}
+		if now.Sub(unattendedSince) < s.pauseConfig.AutoResumeAfter {
+			return
+		}
+
+		if state.ResumeUnattended() {
+			log.Printf("Draft resumed for event %d: no commissioner connected for %s",
+				state.GetEventID(), s.pauseConfig.AutoResumeAfter)
+		}
+		unattendedSince = time.Time{}
+	})
+}
@@ -0,0 +1,456 @@
+package draft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// saveTimeout bounds a single attempt to save a pick
+const saveTimeout = 5 * time.Second
+
+// PersistenceConfig controls how failed pick saves are retried
+type PersistenceConfig struct {
+	MaxAttempts    int           // Attempts per pick before the draft is paused
+	InitialBackoff time.Duration // Wait after the first failed attempt; doubles after each
+	MaxBackoff     time.Duration // Cap on the wait between attempts
+	RetryAfter     time.Duration // Wait before trying again once the draft has been paused
+}
+
+// DefaultPersistenceConfig returns the persistence settings used when none are configured
+func DefaultPersistenceConfig() PersistenceConfig {
+	return PersistenceConfig{
+		MaxAttempts:    5,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     4 * time.Second,
+		RetryAfter:     30 * time.Second,
+	}
+}
+
+// permanent is implemented by PickSaver errors that retrying can't fix,
+// such as a different pick already stored under the same pick number
+type permanent interface {
+	Permanent() bool
+}
+
+// isPermanent reports whether a save error should not be retried
+func isPermanent(err error) bool {
+	var p permanent
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// pickRejection is implemented by PickSaver errors for picks that break a draft rule
+type pickRejection interface {
+	PickReason() string
+}
+
+// pickReason returns the rule a rejected pick broke, or "" if the save simply failed
+func pickReason(err error) string {
+	var r pickRejection
+	if errors.As(err, &r) {
+		return r.PickReason()
+	}
+	return ""
+}
+
+// outbox holds picks or log entries from the moment they're made until they're
+// saved, in order. The draft never waits on the database: it only appends.
+// When the oldest item can't be saved the outbox stops handing out items until
+// retry is called, so items are always stored in order.
+type outbox[T any] struct {
+	mu      sync.Mutex
+	pending []T
+	failure error // Why the oldest pending item couldn't be saved; nil once it is
+	held    bool  // The oldest item failed and isn't handed out until retry
+	closed  bool  // No more items will be added
+	wake    chan struct{}
+	drained chan struct{} // Closed once the outbox is closed and every item is saved
+}
+
+func newOutbox[T any]() *outbox[T] {
+	return &outbox[T]{wake: make(chan struct{}, 1), drained: make(chan struct{})}
+}
+
+// done returns a channel that's closed once the outbox is closed and empty
+func (o *outbox[T]) done() <-chan struct{} {
+	return o.drained
+}
+
+// checkDrainedLocked closes drained if the outbox has nothing left to save
+// Must be called while holding the mutex
+func (o *outbox[T]) checkDrainedLocked() {
+	if !o.closed || len(o.pending) > 0 {
+		return
+	}
+	select {
+	case <-o.drained:
+	default:
+		close(o.drained)
+	}
+}
+
+// signal wakes the persistence goroutine without blocking
+func (o *outbox[T]) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// push adds a newly made item
+func (o *outbox[T]) push(item T) {
+	o.mu.Lock()
+	o.pending = append(o.pending, item)
+	o.mu.Unlock()
+	o.signal()
+}
+
+// close marks that the draft is over; next returns false once the outbox drains
+func (o *outbox[T]) close() {
+	o.mu.Lock()
+	o.closed = true
+	o.checkDrainedLocked()
+	o.mu.Unlock()
+	o.signal()
+}
+
+// next blocks until the oldest pending item can be saved, returning false once
+// the outbox is closed and empty
+func (o *outbox[T]) next() (T, bool) {
+	for {
+		o.mu.Lock()
+		if !o.held && len(o.pending) > 0 {
+			item := o.pending[0]
+			o.mu.Unlock()
+			return item, true
+		}
+		if o.closed && len(o.pending) == 0 {
+			o.mu.Unlock()
+			var zero T
+			return zero, false
+		}
+		o.mu.Unlock()
+		<-o.wake
+	}
+}
+
+// waitRetry blocks until retry is called after a failure, returning false if
+// stop is closed first
+func (o *outbox[T]) waitRetry(stop <-chan struct{}) bool {
+	for {
+		o.mu.Lock()
+		held := o.held
+		o.mu.Unlock()
+		if !held {
+			return true
+		}
+		select {
+		case <-o.wake:
+		case <-stop:
+			return false
+		}
+	}
+}
+
+// saved removes the oldest pending item once it's stored, clearing any failure
+func (o *outbox[T]) saved() {
+	o.mu.Lock()
+	o.pending = o.pending[1:]
+	o.failure = nil
+	o.checkDrainedLocked()
+	o.mu.Unlock()
+}
+
+// failed records that the oldest pending item couldn't be saved, holding the
+// outbox until retry
+func (o *outbox[T]) failed(err error) {
+	o.mu.Lock()
+	o.failure = err
+	o.held = true
+	o.mu.Unlock()
+}
+
+// retry lets the persistence goroutine try the oldest pending item again.
+// The failure is still reported until the item is saved.
+func (o *outbox[T]) retry() {
+	o.mu.Lock()
+	held := o.held
+	o.held = false
+	o.mu.Unlock()
+	if held {
+		o.signal()
+	}
+}
+
+// status returns the items not yet saved, oldest first, and the current failure
+func (o *outbox[T]) status() ([]T, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]T(nil), o.pending...), o.failure
+}
+
+// startPickPersistence saves the room's picks in order as they're made. Each pick
+// is retried with backoff; if it still can't be saved, the draft is paused and
+// commissioners are alerted, and the pick is tried again when the draft resumes
+// or after RetryAfter. Saves are idempotent on (event_id, pick_number), so a
+// pick whose save timed out after reaching the database is safe to retry.
+func (s *DraftService) startPickPersistence(state *DraftState) {
+	picks := state.outbox
+	recovering := false // A pick failed and hasn't been saved since
+	for {
+		pick, ok := picks.next()
+		if !ok {
+			return
+		}
+
+		err := s.savePickWithRetry(pick)
+		if err == nil {
+			picks.saved()
+			log.Printf("Persisted pick: event=%d user=%d player=%d pick#=%d round=%d auto=%v",
+				pick.EventID, pick.UserID, pick.PlayerID, pick.PickNumber, pick.Round, pick.AutoDraft)
+			if recovering {
+				recovering = false
+				s.persistenceRecovered(state, pick)
+			}
+			continue
+		}
+
+		// A stopped room is never resumed, and its commissioners are gone: the
+		// pick stays in its draft log, to be saved again if the room is restored
+		if roomStopped(state) {
+			log.Printf("Giving up on pick %d for event %d, its draft room is closed: %v", pick.PickNumber, pick.EventID, err)
+			return
+		}
+
+		recovering = true
+		picks.failed(err)
+		s.persistenceFailed(state, pick, err)
+
+		// Try again when the commissioner resumes, or on our own after a while
+		retryTimer := s.clock.AfterFunc(s.persistenceConfig.RetryAfter, picks.retry)
+		retried := picks.waitRetry(state.stopped)
+		retryTimer.Stop()
+		if !retried {
+			log.Printf("Giving up on pick %d for event %d, its draft room is closed: %v", pick.PickNumber, pick.EventID, err)
+			return
+		}
+	}
+}
+
+// roomStopped reports whether the room has been stopped
+func roomStopped(state *DraftState) bool {
+	select {
+	case <-state.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// savePickWithRetry saves a pick, retrying with exponential backoff.
+// Permanent errors are returned without retrying.
+func (s *DraftService) savePickWithRetry(pick PickResult) error {
+	result := &models.DraftResult{
+		EventID:       pick.EventID,
+		UserID:        pick.UserID,
+		PlayerID:      pick.PlayerID,
+		PickNumber:    pick.PickNumber,
+		Round:         pick.Round,
+		IsAutoDraft:   pick.AutoDraft,
+		CreatedAt:     pick.PickedAt,
+		TurnStartedAt: &pick.TurnStartedAt,
+		TurnDeadline:  &pick.TurnDeadline,
+
+		AutoDraftStrategy: pick.Strategy,
+	}
+
+	return s.saveWithRetry(fmt.Sprintf("pick %d", pick.PickNumber), func(ctx context.Context) error {
+		return s.pickSaver.SavePick(ctx, result)
+	})
+}
+
+// saveWithRetry calls save until it succeeds, with exponential backoff between
+// attempts. Permanent errors are returned without retrying.
+func (s *DraftService) saveWithRetry(what string, save func(ctx context.Context) error) error {
+	backoff := s.persistenceConfig.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+		err := save(ctx)
+		cancel()
+		if err == nil || isPermanent(err) || attempt >= s.persistenceConfig.MaxAttempts {
+			return err
+		}
+
+		log.Printf("Failed to persist %s (attempt %d/%d), retrying in %s: %v",
+			what, attempt, s.persistenceConfig.MaxAttempts, backoff, err)
+		s.sleep(backoff)
+		backoff = min(backoff*2, s.persistenceConfig.MaxBackoff)
+	}
+}
+
+// sleep waits for d to pass on the service's clock
+func (s *DraftService) sleep(d time.Duration) {
+	done := make(chan struct{})
+	s.clock.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+// startLogPersistence appends the room's draft log entries to the event store in
+// order as they're recorded. An entry that can't be saved is tried again when
+// the draft resumes or after RetryAfter; the draft carries on meanwhile, since
+// the pick it records is saved, and its failures reported, separately.
+func (s *DraftService) startLogPersistence(state *DraftState) {
+	entries := state.logOutbox
+	for {
+		event, ok := entries.next()
+		if !ok {
+			return
+		}
+
+		err := s.saveWithRetry(fmt.Sprintf("draft event %d", event.Seq), func(ctx context.Context) error {
+			return s.eventStore.AppendDraftEvent(ctx, &event)
+		})
+		if err == nil {
+			entries.saved()
+			continue
+		}
+
+		if roomStopped(state) {
+			log.Printf("Giving up on draft event %d for event %d, its draft room is closed: %v", event.Seq, event.EventID, err)
+			return
+		}
+
+		log.Printf("Draft event %d for event %d could not be saved, retrying in %s: %v",
+			event.Seq, event.EventID, s.persistenceConfig.RetryAfter, err)
+		entries.failed(err)
+		retryTimer := s.clock.AfterFunc(s.persistenceConfig.RetryAfter, entries.retry)
+		retried := entries.waitRetry(state.stopped)
+		retryTimer.Stop()
+		if !retried {
+			log.Printf("Giving up on draft event %d for event %d, its draft room is closed: %v", event.Seq, event.EventID, err)
+			return
+		}
+	}
+}
+
+// persistenceFailed pauses the draft and alerts commissioners that a pick couldn't be saved
+func (s *DraftService) persistenceFailed(state *DraftState, pick PickResult, err error) {
+	log.Printf("Pick %d for event %d could not be saved, pausing draft: %v", pick.PickNumber, pick.EventID, err)
+
+	if pauseErr := state.PauseDraft(PauseReasonPersistenceFailed, 0); pauseErr == nil {
+		log.Printf("Draft paused for event %d until picks can be saved", pick.EventID)
+	}
+
+	pending, _ := state.outbox.status()
+	s.manager.SendToCommissioners(&PersistenceFailedMessage{
+		Envelope:     Envelope{Type: MsgTypePersistenceFailed},
+		EventID:      pick.EventID,
+		PickNumber:   pick.PickNumber,
+		PendingPicks: len(pending),
+		Error:        err.Error(),
+		Reason:       pickReason(err),
+	})
+}
+
+// persistenceRecovered tells commissioners that saving picks works again
+func (s *DraftService) persistenceRecovered(state *DraftState, pick PickResult) {
+	log.Printf("Pick persistence recovered for event %d at pick %d", pick.EventID, pick.PickNumber)
+
+	pending, _ := state.outbox.status()
+	s.manager.SendToCommissioners(&PersistenceRecoveredMessage{
+		Envelope:     Envelope{Type: MsgTypePersistenceRecovered},
+		EventID:      pick.EventID,
+		PickNumber:   pick.PickNumber,
+		PendingPicks: len(pending),
+	})
+}
+
+// Reconciliation compares the picks in the draft room with those stored in the database
+type Reconciliation struct {
+	EventID           int                  `json:"eventID"`
+	InSync            bool                 `json:"inSync"`
+	RoomPicks         int                  `json:"roomPicks"`
+	StoredPicks       int                  `json:"storedPicks"`
+	PendingPicks      []PickResult         `json:"pendingPicks"`                // Made but not yet saved
+	PersistenceError  string               `json:"persistenceError,omitempty"`  // Why the oldest pending pick isn't saved
+	PersistenceReason string               `json:"persistenceReason,omitempty"` // Rule the oldest pending pick broke, if rejected
+	Missing           []PickResult         `json:"missing"`                     // In the room, neither stored nor pending
+	Mismatched        []PickMismatch       `json:"mismatched"`                  // Stored with different details
+	Unexpected        []models.DraftResult `json:"unexpected"`                  // Stored, but not in the room
+}
+
+// PickMismatch is a pick number stored differently from the room's pick
+type PickMismatch struct {
+	PickNumber int                `json:"pickNumber"`
+	Room       PickResult         `json:"room"`
+	Stored     models.DraftResult `json:"stored"`
+}
+
+// Reconcile compares the pick history of the event's draft room with the stored
+// picks. Load stored before calling, so picks saved in between show as stored
+// rather than missing.
+func (s *DraftService) Reconcile(eventID int, stored []models.DraftResult) (*Reconciliation, error) {
+	state, err := s.roomForEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := state.GetSnapshot().PickHistory
+	pending, failure := state.outbox.status()
+
+	result := &Reconciliation{
+		EventID:      eventID,
+		RoomPicks:    len(history),
+		StoredPicks:  len(stored),
+		PendingPicks: pending,
+		Missing:      []PickResult{},
+		Mismatched:   []PickMismatch{},
+		Unexpected:   []models.DraftResult{},
+	}
+	if failure != nil {
+		result.PersistenceError = failure.Error()
+		result.PersistenceReason = pickReason(failure)
+	}
+
+	storedByNumber := make(map[int]models.DraftResult, len(stored))
+	for _, pick := range stored {
+		storedByNumber[pick.PickNumber] = pick
+	}
+	pendingNumbers := make(map[int]bool, len(pending))
+	for _, pick := range pending {
+		pendingNumbers[pick.PickNumber] = true
+	}
+
+	for _, pick := range history {
+		storedPick, ok := storedByNumber[pick.PickNumber]
+		delete(storedByNumber, pick.PickNumber)
+		switch {
+		case !ok && !pendingNumbers[pick.PickNumber]:
+			result.Missing = append(result.Missing, pick)
+		case !ok:
+			// Still in the outbox
+		case storedPick.UserID != pick.UserID || storedPick.PlayerID != pick.PlayerID ||
+			storedPick.Round != pick.Round || storedPick.IsAutoDraft != pick.AutoDraft ||
+			storedPick.AutoDraftStrategy != pick.Strategy:
+			result.Mismatched = append(result.Mismatched, PickMismatch{
+				PickNumber: pick.PickNumber,
+				Room:       pick,
+				Stored:     storedPick,
+			})
+		}
+	}
+	for _, pick := range stored {
+		if _, ok := storedByNumber[pick.PickNumber]; ok {
+			result.Unexpected = append(result.Unexpected, pick)
+		}
+	}
+
+	result.InSync = len(result.Missing) == 0 && len(result.Mismatched) == 0 &&
+		len(result.Unexpected) == 0 && failure == nil
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package draft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// gatedPickSaver saves picks to a MemoryStore, failing them or holding them
+// back when told to
+type gatedPickSaver struct {
+	store    *MemoryStore
+	attempts atomic.Int32
+
+	mu   sync.Mutex
+	fail bool
+	gate chan struct{} // When set, saves wait for it to be closed
+}
+
+func (g *gatedPickSaver) SavePick(ctx context.Context, pick *models.DraftResult) error {
+	g.attempts.Add(1)
+	g.mu.Lock()
+	fail, gate := g.fail, g.gate
+	g.mu.Unlock()
+	if fail {
+		return errors.New("database unavailable")
+	}
+	if gate != nil {
+		<-gate
+	}
+	return g.store.SavePick(ctx, pick)
+}
+
+// set changes how later saves behave
+func (g *gatedPickSaver) set(fail bool, gate chan struct{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.fail, g.gate = fail, gate
+}
+
+// newGatedSimulation is a simulation whose picks are saved through a
+// gatedPickSaver, with one attempt per save and retries only on resume
+func newGatedSimulation(t *testing.T) (*simulation, *gatedPickSaver) {
+	s := newSimulation(t, 1, 20, nil)
+	saver := &gatedPickSaver{store: s.store}
+	s.service.pickSaver = saver
+	s.service.persistenceConfig = PersistenceConfig{MaxAttempts: 1, RetryAfter: time.Hour}
+	return s, saver
+}
+
+func TestFailedPickPausesUntilSaved(t *testing.T) {
+	s, saver := newGatedSimulation(t)
+	commissioner := s.connect(0, 0)
+	for userID := 1; userID <= 3; userID++ {
+		s.connect(userID, 0)
+	}
+	s.mustSend(commissioner, StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2, 3}, TotalRounds: 1, TimerDuration: 60})
+
+	saver.set(true, nil)
+	s.pickBest()
+	s.waitFor("the draft to pause", func() bool { return s.snapshot().Status == StatusPaused })
+	if reason := s.snapshot().PauseReason; reason != PauseReasonPersistenceFailed {
+		t.Fatalf("paused because %q", reason)
+	}
+	reconciliation, err := s.service.Reconcile(simEventID, s.store.Picks())
+	if err != nil || reconciliation.InSync || reconciliation.PersistenceError == "" || len(reconciliation.PendingPicks) != 1 {
+		t.Fatalf("reconciliation %+v, %v; want the failed pick pending", reconciliation, err)
+	}
+
+	// Resuming retries the pick; the failure stands until it's saved
+	gate := make(chan struct{})
+	saver.set(false, gate)
+	s.mustSend(commissioner, Envelope{Type: MsgTypeResumeDraft})
+	s.waitFor("the retry", func() bool { return saver.attempts.Load() == 2 })
+	if reconciliation, _ := s.service.Reconcile(simEventID, s.store.Picks()); reconciliation.PersistenceError == "" {
+		t.Fatal("failure cleared before the pick was saved")
+	}
+	close(gate)
+	s.waitFor("the pick to be saved", func() bool {
+		reconciliation, _ := s.service.Reconcile(simEventID, s.store.Picks())
+		return reconciliation.InSync
+	})
+
+	s.pickBest()
+	s.pickBest()
+	s.checkCompleted([]int{1, 2, 3}, 1)
+}
+
+func TestEventCompletedOnlyOncePicksAreSaved(t *testing.T) {
+	s, saver := newGatedSimulation(t)
+	commissioner := s.connect(0, 0)
+	for userID := 1; userID <= 3; userID++ {
+		s.connect(userID, 0)
+	}
+	s.mustSend(commissioner, StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2, 3}, TotalRounds: 1, TimerDuration: 60})
+
+	gate := make(chan struct{})
+	saver.set(false, gate)
+	for range 3 {
+		s.pickBest()
+	}
+	if status := s.snapshot().Status; status != StatusCompleted {
+		t.Fatalf("status %s, want completed", status)
+	}
+
+	// Give the completion handler the chance to run early
+	time.Sleep(20 * time.Millisecond)
+	if statuses := s.store.Statuses(); len(statuses) != 1 {
+		t.Fatalf("event statuses %v while picks are unsaved, want only in_progress", statuses)
+	}
+
+	close(gate)
+	s.checkCompleted([]int{1, 2, 3}, 1)
+}
+
+func TestStoppedRoomGivesUpOnAFailingPick(t *testing.T) {
+	s, saver := newGatedSimulation(t)
+	commissioner := s.connect(0, 0)
+	for userID := 1; userID <= 3; userID++ {
+		s.connect(userID, 0)
+	}
+	s.mustSend(commissioner, StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2, 3}, TotalRounds: 1, TimerDuration: 60})
+
+	saver.set(true, nil)
+	s.pickBest()
+	s.waitFor("the draft to pause", func() bool { return s.snapshot().Status == StatusPaused })
+	s.clock.Advance(s.service.persistenceConfig.RetryAfter)
+	s.waitFor("the pick to be retried", func() bool { return saver.attempts.Load() == 2 })
+
+	// A new room for the event replaces the one whose pick keeps failing
+	if err := s.service.CreateRoom(simEventID, simMaxRounds, s.pool, nil); err != nil {
+		t.Fatal(err)
+	}
+	for len(commissioner.Send) > 0 {
+		<-commissioner.Send
+	}
+	for range 3 {
+		s.clock.Advance(s.service.persistenceConfig.RetryAfter)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if attempts := saver.attempts.Load(); attempts != 2 {
+		t.Fatalf("the stopped room's pick was retried %d more times", attempts-2)
+	}
+	for len(commissioner.Send) > 0 {
+		var msg Envelope
+		json.Unmarshal(<-commissioner.Send, &msg)
+		if msg.Type == MsgTypePersistenceFailed {
+			t.Fatal("the new room's commissioner was alerted about the stopped room's pick")
+		}
+	}
+}
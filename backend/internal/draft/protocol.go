@@ -0,0 +1,200 @@
+package draft
+
+import (
+	"encoding/json"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// Protocol versions supported by the server.
+// Version 2 adds the hello message, sequence numbers and resync.
+const (
+	ProtocolVersion    = 2
+	MinProtocolVersion = 1
+)
+
+// Subprotocol is the WebSocket subprotocol the server accepts. Browsers offer
+// it alongside "bearer.<rejoin token>", and fail the connection if the server
+// picks none of the protocols offered.
+const Subprotocol = "draft"
+
+// replayLogSize is how many broadcasts the Manager keeps for resync
+const replayLogSize = 500
+
+// ServerMessage is implemented by every message sent from server to client
+type ServerMessage interface {
+	setSeq(seq uint64)
+}
+
+// Envelope holds the fields common to every server message.
+// Seq is assigned to broadcasts only; messages sent to a single client have none.
+type Envelope struct {
+	Type string `json:"type"`
+	Seq  uint64 `json:"seq,omitempty"`
+}
+
+func (e *Envelope) setSeq(seq uint64) {
+	e.Seq = seq
+}
+
+// encodeMessage serializes a server message for sending
+func encodeMessage(msg ServerMessage) []byte {
+	data, _ := json.Marshal(msg)
+	return data
+}
+
+// HelloMessage is the first message on every connection, confirming the negotiated protocol
+type HelloMessage struct {
+	Envelope
+	ProtocolVersion int    `json:"protocolVersion"`
+	LastSeq         uint64 `json:"lastSeq"` // Sequence number of the latest broadcast
+}
+
+// DraftStartedMessage is broadcast when a draft begins
+type DraftStartedMessage struct {
+	Envelope
+	EventID          int   `json:"eventID"`
+	CurrentTurn      int   `json:"currentTurn"`
+	RoundNumber      int   `json:"roundNumber"`
+	TurnDeadline     int64 `json:"turnDeadline"`
+	PickOrder        []int `json:"pickOrder"`
+	TotalRounds      int   `json:"totalRounds"`
+	AvailablePlayers []int `json:"availablePlayers"`
+
+	ScheduledBreaks []ScheduledBreak `json:"scheduledBreaks,omitempty"`
+}
+
+// PickMadeMessage is broadcast when a pick is made (manually or via auto-draft)
+type PickMadeMessage struct {
+	Envelope
+	UserID     int  `json:"userID"`
+	PlayerID   int  `json:"playerID"`
+	PickNumber int  `json:"pickNumber"`
+	Round      int  `json:"round"`
+	AutoDraft  bool `json:"autoDraft"`
+
+	Strategy string `json:"strategy,omitempty"` // The auto-draft strategy that chose the player
+}
+
+// TurnChangedMessage is broadcast when the turn advances to the next user
+type TurnChangedMessage struct {
+	Envelope
+	CurrentTurn  int   `json:"currentTurn"`
+	RoundNumber  int   `json:"roundNumber"`
+	TurnDeadline int64 `json:"turnDeadline"`
+}
+
+// DraftCompletedMessage is broadcast when the final pick is made
+type DraftCompletedMessage struct {
+	Envelope
+	EventID     int `json:"eventID"`
+	TotalPicks  int `json:"totalPicks"`
+	TotalRounds int `json:"totalRounds"`
+}
+
+// DraftPausedMessage is broadcast when a draft is paused
+type DraftPausedMessage struct {
+	Envelope
+	EventID       int     `json:"eventID"`
+	RemainingTime float64 `json:"remainingTime"`
+	Reason        string  `json:"reason"`
+	ResumeAt      int64   `json:"resumeAt,omitempty"` // Unix time the draft resumes on its own, if it does
+}
+
+// DraftResumedMessage is broadcast when a paused draft resumes
+type DraftResumedMessage struct {
+	Envelope
+	EventID      int   `json:"eventID"`
+	CurrentTurn  int   `json:"currentTurn"`
+	RoundNumber  int   `json:"roundNumber"`
+	TurnDeadline int64 `json:"turnDeadline"`
+}
+
+// DraftStateMessage is the full snapshot sent to connecting or resyncing clients
+type DraftStateMessage struct {
+	Envelope
+	DraftSnapshot
+	ConnectedUserIDs []int                    `json:"connectedUserIDs"`
+	ConnectedMembers map[int][]MemberPresence `json:"connectedMembers"`
+	IdleUserIDs      []int                    `json:"idleUserIDs"`
+	RecentChat       []models.ChatMessage     `json:"recentChat"`
+	Queue            []int                    `json:"queue,omitempty"`    // The receiving team's preference queue
+	Strategy         string                   `json:"strategy,omitempty"` // The receiving team's auto-draft strategy, if it chose one
+}
+
+// UserJoinedMessage reports a team member connecting
+type UserJoinedMessage struct {
+	Envelope
+	UserID   int              `json:"userID"`
+	Username string           `json:"username"`
+	Members  []MemberPresence `json:"members"`
+}
+
+// UserLeftMessage reports a team member's last connection closing
+type UserLeftMessage struct {
+	Envelope
+	UserID   int              `json:"userID"`
+	MemberID int              `json:"memberID"`
+	Members  []MemberPresence `json:"members"`
+}
+
+// PresenceChangedMessage is broadcast when a team on the clock goes idle or becomes
+// active again, and when a team goes on or comes off auto-pilot
+type PresenceChangedMessage struct {
+	Envelope
+	UserID    int    `json:"userID"`
+	Status    string `json:"status"` // PresenceIdle or PresenceActive
+	AutoPilot bool   `json:"autoPilot"`
+}
+
+// QueueUpdatedMessage is sent to a team's connections when its preference queue changes
+type QueueUpdatedMessage struct {
+	Envelope
+	UserID    int   `json:"userID"`
+	PlayerIDs []int `json:"playerIDs"`
+}
+
+// ChatPostedMessage is broadcast when a chat message is sent
+type ChatPostedMessage struct {
+	Envelope
+	Message *models.ChatMessage `json:"message"`
+}
+
+// ChatMessageDeletedMessage is broadcast when the commissioner deletes a chat message
+type ChatMessageDeletedMessage struct {
+	Envelope
+	MessageID int `json:"messageID"`
+}
+
+// PersistenceFailedMessage is sent to commissioners when a pick couldn't be saved
+// after retrying; the draft is paused until it can be
+type PersistenceFailedMessage struct {
+	Envelope
+	EventID      int    `json:"eventID"`
+	PickNumber   int    `json:"pickNumber"`   // The oldest pick not yet saved
+	PendingPicks int    `json:"pendingPicks"` // Picks made but not yet saved
+	Error        string `json:"error"`
+	Reason       string `json:"reason,omitempty"` // Code of the rule the database says the pick broke
+}
+
+// PersistenceRecoveredMessage is sent to commissioners when picks are being saved again
+type PersistenceRecoveredMessage struct {
+	Envelope
+	EventID      int `json:"eventID"`
+	PickNumber   int `json:"pickNumber"` // The pick that failed before and is now saved
+	PendingPicks int `json:"pendingPicks"`
+}
+
+// ErrorMessage is sent to a single client when its request fails
+type ErrorMessage struct {
+	Envelope
+	RequestID string    `json:"requestID,omitempty"` // Echoed from the failed message
+	Code      ErrorCode `json:"code"`
+	Error     string    `json:"error"`
+}
+
+// AckMessage is sent to a single client when a message carrying a requestID succeeds
+type AckMessage struct {
+	Envelope
+	RequestID string `json:"requestID"`
+}
@@ -0,0 +1,116 @@
+package draft
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// BackpressurePolicy decides what happens when a client's send queue is full
+type BackpressurePolicy string
+
+const (
+	// PolicyDropOldest discards the oldest queued message; the client sees a
+	// seq gap and sends resync_from. Only broadcasts can be recovered that way,
+	// so if the oldest message carries no seq the client is disconnected instead.
+	PolicyDropOldest BackpressurePolicy = "drop_oldest"
+	// PolicyDisconnect closes the connection; the client reconnects and gets a
+	// snapshot (or a replay, if it reconnects with lastSeq)
+	PolicyDisconnect BackpressurePolicy = "disconnect"
+)
+
+// QueueConfig controls the per-client send queues
+type QueueConfig struct {
+	Size   int                // Messages buffered per client
+	Policy BackpressurePolicy // Applied to version 2 clients; version 1 clients can't detect gaps, so they are always disconnected
+}
+
+// DefaultQueueConfig returns the queue settings used when none are configured
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		Size:   256,
+		Policy: PolicyDropOldest,
+	}
+}
+
+// queueMetrics counts send queue activity across all clients
+type queueMetrics struct {
+	sent        atomic.Uint64
+	dropped     atomic.Uint64
+	disconnects atomic.Uint64
+}
+
+// QueueStats is a point-in-time view of the send queues, served by HandleMetrics
+type QueueStats struct {
+	Clients               int    `json:"clients"`
+	QueueSize             int    `json:"queueSize"`
+	TotalDepth            int    `json:"totalDepth"` // Messages waiting across all clients
+	MaxDepth              int    `json:"maxDepth"`   // Fullest single queue
+	MessagesSent          uint64 `json:"messagesSent"`
+	MessagesDropped       uint64 `json:"messagesDropped"`
+	SlowClientDisconnects uint64 `json:"slowClientDisconnects"`
+}
+
+// enqueue adds a message to the client's send queue without blocking.
+// When the queue is full the client's backpressure policy applies.
+// Returns false if the message was not queued because the client is closed.
+func (c *Client) enqueue(data []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.sendClosed {
+		return false
+	}
+
+	select {
+	case c.Send <- data:
+		c.metrics.sent.Add(1)
+		return true
+	default:
+	}
+
+	if c.policy == PolicyDropOldest {
+		dropped := true
+		select {
+		case oldest := <-c.Send:
+			dropped = isSequenced(oldest)
+		default: // The write pump drained it first
+		}
+		if dropped {
+			// Can't block: only enqueue adds to the channel, and it holds sendMu
+			c.Send <- data
+			c.metrics.sent.Add(1)
+			c.metrics.dropped.Add(1)
+			return true
+		}
+		// An ack, error or snapshot the client can't get back with resync_from
+	}
+
+	// Disconnect: the write pump sees the closed queue and closes the connection,
+	// which unregisters the client
+	c.overflowed = true
+	c.sendClosed = true
+	close(c.Send)
+	c.metrics.disconnects.Add(1)
+	return false
+}
+
+// isSequenced reports whether an encoded message is a broadcast, which carries a seq
+func isSequenced(data []byte) bool {
+	var envelope Envelope
+	return json.Unmarshal(data, &envelope) == nil && envelope.Seq != 0
+}
+
+// closeSend closes the client's send queue; safe to call more than once
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.sendClosed {
+		c.sendClosed = true
+		close(c.Send)
+	}
+}
+
+// queueSpace returns how many more messages fit in the client's send queue
+func (c *Client) queueSpace() int {
+	return cap(c.Send) - len(c.Send)
+}
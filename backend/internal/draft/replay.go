@@ -0,0 +1,170 @@
+package draft
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// ReplaySpeeds are the playback speeds a replay can run at
+var ReplaySpeeds = []int{1, 5, 20}
+
+// Replay is a completed draft to play back
+type Replay struct {
+	EventID   int
+	StartedAt time.Time            // When the draft started; falls back to the first pick
+	PlayerIDs []int                // The event's player pool, as available at the start
+	Picks     []models.DraftResult // In pick order
+}
+
+// replayStep is one message of a replay and how far into the original draft it was sent
+type replayStep struct {
+	at  time.Duration
+	msg ServerMessage
+}
+
+// HandleReplay upgrades to a WebSocket and plays a completed draft back to this
+// one client: draft_started, then pick_made and turn_changed for each pick as they
+// originally happened, then draft_completed, with the gaps between them divided
+// by speed. The messages are the normal draft messages, numbered from seq 1, so
+// the draft room UI can watch a replay unchanged. Turn deadlines are each pick's
+// stored deadline, scaled like the rest of the replay.
+// The replay is read-only: the connection is closed if the client sends a message.
+func (s *DraftService) HandleReplay(w http.ResponseWriter, r *http.Request, replay *Replay, speed int) {
+	protocolVersion, ok := negotiateProtocol(w, r)
+	if !ok {
+		return
+	}
+
+	opts := &websocket.AcceptOptions{}
+	if os.Getenv("ENVIRONMENT") != "production" {
+		opts.InsecureSkipVerify = true
+	}
+	conn, err := websocket.Accept(w, r, opts)
+	if err != nil {
+		log.Printf("Failed to upgrade replay connection: %v", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	log.Printf("Replay started (eventID: %d, speed: %dx)", replay.EventID, speed)
+
+	// Cancelled when the client goes away
+	ctx := conn.CloseRead(r.Context())
+
+	if protocolVersion >= 2 {
+		if err := conn.Write(ctx, websocket.MessageText, encodeMessage(&HelloMessage{
+			Envelope:        Envelope{Type: MsgTypeHello},
+			ProtocolVersion: protocolVersion,
+		})); err != nil {
+			return
+		}
+	}
+
+	start := time.Now()
+	steps := replaySteps(replay, start, speed)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for i, step := range steps {
+		timer.Reset(time.Until(start.Add(step.at / time.Duration(speed))))
+		select {
+		case <-ctx.Done():
+			log.Printf("Replay stopped by client (eventID: %d)", replay.EventID)
+			return
+		case <-timer.C:
+		}
+
+		step.msg.setSeq(uint64(i + 1))
+		if err := conn.Write(ctx, websocket.MessageText, encodeMessage(step.msg)); err != nil {
+			log.Printf("Replay write error: %v", err)
+			return
+		}
+	}
+
+	log.Printf("Replay finished (eventID: %d)", replay.EventID)
+	conn.Close(websocket.StatusNormalClosure, "replay finished")
+}
+
+// replaySteps rebuilds the messages of a draft from its picks, timed relative to
+// its start. The pick order is the order of the first round; deadlines are the
+// original turn deadlines, as wall-clock times in a replay beginning at start.
+// Picks saved before turn deadlines were stored fall back to the time the pick
+// is sent.
+func replaySteps(replay *Replay, start time.Time, speed int) []replayStep {
+	picks := replay.Picks
+	if len(picks) == 0 {
+		return nil
+	}
+
+	startedAt := replay.StartedAt
+	if startedAt.IsZero() || startedAt.After(picks[0].CreatedAt) {
+		startedAt = picks[0].CreatedAt
+	}
+	offset := func(pick models.DraftResult) time.Duration {
+		return max(pick.CreatedAt.Sub(startedAt), 0)
+	}
+	deadline := func(pick models.DraftResult) int64 {
+		at := offset(pick)
+		if pick.TurnDeadline != nil {
+			at = max(pick.TurnDeadline.Sub(startedAt), 0)
+		}
+		return start.Add(at / time.Duration(speed)).Unix()
+	}
+
+	var pickOrder []int
+	totalRounds := 0
+	for _, pick := range picks {
+		if pick.Round == 1 {
+			pickOrder = append(pickOrder, pick.UserID)
+		}
+		totalRounds = max(totalRounds, pick.Round)
+	}
+
+	steps := make([]replayStep, 0, 2*len(picks)+1)
+	steps = append(steps, replayStep{msg: &DraftStartedMessage{
+		Envelope:         Envelope{Type: MsgTypeDraftStarted},
+		EventID:          replay.EventID,
+		CurrentTurn:      picks[0].UserID,
+		RoundNumber:      1,
+		TurnDeadline:     deadline(picks[0]),
+		PickOrder:        pickOrder,
+		TotalRounds:      totalRounds,
+		AvailablePlayers: replay.PlayerIDs,
+	}})
+
+	for i, pick := range picks {
+		at := offset(pick)
+		steps = append(steps, replayStep{at: at, msg: &PickMadeMessage{
+			Envelope:   Envelope{Type: MsgTypePickMade},
+			UserID:     pick.UserID,
+			PlayerID:   pick.PlayerID,
+			PickNumber: pick.PickNumber,
+			Round:      pick.Round,
+			AutoDraft:  pick.IsAutoDraft,
+			Strategy:   pick.AutoDraftStrategy,
+		}})
+
+		if i+1 < len(picks) {
+			next := picks[i+1]
+			steps = append(steps, replayStep{at: at, msg: &TurnChangedMessage{
+				Envelope:     Envelope{Type: MsgTypeTurnChanged},
+				CurrentTurn:  next.UserID,
+				RoundNumber:  next.Round,
+				TurnDeadline: deadline(next),
+			}})
+		}
+	}
+
+	steps = append(steps, replayStep{at: offset(picks[len(picks)-1]), msg: &DraftCompletedMessage{
+		Envelope:    Envelope{Type: MsgTypeDraftCompleted},
+		EventID:     replay.EventID,
+		TotalPicks:  len(picks),
+		TotalRounds: totalRounds,
+	}})
+
+	return steps
+}
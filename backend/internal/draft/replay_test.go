@@ -0,0 +1,48 @@
+package draft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+func TestReplayUsesStoredTurnDeadlines(t *testing.T) {
+	started := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) *time.Time {
+		when := started.Add(time.Duration(seconds) * time.Second)
+		return &when
+	}
+	replay := &Replay{
+		EventID:   1,
+		StartedAt: started,
+		Picks: []models.DraftResult{
+			{PickNumber: 1, Round: 1, UserID: 1, PlayerID: 101, CreatedAt: *at(20), TurnDeadline: at(60)},
+			{PickNumber: 2, Round: 1, UserID: 2, PlayerID: 102, CreatedAt: *at(50), TurnDeadline: at(80)},
+			{PickNumber: 3, Round: 2, UserID: 2, PlayerID: 103, CreatedAt: *at(70)}, // Saved before deadlines were stored
+		},
+	}
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	steps := replaySteps(replay, start, 5)
+
+	var deadlines []int64
+	for _, step := range steps {
+		switch msg := step.msg.(type) {
+		case *DraftStartedMessage:
+			deadlines = append(deadlines, msg.TurnDeadline)
+		case *TurnChangedMessage:
+			deadlines = append(deadlines, msg.TurnDeadline)
+		}
+	}
+
+	// Deadlines at 60s and 80s into the draft, then pick 3's arrival at 70s, all at 5x
+	want := []int64{start.Add(12 * time.Second).Unix(), start.Add(16 * time.Second).Unix(), start.Add(14 * time.Second).Unix()}
+	if len(deadlines) != len(want) {
+		t.Fatalf("deadlines %v, want %v", deadlines, want)
+	}
+	for i := range want {
+		if deadlines[i] != want[i] {
+			t.Fatalf("deadline %d is %d, want %d", i+1, deadlines[i], want[i])
+		}
+	}
+}
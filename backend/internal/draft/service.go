@@ -6,15 +6,31 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/coder/websocket"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
-// PickSaver defines the interface for persisting draft picks
+// PickSaver defines the interface for persisting draft picks.
+// SavePick must be idempotent on (event_id, pick_number): saving a pick that's
+// already stored succeeds. Errors with a Permanent() bool method returning true
+// are not retried; a PickReason() string method gives the rule the pick broke.
 type PickSaver interface {
-	SavePick(ctx context.Context, eventID, userID, playerID, pickNumber, round int, isAutoDraft bool) error
+	SavePick(ctx context.Context, pick *models.DraftResult) error
+}
+
+// EventStore defines the interface for persisting the draft log.
+// AppendDraftEvent must be idempotent on (event_id, seq): appending an entry
+// that's already stored succeeds. Errors with a Permanent() bool method
+// returning true are not retried.
+type EventStore interface {
+	AppendDraftEvent(ctx context.Context, event *models.DraftEvent) error
 }
 
 // EventUpdater defines the interface for updating event status
@@ -22,35 +38,179 @@ type EventUpdater interface {
 	UpdateStatus(ctx context.Context, eventID int, status string) error
 }
 
+// ChatStore defines the interface for persisting draft room chat
+type ChatStore interface {
+	SaveChatMessage(ctx context.Context, msg *models.ChatMessage) error
+	GetRecentChatMessages(ctx context.Context, eventID, limit int) ([]models.ChatMessage, error)
+	DeleteChatMessage(ctx context.Context, eventID, id int) error
+}
+
+// SpectatorAuthenticator resolves spectator link tokens to the event they grant access to
+type SpectatorAuthenticator interface {
+	GetEventIDBySpectatorToken(ctx context.Context, token string) (int, error)
+}
+
+// TeamAuthenticator resolves a team's rejoin token to the team it belongs to,
+// and a member token to the team member and their team
+type TeamAuthenticator interface {
+	GetUserByRejoinToken(ctx context.Context, token string) (*models.User, error)
+	GetUserByMemberToken(ctx context.Context, token string) (*models.User, *models.TeamMember, error)
+}
+
 // DraftService manages WebSocket connections and draft state
 type DraftService struct {
-	manager      *Manager
-	state        *DraftState
-	mu           sync.RWMutex // protects state
-	pickSaver    PickSaver
-	eventUpdater EventUpdater
+	manager       *Manager
+	state         *DraftState
+	mu            sync.RWMutex // protects state
+	pickSaver     PickSaver
+	eventStore    EventStore
+	eventUpdater  EventUpdater
+	chatStore     ChatStore
+	spectatorAuth SpectatorAuthenticator
+	teamAuth      TeamAuthenticator
+	closed        chan struct{} // Closed by Close; stops the monitors
+
+	ticketsMu sync.Mutex
+	tickets   map[string]streamTicket // Unredeemed SSE stream tickets
+
+	heartbeatConfig   HeartbeatConfig
+	persistenceConfig PersistenceConfig
+	pauseConfig       PauseConfig
+	autoPilotConfig   AutoPilotConfig
+	clock             Clock
+	seed              int64
+}
+
+// Config holds the tunable connection settings of a DraftService
+type Config struct {
+	Heartbeat   HeartbeatConfig
+	Queue       QueueConfig
+	Persistence PersistenceConfig
+	Pause       PauseConfig
+	AutoPilot   AutoPilotConfig
+	Mock        MockConfig
+
+	// Clock runs the rooms' timers and the monitors; nil for SystemClock. Seed seeds each
+	// room's random auto-draft picks; 0 for a different seed every room.
+	Clock Clock
+	Seed  int64
+}
+
+// DefaultConfig returns the settings used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		Heartbeat:   DefaultHeartbeatConfig(),
+		Queue:       DefaultQueueConfig(),
+		Persistence: DefaultPersistenceConfig(),
+		Pause:       DefaultPauseConfig(),
+		AutoPilot:   DefaultAutoPilotConfig(),
+		Mock:        DefaultMockConfig(),
+	}
 }
 
-// NewDraftService creates a new DraftService and starts the manager
-func NewDraftService(pickSaver PickSaver, eventUpdater EventUpdater) *DraftService {
+// NewDraftService creates a new DraftService and starts the idle and pause monitors
+func NewDraftService(pickSaver PickSaver, eventStore EventStore, eventUpdater EventUpdater, chatStore ChatStore, spectatorAuth SpectatorAuthenticator, teamAuth TeamAuthenticator, config Config) *DraftService {
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
 	s := &DraftService{
-		manager:      NewManager(),
-		pickSaver:    pickSaver,
-		eventUpdater: eventUpdater,
+		manager:           NewManager(config.Queue, config.Clock),
+		pickSaver:         pickSaver,
+		eventStore:        eventStore,
+		eventUpdater:      eventUpdater,
+		chatStore:         chatStore,
+		spectatorAuth:     spectatorAuth,
+		teamAuth:          teamAuth,
+		closed:            make(chan struct{}),
+		tickets:           make(map[string]streamTicket),
+		heartbeatConfig:   config.Heartbeat,
+		persistenceConfig: config.Persistence,
+		pauseConfig:       config.Pause,
+		autoPilotConfig:   config.AutoPilot,
+		clock:             config.Clock,
+		seed:              config.Seed,
+	}
+	if config.Heartbeat.IdleAfter > 0 {
+		s.monitorIdle()
+	}
+	if config.Pause.AutoResumeAfter > 0 {
+		s.monitorUnattendedPause()
 	}
-	go s.manager.Run()
 	return s
 }
 
-// CreateRoom creates a new draft room for the given event with available players
-func (s *DraftService) CreateRoom(eventID int, playerIDs []int) error {
+// Close disconnects every client, stops the room's timers and persistence, and
+// stops the monitors. The service can't be used afterwards.
+func (s *DraftService) Close() {
+	close(s.closed)
+	s.manager.disconnectAll()
+	if state := s.GetRoom(); state != nil {
+		state.stop()
+	}
+}
+
+// CreateRoom creates a new draft room for the given event with its max picks
+// per team, its players, best ranked first, and stipulations
+func (s *DraftService) CreateRoom(eventID, maxRounds int, players []models.Player, stipulations models.Stipulations) error {
+	if maxRounds < 1 {
+		return newError(ErrCodeRuleViolation, "event must allow at least 1 pick per team")
+	}
+	state := s.newRoom(eventID, maxRounds)
+	if err := state.SetPlayerPool(players, stipulations); err != nil {
+		state.stop()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manager.ResetLog()
+	s.replaceRoom(state)
+	return nil
+}
+
+// RestoreRoom recreates the draft room for an event from its draft log, so a
+// draft survives a server restart. See DraftState.restore.
+func (s *DraftService) RestoreRoom(eventID, maxRounds int, players []models.Player, stipulations models.Stipulations, events []models.DraftEvent) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.state = NewDraftState(eventID)
-	s.state.SetAvailablePlayers(playerIDs)
+	s.manager.ResetLog()
+	state := s.newRoom(eventID, maxRounds)
+	if err := state.SetPlayerPool(players, stipulations); err != nil {
+		state.stop()
+		return err
+	}
+	if err := state.restore(events); err != nil {
+		state.stop()
+		return err
+	}
+	s.replaceRoom(state)
+
+	if state.GetStatus() != StatusNotStarted {
+		go s.startPickPersistence(state)
+		go s.startLogPersistence(state)
+		go s.startCompletionHandler(state)
+	}
+	log.Printf("Draft room restored for event %d from %d draft events", eventID, len(events))
 	return nil
 }
 
+// replaceRoom makes state the current room, stopping the one it replaces so its
+// loop, timers and persistence don't outlive it. Clients connected for another
+// event are disconnected.
+// Must be called while holding the mutex
+func (s *DraftService) replaceRoom(state *DraftState) {
+	if s.state != nil {
+		s.state.stop()
+	}
+	s.state = state
+	s.manager.SetEvent(state.GetEventID())
+}
+
+// newRoom creates a draft room that publishes through the manager
+func (s *DraftService) newRoom(eventID, maxRounds int) *DraftState {
+	return NewDraftState(eventID, maxRounds, s.manager, s.autoPilotConfig, s.clock, newRand(s.seed, s.clock))
+}
+
 // GetRoom returns the current draft room state
 func (s *DraftService) GetRoom() *DraftState {
 	s.mu.RLock()
@@ -59,41 +219,80 @@ func (s *DraftService) GetRoom() *DraftState {
 }
 
 // Client represents a WebSocket client connection
+// UserID identifies the team; MemberID identifies which co-manager of the team is connected.
+// Spectators have no UserID and are read-only.
 type Client struct {
-	Conn     *websocket.Conn
-	Send     chan []byte // Buffered channel for outgoing messages
-	UserID   int
-	Username string
+	Conn       *websocket.Conn
+	Send       chan []byte // Bounded send queue; only written through enqueue
+	EventID    int         // Event the client joined for
+	UserID     int
+	Username   string
+	MemberID   int
+	MemberName string
+
+	ProtocolVersion int // Negotiated at connect
+
+	IsCommissioner bool        // Authenticated with ADMIN_KEY; guarded by the Manager's mutex once registered
+	IsSpectator    bool        // Connected via spectator link; receives broadcasts only
+	chatLimiter    chatLimiter // Only used from the client's read pump
+	lastActivity   time.Time   // Last message from the client; guarded by the Manager's mutex
+
+	sendMu     sync.Mutex // Serializes enqueue and close of Send
+	sendClosed bool
+	overflowed bool // Send was closed because the queue overflowed under PolicyDisconnect
+	policy     BackpressurePolicy
+	metrics    *queueMetrics
 }
 
-// SendError sends an error message to this client
-func (c *Client) SendError(message string) {
-	errMsg, _ := json.Marshal(map[string]string{
-		"type":  "error",
-		"error": message,
-	})
-	c.Send <- errMsg
+// SendError sends an error message to this client, echoing the requestID of the
+// message that failed. Errors that aren't an *Error are reported as INTERNAL_ERROR.
+func (c *Client) SendError(requestID string, err error) {
+	c.enqueue(encodeMessage(&ErrorMessage{
+		Envelope:  Envelope{Type: MsgTypeError},
+		RequestID: requestID,
+		Code:      ErrorCodeOf(err),
+		Error:     err.Error(),
+	}))
+}
+
+// SendAck confirms to this client that the message with requestID succeeded
+func (c *Client) SendAck(requestID string) {
+	c.enqueue(encodeMessage(&AckMessage{
+		Envelope:  Envelope{Type: MsgTypeAck},
+		RequestID: requestID,
+	}))
 }
 
 // HandleWebSocket upgrades HTTP connection to WebSocket and handles messages
 func (s *DraftService) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	// Parse userID from query parameter before upgrading connection
-	userIDStr := r.URL.Query().Get("userID")
-	if userIDStr == "" {
-		http.Error(w, "userID query parameter is required", http.StatusBadRequest)
+	query := r.URL.Query()
+
+	protocolVersion, ok := negotiateProtocol(w, r)
+	if !ok {
 		return
 	}
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil || userID <= 0 {
-		http.Error(w, "userID must be a positive integer", http.StatusBadRequest)
+
+	// lastSeq lets a reconnecting version 2 client resume without a full snapshot
+	var lastSeq uint64
+	if lastSeqStr := query.Get("lastSeq"); lastSeqStr != "" && protocolVersion >= 2 {
+		var err error
+		lastSeq, err = strconv.ParseUint(lastSeqStr, 10, 64)
+		if err != nil {
+			http.Error(w, "lastSeq must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Identify the client before upgrading connection
+	client := s.identifyClient(w, r)
+	if client == nil {
 		return
 	}
-	username := r.URL.Query().Get("username")
 
 	// Upgrade HTTP connection to WebSocket
 	// In production, the default origin check (Origin must match Host) is enforced.
 	// In development, InsecureSkipVerify allows all origins.
-	opts := &websocket.AcceptOptions{}
+	opts := &websocket.AcceptOptions{Subprotocols: []string{Subprotocol}}
 	if os.Getenv("ENVIRONMENT") != "production" {
 		opts.InsecureSkipVerify = true
 	}
@@ -104,26 +303,151 @@ func (s *DraftService) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("WebSocket connection established (userID: %d)", userID)
+	log.Printf("WebSocket connection established (userID: %d, spectator: %v)", client.UserID, client.IsSpectator)
+
+	// Cancelled when the read pump exits, stopping the heartbeat
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
 
-	// Create client
-	client := &Client{
-		Conn:     conn,
-		Send:     make(chan []byte, 256), // Buffered channel
-		UserID:   userID,
-		Username: username,
+	client.Conn = conn
+	client.ProtocolVersion = protocolVersion
+	s.manager.initQueue(client)
+
+	// Confirm the negotiated protocol before anything else is sent.
+	// Version 1 predates hello, so those clients don't get one.
+	if protocolVersion >= 2 {
+		client.enqueue(encodeMessage(&HelloMessage{
+			Envelope:        Envelope{Type: MsgTypeHello},
+			ProtocolVersion: protocolVersion,
+			LastSeq:         s.manager.LastSeq(),
+		}))
 	}
-	// Register client with the draft manager
-	s.manager.Register(client)
 
-	// Start write pump in separate goroutine
-	go s.writePump(r.Context(), client)
+	// Register client with the draft manager, replaying missed broadcasts if resuming
+	resumed := s.manager.Register(client, lastSeq)
+
+	// Start write pump and heartbeat in separate goroutines
+	go s.writePump(ctx, client)
+	if s.heartbeatConfig.PingInterval > 0 {
+		go s.heartbeat(ctx, client)
+	}
 
 	// Send current draft state if there's an active draft (for reconnection)
-	s.sendStateToClient(client)
+	if !resumed {
+		s.sendStateToClient(client)
+	}
 
 	// Start read pump (blocks here until connection closes)
-	s.readPump(r.Context(), client)
+	s.readPump(ctx, client)
+}
+
+// negotiateProtocol reads the protocol query parameter: clients that don't ask
+// get version 1, clients newer than the server are downgraded to ProtocolVersion.
+// Writes a 400 and returns ok=false if the version is unsupported.
+func negotiateProtocol(w http.ResponseWriter, r *http.Request) (int, bool) {
+	versionStr := r.URL.Query().Get("protocol")
+	if versionStr == "" {
+		return MinProtocolVersion, true
+	}
+	requested, err := strconv.Atoi(versionStr)
+	if err != nil || requested < MinProtocolVersion {
+		http.Error(w, "unsupported protocol version", http.StatusBadRequest)
+		return 0, false
+	}
+	return min(requested, ProtocolVersion), true
+}
+
+// identifyClient builds a Client for the team member whose token the request
+// carries (see teamCredential), or from spectatorToken for spectators. The
+// commissioner sends the admin key in an X-Admin-Key header or, over a
+// WebSocket, an authenticate message; never in the URL, which gets logged. Shared by the WebSocket and SSE
+// endpoints. Writes an HTTP error and returns nil if the client is rejected.
+func (s *DraftService) identifyClient(w http.ResponseWriter, r *http.Request) *Client {
+	query := r.URL.Query()
+
+	var client *Client
+	if spectatorToken := query.Get("spectatorToken"); spectatorToken != "" {
+		eventID, err := s.spectatorAuth.GetEventIDBySpectatorToken(r.Context(), spectatorToken)
+		if err != nil {
+			log.Printf("Spectator authentication failed: %v", err)
+			http.Error(w, "invalid spectator link", http.StatusUnauthorized)
+			return nil
+		}
+		if room := s.GetRoom(); room != nil && room.GetEventID() != eventID {
+			http.Error(w, "spectator link is not for the active draft", http.StatusForbidden)
+			return nil
+		}
+		client = &Client{
+			EventID:     eventID,
+			Username:    "Spectator",
+			IsSpectator: true,
+		}
+	} else {
+		token := teamCredential(r)
+		if token == "" {
+			http.Error(w, "rejoin token required", http.StatusUnauthorized)
+			return nil
+		}
+		user, member, err := s.authenticateTeam(r.Context(), token)
+		if err != nil {
+			log.Printf("Team authentication failed: %v", err)
+			http.Error(w, "invalid member or rejoin token", http.StatusUnauthorized)
+			return nil
+		}
+
+		// userID is optional, but must match the token when given
+		if userIDStr := query.Get("userID"); userIDStr != "" && userIDStr != strconv.Itoa(user.ID) {
+			http.Error(w, "rejoin token is for another team", http.StatusForbidden)
+			return nil
+		}
+		if room := s.GetRoom(); room != nil && room.GetEventID() != user.EventID {
+			http.Error(w, "team is not in the active draft", http.StatusForbidden)
+			return nil
+		}
+
+		client = &Client{
+			EventID:    user.EventID,
+			UserID:     user.ID,
+			Username:   user.Username,
+			MemberName: user.Username,
+
+			// Commissioners authenticate with the ADMIN_KEY environment variable
+			IsCommissioner: auth.IsAdminKey(r.Header.Get("X-Admin-Key")),
+		}
+		if member != nil {
+			client.MemberID = member.ID
+			client.MemberName = member.Name
+		}
+	}
+	return client
+}
+
+// authenticateTeam resolves a member token to the member and their team. A
+// team's rejoin token is accepted too, for clients that predate co-managers;
+// it identifies the team but no member.
+func (s *DraftService) authenticateTeam(ctx context.Context, token string) (*models.User, *models.TeamMember, error) {
+	if user, member, err := s.teamAuth.GetUserByMemberToken(ctx, token); err == nil {
+		return user, member, nil
+	}
+	user, err := s.teamAuth.GetUserByRejoinToken(ctx, token)
+	return user, nil, err
+}
+
+// teamCredential returns the member or rejoin token a team connects with, from an
+// "Authorization: Bearer" header or, for browsers, which can't set headers on
+// a WebSocket, a "bearer.<token>" subprotocol offered alongside Subprotocol
+func teamCredential(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), "bearer."); ok {
+				return token
+			}
+		}
+	}
+	return ""
 }
 
 // readPump handles incoming messages from the client
@@ -151,7 +475,11 @@ func (s *DraftService) readPump(ctx context.Context, c *Client) {
 			return
 		}
 
-		log.Printf("Received message: %s", string(data))
+		// Only the type: authenticate carries the admin key, and chat is private
+		log.Printf("Received %s message (userID: %d, memberID: %d)", messageType(data), c.UserID, c.MemberID)
+
+		// Any message counts as interaction for idle presence
+		s.manager.Touch(c, s.clock.Now())
 
 		// Handle the message
 		s.handleMessage(c, data)
@@ -167,38 +495,126 @@ func (s *DraftService) writePump(ctx context.Context, c *Client) {
 			log.Printf("Write error: %v", err)
 			return
 		}
-		log.Printf("Sent message: %s", string(msg))
+		log.Printf("Sent %s message (userID: %d, memberID: %d)", messageType(msg), c.UserID, c.MemberID)
+	}
+
+	// The queue overflowed under PolicyDisconnect; closing ends the read pump,
+	// which unregisters the client. It reconnects and resyncs.
+	if c.overflowed {
+		log.Printf("Send queue overflowed, disconnecting slow client (userID: %d, memberID: %d)", c.UserID, c.MemberID)
+		c.Conn.Close(websocket.StatusTryAgainLater, "send queue overflow")
+	}
+}
+
+// messageType returns the type of a JSON message, so it can be logged without
+// its payload
+func messageType(data []byte) string {
+	var msg struct {
+		Type string `json:"type"`
 	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+		return "invalid"
+	}
+	return msg.Type
+}
+
+// Metrics is served by HandleMetrics: the send queues, plus the process's
+// goroutines and heap so load tests can watch for leaks
+type Metrics struct {
+	QueueStats
+	Goroutines int    `json:"goroutines"`
+	HeapBytes  uint64 `json:"heapBytes"` // Allocated heap objects
+}
+
+// HandleMetrics serves send queue and runtime statistics as JSON, to the
+// commissioner only
+func (s *DraftService) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !auth.IsAdminKey(strings.TrimSpace(token)) && !auth.IsAdminKey(r.Header.Get("X-Admin-Key")) {
+		http.Error(w, `{"error": "commissioner authorization required", "code": "FORBIDDEN"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Metrics{
+		QueueStats: s.manager.QueueStats(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapBytes:  mem.HeapAlloc,
+	})
 }
 
-// handleMessage routes incoming messages to appropriate handlers
+// handleMessage routes incoming messages to appropriate handlers.
+// If the message carries a requestID, it is echoed on the ack or error that answers it.
 func (s *DraftService) handleMessage(c *Client, data []byte) {
 	// Parse message to extract type
 	var msg struct {
-		Type string `json:"type"`
+		Type      string `json:"type"`
+		RequestID string `json:"requestID"`
 	}
 	if err := json.Unmarshal(data, &msg); err != nil {
-		c.SendError("invalid JSON format")
+		c.SendError("", newError(ErrCodeInvalidMessage, "invalid JSON format"))
+		return
+	}
+
+	if err := s.routeMessage(c, msg.Type, data); err != nil {
+		c.SendError(msg.RequestID, err)
 		return
 	}
+	if msg.RequestID != "" {
+		c.SendAck(msg.RequestID)
+	}
+}
+
+// routeMessage dispatches an incoming message to its handler
+func (s *DraftService) routeMessage(c *Client, msgType string, data []byte) error {
+	if c.IsSpectator && stateChangingMessages[msgType] {
+		return newError(ErrCodeForbidden, "spectators cannot send %s", msgType)
+	}
+
+	// Anything a team sends shows someone is there to pick. Resyncs and
+	// authentication are sent by the client on its own, so they don't count.
+	if !c.IsSpectator && msgType != MsgTypeSetAutoPilot && msgType != MsgTypeResyncFrom && msgType != MsgTypeAuthenticate {
+		if state := s.GetRoom(); state != nil {
+			state.ClearAutoPilot(c.UserID)
+		}
+	}
 
 	// Route to appropriate handler based on message type
-	switch msg.Type {
+	switch msgType {
 	case MsgTypeStartDraft:
-		s.handleStartDraft(c, data)
+		return s.handleStartDraft(c, data)
 	case MsgTypeMakePick:
-		s.handleMakePick(c, data)
+		return s.handleMakePick(c, data)
 	case MsgTypePauseDraft:
-		s.handlePauseDraft(c)
+		return s.handlePauseDraft(c, data)
 	case MsgTypeResumeDraft:
-		s.handleResumeDraft(c)
+		return s.handleResumeDraft(c)
+	case MsgTypeSetAutoPilot:
+		return s.handleSetAutoPilot(c, data)
+	case MsgTypeSetQueue:
+		return s.handleSetQueue(c, data)
+	case MsgTypeSetStrategy:
+		return s.handleSetStrategy(c, data)
+	case MsgTypeChatMessage:
+		return s.handleChatMessage(c, data)
+	case MsgTypeDeleteChatMessage:
+		return s.handleDeleteChatMessage(c, data)
+	case MsgTypeResyncFrom:
+		return s.handleResyncFrom(c, data)
+	case MsgTypeAuthenticate:
+		return s.handleAuthenticate(c, data)
 	default:
-		c.SendError("unknown message type: " + msg.Type)
+		return newError(ErrCodeInvalidMessage, "unknown message type: %s", msgType)
 	}
 }
 
 // sendStateToClient sends the current draft state to a newly connected client
-// This enables reconnection - clients joining mid-draft receive the full state
+// This enables reconnection - clients joining mid-draft receive the full state,
+// and clients joining before the start still get the chat backlog
 func (s *DraftService) sendStateToClient(c *Client) {
 	s.mu.RLock()
 	state := s.state
@@ -209,25 +625,18 @@ func (s *DraftService) sendStateToClient(c *Client) {
 	}
 
 	snapshot := state.GetSnapshot()
-	if snapshot.Status == StatusNotStarted {
-		return // Draft exists but hasn't been configured/started yet
-	}
-
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":             MsgTypeDraftState,
-		"eventID":          snapshot.EventID,
-		"status":           snapshot.Status,
-		"currentTurn":      snapshot.CurrentTurn,
-		"roundNumber":      snapshot.RoundNumber,
-		"currentPickIndex": snapshot.CurrentPickIndex,
-		"totalRounds":      snapshot.TotalRounds,
-		"pickOrder":        snapshot.PickOrder,
-		"availablePlayers": snapshot.AvailablePlayers,
-		"turnDeadline":     snapshot.TurnDeadline,
-		"remainingTime":    snapshot.RemainingTime,
-		"pickHistory":      snapshot.PickHistory,
-		"connectedUserIDs": s.manager.GetConnectedUserIDs(),
-	})
-	c.Send <- msg
+	msg := &DraftStateMessage{
+		Envelope:         Envelope{Type: MsgTypeDraftState},
+		DraftSnapshot:    snapshot,
+		ConnectedUserIDs: s.manager.GetConnectedUserIDs(),
+		ConnectedMembers: s.manager.GetConnectedMembers(),
+		IdleUserIDs:      s.manager.GetIdleUserIDs(),
+		RecentChat:       s.recentChat(snapshot.EventID),
+	}
+	if !c.IsSpectator {
+		msg.Queue = state.GetQueue(c.UserID)
+		msg.Strategy = state.GetStrategy(c.UserID)
+	}
+	c.enqueue(encodeMessage(msg))
 	log.Printf("Sent draft state to reconnecting client (status: %s)", snapshot.Status)
 }
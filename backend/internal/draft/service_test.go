@@ -0,0 +1,443 @@
+package draft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+func TestTeamsConnectWithTheirTokens(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	s.store.AddTeam(models.User{ID: 1, EventID: simEventID, Username: "Team 1"}, "token-1")
+	s.store.AddTeam(models.User{ID: 2, EventID: simEventID + 1, Username: "Elsewhere"}, "token-2")
+	s.store.AddMember(models.TeamMember{ID: 11, UserID: 1, Name: "Sam"}, "member-11")
+
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+
+	tests := []struct {
+		name             string
+		query            string
+		header           http.Header
+		want             int
+		wantUser         int
+		wantMember       int
+		wantCommissioner bool
+	}{
+		{name: "no token", query: "userID=1", want: http.StatusUnauthorized},
+		{name: "unknown token", header: http.Header{"Authorization": {"Bearer nope"}}, want: http.StatusUnauthorized},
+		{name: "bearer header", header: http.Header{"Authorization": {"Bearer token-1"}}, want: http.StatusOK, wantUser: 1},
+		{name: "subprotocol", header: http.Header{"Sec-Websocket-Protocol": {"draft, bearer.token-1"}}, want: http.StatusOK, wantUser: 1},
+		{name: "member token", header: http.Header{"Authorization": {"Bearer member-11"}}, want: http.StatusOK, wantUser: 1, wantMember: 11},
+		{name: "member query ignored", query: "memberID=12&memberName=Alex", header: http.Header{"Authorization": {"Bearer member-11"}}, want: http.StatusOK, wantUser: 1, wantMember: 11},
+		{name: "admin key in the query ignored", query: "adminKey=test-admin-key", header: http.Header{"Authorization": {"Bearer token-1"}}, want: http.StatusOK, wantUser: 1},
+		{name: "admin key header", header: http.Header{"Authorization": {"Bearer token-1"}, "X-Admin-Key": {"test-admin-key"}}, want: http.StatusOK, wantUser: 1, wantCommissioner: true},
+		{name: "another team's userID", query: "userID=3", header: http.Header{"Authorization": {"Bearer token-1"}}, want: http.StatusForbidden},
+		{name: "unknown spectator link", query: "spectatorToken=nope", want: http.StatusUnauthorized},
+		{name: "team in another event", header: http.Header{"Authorization": {"Bearer token-2"}}, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws/draft?"+tt.query, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			client := s.service.identifyClient(rec, req)
+
+			if tt.want != http.StatusOK {
+				if client != nil || rec.Code != tt.want {
+					t.Fatalf("status %d, want %d", rec.Code, tt.want)
+				}
+				return
+			}
+			if client == nil || client.UserID != tt.wantUser || client.Username != "Team 1" || client.MemberID != tt.wantMember ||
+				client.IsCommissioner != tt.wantCommissioner {
+				t.Fatalf("client %+v (status %d), want user %d member %d commissioner %v", client, rec.Code, tt.wantUser, tt.wantMember, tt.wantCommissioner)
+			}
+		})
+	}
+}
+
+func TestTeamsOnlyPickForThemselves(t *testing.T) {
+	s, commissioner := startSimulation(t, 1)
+
+	s.expectError(s.send(s.clients[2], MakePickMessage{Type: MsgTypeMakePick, UserID: 1, PlayerID: 101}), ErrCodeForbidden)
+
+	// Leaving out userID picks for the sender; the commissioner may pick for anyone
+	s.mustSend(s.clients[1], MakePickMessage{Type: MsgTypeMakePick, PlayerID: 101})
+	s.mustSend(commissioner, MakePickMessage{Type: MsgTypeMakePick, UserID: 2, PlayerID: 102})
+
+	picks := s.snapshot().PickHistory
+	if len(picks) != 2 || picks[0].UserID != 1 || picks[1].UserID != 2 {
+		t.Fatalf("picks = %+v", picks)
+	}
+}
+
+func TestTeamsCannotMarkTheirPicksAutoDrafted(t *testing.T) {
+	s, _ := startSimulation(t, 1)
+
+	s.mustSend(s.clients[1], map[string]any{"type": MsgTypeMakePick, "playerID": 101, "autoDraft": true})
+	if picks := s.snapshot().PickHistory; len(picks) != 1 || picks[0].AutoDraft {
+		t.Fatalf("picks = %+v, want one pick that isn't an auto-draft", picks)
+	}
+}
+
+func TestCommissionerAuthenticatesWithAMessage(t *testing.T) {
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+	s := newSimulation(t, 1, 20, nil)
+	team := s.connect(1, 0)
+	spectator := &simClient{Client: &Client{Username: "Spectator", IsSpectator: true}}
+
+	s.expectError(s.send(team, AuthenticateMessage{Type: MsgTypeAuthenticate, AdminKey: "wrong"}), ErrCodeForbidden)
+	s.expectError(s.send(spectator, AuthenticateMessage{Type: MsgTypeAuthenticate, AdminKey: "test-admin-key"}), ErrCodeForbidden)
+	if s.service.manager.CommissionerConnected() {
+		t.Fatal("commissioner connected before authenticating")
+	}
+
+	s.mustSend(team, AuthenticateMessage{Type: MsgTypeAuthenticate, AdminKey: "test-admin-key"})
+	if !s.service.manager.CommissionerConnected() {
+		t.Fatal("commissioner not connected after authenticating")
+	}
+}
+
+// lockedBuffer is a log output that can be read while connections still log
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAdminKeyAndChatAreNotLogged(t *testing.T) {
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+	s := newSimulation(t, 1, 20, nil)
+	var logs lockedBuffer
+	log.SetOutput(&logs) // After newSimulation, which discards the log
+	s.store.AddTeam(models.User{ID: 1, EventID: simEventID, Username: "Team 1"}, "token-1")
+	server := httptest.NewServer(http.HandlerFunc(s.service.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/?protocol=2", &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": {"Bearer token-1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	conn.Write(ctx, websocket.MessageText, []byte(`{"type": "authenticate", "adminKey": "test-admin-key"}`))
+	conn.Write(ctx, websocket.MessageText, []byte(`{"type": "chat_message", "body": "secret plans", "requestID": "chat"}`))
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), `"requestID":"chat"`) {
+			break
+		}
+	}
+	// The chat was broadcast back too; wait for its write to be logged
+	time.Sleep(50 * time.Millisecond)
+
+	if out := logs.String(); strings.Contains(out, "test-admin-key") || strings.Contains(out, "secret plans") {
+		t.Fatalf("log contains a message payload:\n%s", out)
+	}
+	if out := logs.String(); !strings.Contains(out, "Received authenticate message (userID: 1") {
+		t.Fatalf("log doesn't record the authenticate message:\n%s", out)
+	}
+}
+
+func TestChatBacklogSentBeforeTheDraftStarts(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	s.store.SaveChatMessage(context.Background(), &models.ChatMessage{EventID: simEventID, UserID: 1, SenderName: "Team 1", Body: "good luck"})
+
+	c := &Client{UserID: 2, Username: "Team 2"}
+	s.service.manager.initQueue(c)
+	s.service.sendStateToClient(c)
+
+	var msg DraftStateMessage
+	if err := json.Unmarshal(<-c.Send, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != MsgTypeDraftState || msg.Status != StatusNotStarted || len(msg.RecentChat) != 1 || msg.RecentChat[0].Body != "good luck" {
+		t.Fatalf("got %+v, want a not_started draft_state with the chat backlog", msg)
+	}
+}
+
+func TestSpectatorsCannotChangeTheDraft(t *testing.T) {
+	s, _ := startSimulation(t, 2)
+	spectator := &simClient{Client: &Client{Username: "Spectator", IsSpectator: true}}
+
+	for _, msg := range []any{
+		StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2}, TotalRounds: 1},
+		MakePickMessage{Type: MsgTypeMakePick, UserID: 1, PlayerID: 101},
+		PauseDraftMessage{Type: MsgTypePauseDraft},
+		Envelope{Type: MsgTypeResumeDraft},
+		SetQueueMessage{Type: MsgTypeSetQueue, PlayerIDs: []int{101}},
+		SendChatMessage{Type: MsgTypeChatMessage, Body: "hi"},
+		DeleteChatMessage{Type: MsgTypeDeleteChatMessage, MessageID: 1},
+	} {
+		s.expectError(s.send(spectator, msg), ErrCodeForbidden)
+	}
+	if picks := s.snapshot().PickHistory; len(picks) != 0 {
+		t.Fatalf("spectator made picks %+v", picks)
+	}
+}
+
+func TestOnlyVersion2ClientsGetHelloAndResync(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	s.store.AddTeam(models.User{ID: 1, EventID: simEventID, Username: "Team 1"}, "token-1")
+	server := httptest.NewServer(http.HandlerFunc(s.service.HandleWebSocket))
+	defer server.Close()
+
+	tests := []struct {
+		query      string
+		wantHello  bool
+		wantResync string // Type of the reply to resync_from
+	}{
+		{query: "", wantHello: false, wantResync: MsgTypeError},
+		{query: "protocol=1", wantHello: false, wantResync: MsgTypeError},
+		{query: "protocol=2", wantHello: true, wantResync: MsgTypeAck},
+	}
+	for _, tt := range tests {
+		t.Run("query "+tt.query, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/?"+tt.query, &websocket.DialOptions{
+				HTTPHeader: http.Header{"Authorization": {"Bearer token-1"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.CloseNow()
+
+			read := func() (msg struct {
+				Type      string `json:"type"`
+				RequestID string `json:"requestID"`
+				Code      string `json:"code"`
+			}) {
+				t.Helper()
+				_, data, err := conn.Read(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				json.Unmarshal(data, &msg)
+				return msg
+			}
+
+			// Everything up to the snapshot: hello first, if any, then user_joined
+			first := read()
+			if gotHello := first.Type == MsgTypeHello; gotHello != tt.wantHello {
+				t.Fatalf("first message %s, want hello: %v", first.Type, tt.wantHello)
+			}
+			for msg := first; msg.Type != MsgTypeDraftState; {
+				if msg = read(); msg.Type == MsgTypeHello {
+					t.Fatal("hello after the first message")
+				}
+			}
+
+			conn.Write(ctx, websocket.MessageText, []byte(`{"type": "resync_from", "seq": 0, "requestID": "r1"}`))
+			for {
+				msg := read()
+				if msg.RequestID != "r1" {
+					continue
+				}
+				if msg.Type != tt.wantResync || (msg.Type == MsgTypeError && msg.Code != string(ErrCodeInvalidMessage)) {
+					t.Fatalf("resync_from answered with %+v, want %s", msg, tt.wantResync)
+				}
+				break
+			}
+		})
+	}
+}
+
+func TestClientsOfAnotherEventAreDisconnected(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	team := s.connect(1, 0)
+
+	// Recreating the room for the same event keeps the team connected
+	if err := s.service.CreateRoom(simEventID, simMaxRounds, s.pool, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !team.enqueue([]byte(`{}`)) {
+		t.Fatal("team disconnected when its own event's room was recreated")
+	}
+
+	if err := s.service.CreateRoom(simEventID+1, simMaxRounds, s.pool, nil); err != nil {
+		t.Fatal(err)
+	}
+	if team.enqueue([]byte(`{}`)) {
+		t.Fatal("team still connected after the room moved to another event")
+	}
+
+	// A client identified before the room changed is turned away when it registers
+	late := &Client{EventID: simEventID, UserID: 2, ProtocolVersion: ProtocolVersion}
+	s.service.manager.initQueue(late)
+	s.service.manager.Register(late, 0)
+	if late.enqueue([]byte(`{}`)) || slices.Contains(s.service.manager.GetConnectedUserIDs(), 2) {
+		t.Fatal("client of another event registered")
+	}
+}
+
+func TestOnlyTheCommissionerControlsTheDraft(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	commissioner := s.connect(0, 0)
+	team := s.connect(1, 0)
+	s.connect(2, 0)
+
+	start := StartDraftMessage{Type: MsgTypeStartDraft, PickOrder: []int{1, 2}, TotalRounds: 1, TimerDuration: 60}
+	s.expectError(s.send(team, start), ErrCodeForbidden)
+	s.mustSend(commissioner, start)
+
+	s.expectError(s.send(team, PauseDraftMessage{Type: MsgTypePauseDraft}), ErrCodeForbidden)
+	s.mustSend(commissioner, PauseDraftMessage{Type: MsgTypePauseDraft})
+
+	s.expectError(s.send(team, Envelope{Type: MsgTypeResumeDraft}), ErrCodeForbidden)
+	s.mustSend(commissioner, Envelope{Type: MsgTypeResumeDraft})
+
+	if status := s.snapshot().Status; status != StatusInProgress {
+		t.Fatalf("status %s, want in_progress", status)
+	}
+}
+
+func TestUnattendedPauseResumes(t *testing.T) {
+	s, commissioner := startSimulation(t, 1)
+	s.service.pauseConfig.AutoResumeAfter = time.Minute
+	s.service.monitorUnattendedPause()
+	s.mustSend(commissioner, PauseDraftMessage{Type: MsgTypePauseDraft})
+
+	s.clock.Advance(2 * time.Minute)
+	if status := s.snapshot().Status; status != StatusPaused {
+		t.Fatalf("%s with the commissioner connected, want paused", status)
+	}
+
+	// The monitor notices at its next check, and resumes a minute after that
+	s.disconnect(commissioner)
+	s.clock.Advance(time.Minute)
+	if status := s.snapshot().Status; status != StatusPaused {
+		t.Fatalf("%s before a minute unattended, want paused", status)
+	}
+	s.clock.Advance(time.Second)
+	if status := s.snapshot().Status; status != StatusInProgress {
+		t.Fatalf("%s after a minute unattended, want in_progress", status)
+	}
+}
+
+func TestPauseWithAReasonWaitsForTheCommissioner(t *testing.T) {
+	s, commissioner := startSimulation(t, 1)
+	s.service.pauseConfig.AutoResumeAfter = time.Minute
+	s.service.monitorUnattendedPause()
+	s.mustSend(commissioner, PauseDraftMessage{Type: MsgTypePauseDraft, Reason: "Waiting on a trade"})
+
+	s.disconnect(commissioner)
+	s.clock.Advance(time.Hour)
+	if status := s.snapshot().Status; status != StatusPaused {
+		t.Fatalf("%s after an hour unattended, want paused", status)
+	}
+}
+
+func TestTeamOnTheClockGoesIdle(t *testing.T) {
+	s, _ := startSimulation(t, 1)
+	s.service.heartbeatConfig.IdleAfter = 30 * time.Second
+	s.service.monitorIdle()
+
+	idle := func() bool {
+		s.service.manager.mu.Lock()
+		defer s.service.manager.mu.Unlock()
+		return s.service.manager.idle[1]
+	}
+	s.clock.Advance(29 * time.Second)
+	if idle() {
+		t.Fatal("team 1 idle after 29 seconds")
+	}
+	s.clock.Advance(time.Second)
+	if !idle() {
+		t.Fatal("team 1 not idle after 30 seconds on the clock")
+	}
+}
+
+func TestMetricsRequireTheAdminKey(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+
+	for bearer, want := range map[string]int{"": http.StatusUnauthorized, "wrong-key": http.StatusUnauthorized, "test-admin-key": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/draft/metrics", nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		s.service.HandleMetrics(rec, req)
+		if rec.Code != want {
+			t.Fatalf("with bearer %q: status %d, want %d", bearer, rec.Code, want)
+		}
+	}
+}
+
+func TestStreamTicketsOpenOneStream(t *testing.T) {
+	s := newSimulation(t, 1, 20, nil)
+	s.store.AddTeam(models.User{ID: 1, EventID: simEventID, Username: "Team 1"}, "token-1")
+	t.Setenv("ADMIN_KEY", "test-admin-key")
+
+	issue := func(header http.Header) StreamTicket {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/events/7/draft/stream-ticket", nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		s.service.HandleStreamTicket(rec, req, simEventID)
+		var ticket StreamTicket
+		if rec.Code != http.StatusCreated || json.NewDecoder(rec.Body).Decode(&ticket) != nil {
+			t.Fatalf("issuing a ticket: status %d: %s", rec.Code, rec.Body)
+		}
+		return ticket
+	}
+	redeem := func(ticket StreamTicket, eventID, want int) *Client {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		client := s.service.redeemStreamTicket(rec, ticket.Ticket, eventID)
+		if want == http.StatusOK && client == nil || want != http.StatusOK && (client != nil || rec.Code != want) {
+			t.Fatalf("redeeming a ticket: status %d, want %d", rec.Code, want)
+		}
+		return client
+	}
+
+	rec := httptest.NewRecorder()
+	s.service.HandleStreamTicket(rec, httptest.NewRequest(http.MethodPost, "/events/7/draft/stream-ticket", nil), simEventID)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without a token: status %d, want 401", rec.Code)
+	}
+
+	ticket := issue(http.Header{"Authorization": {"Bearer token-1"}, "X-Admin-Key": {"test-admin-key"}})
+	if client := redeem(ticket, simEventID, http.StatusOK); client.UserID != 1 || !client.IsCommissioner {
+		t.Fatalf("ticket opened a stream for %+v, want team 1 as commissioner", client)
+	}
+	redeem(ticket, simEventID, http.StatusUnauthorized)
+
+	redeem(issue(http.Header{"Authorization": {"Bearer token-1"}}), simEventID+1, http.StatusForbidden)
+
+	expired := issue(http.Header{"Authorization": {"Bearer token-1"}})
+	s.clock.Advance(streamTicketTTL)
+	redeem(expired, simEventID, http.StatusUnauthorized)
+}
@@ -0,0 +1,460 @@
+package draft
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"math/rand"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// simulation runs a draft room through a DraftService on a fake clock, backed
+// by a MemoryStore. Clients are connected the way HandleWebSocket connects
+// them, minus the socket, and send messages through the same routing. Nothing
+// happens until the test sends a message or advances the clock, so a
+// simulation with the same seed and script runs the same way every time.
+type simulation struct {
+	t       *testing.T
+	clock   *fakeClock
+	store   *MemoryStore
+	service *DraftService
+	pool    []models.Player
+	clients map[int]*simClient // Each connected team's client
+}
+
+// simClient is a connected client and what it has received
+type simClient struct {
+	*Client
+	lastSeq   uint64 // Latest broadcast received
+	synced    bool   // Resumed, or received hello; a resumed client's hello is ahead of its replay
+	picksSeen int    // pick_made broadcasts received
+}
+
+// simEventID is the event every simulation drafts
+const simEventID = 7
+
+// simMaxRounds is the simulated event's max picks per team
+const simMaxRounds = 6
+
+// newSimulation creates a draft room for simEventID with a pool of players:
+// ranked best first, every fourth an amateur and every third from outside
+// the USA. seed seeds the room's random auto-draft picks.
+func newSimulation(t *testing.T, seed int64, players int, stipulations models.Stipulations) *simulation {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	config.Seed = seed
+	config.Heartbeat.IdleAfter = 0
+	config.Pause.AutoResumeAfter = 0
+	config.Queue.Size = 4096 // Clients only read between steps
+
+	s := &simulation{
+		t:       t,
+		clock:   config.Clock.(*fakeClock),
+		store:   NewMemoryStore(),
+		clients: make(map[int]*simClient),
+	}
+	s.service = NewDraftService(s.store, s.store, s.store, s.store, s.store, s.store, config)
+	t.Cleanup(s.service.Close)
+
+	for i := 1; i <= players; i++ {
+		player := models.Player{ID: 100 + i, Status: "pro", CountryCode: "USA"}
+		if i%4 == 0 {
+			player.Status = "amateur"
+		}
+		if i%3 == 0 {
+			player.CountryCode = "ENG"
+		}
+		s.pool = append(s.pool, player)
+	}
+	if err := s.service.CreateRoom(simEventID, simMaxRounds, s.pool, stipulations); err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	return s
+}
+
+// room returns the simulation's draft room
+func (s *simulation) room() *DraftState {
+	return s.service.GetRoom()
+}
+
+// snapshot returns the room's current state
+func (s *simulation) snapshot() DraftSnapshot {
+	return s.room().GetSnapshot()
+}
+
+// connect connects a client for a team, or a commissioner if userID is 0, as
+// HandleWebSocket does. A non-zero lastSeq resumes from that broadcast.
+func (s *simulation) connect(userID int, lastSeq uint64) *simClient {
+	s.t.Helper()
+	c := &simClient{Client: &Client{
+		EventID:         simEventID,
+		UserID:          userID,
+		Username:        fmt.Sprintf("Team %d", userID),
+		MemberName:      fmt.Sprintf("Team %d", userID),
+		ProtocolVersion: ProtocolVersion,
+	}}
+	if userID == 0 {
+		c.UserID = 1000
+		c.IsCommissioner = true
+	}
+	s.service.manager.initQueue(c.Client)
+	c.enqueue(encodeMessage(&HelloMessage{
+		Envelope:        Envelope{Type: MsgTypeHello},
+		ProtocolVersion: ProtocolVersion,
+		LastSeq:         s.service.manager.LastSeq(),
+	}))
+	if s.service.manager.Register(c.Client, lastSeq) {
+		c.lastSeq = lastSeq
+		c.synced = true
+	} else {
+		s.service.sendStateToClient(c.Client)
+	}
+	if userID != 0 {
+		s.clients[userID] = c
+	}
+	s.drainClient(c)
+	return c
+}
+
+// disconnect disconnects a team's client
+func (s *simulation) disconnect(c *simClient) {
+	s.service.manager.Unregister(c.Client)
+	delete(s.clients, c.UserID)
+}
+
+// send sends a client message as c would over its connection
+func (s *simulation) send(c *simClient, msg any) error {
+	s.t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(data, &envelope)
+	return s.service.routeMessage(c.Client, envelope.Type, data)
+}
+
+// mustSend sends a client message that must succeed
+func (s *simulation) mustSend(c *simClient, msg any) {
+	s.t.Helper()
+	if err := s.send(c, msg); err != nil {
+		s.t.Fatalf("%T from user %d: %v", msg, c.UserID, err)
+	}
+}
+
+// expectError checks that err is an *Error with the given code
+func (s *simulation) expectError(err error, code ErrorCode) {
+	s.t.Helper()
+	if err == nil {
+		s.t.Fatalf("expected %s, got success", code)
+	}
+	if got := ErrorCodeOf(err); got != code {
+		s.t.Fatalf("expected %s, got %s: %v", code, got, err)
+	}
+}
+
+// drain reads every connected client's queue, checking broadcasts arrive in sequence
+func (s *simulation) drain(extra ...*simClient) {
+	s.t.Helper()
+	for _, c := range s.clients {
+		s.drainClient(c)
+	}
+	for _, c := range extra {
+		s.drainClient(c)
+	}
+}
+
+func (s *simulation) drainClient(c *simClient) {
+	s.t.Helper()
+	for {
+		select {
+		case data := <-c.Send:
+			s.receive(c, data)
+		default:
+			return
+		}
+	}
+}
+
+// receive checks one message delivered to c
+func (s *simulation) receive(c *simClient, data []byte) {
+	s.t.Helper()
+	var msg struct {
+		Type    string `json:"type"`
+		Seq     uint64 `json:"seq"`
+		LastSeq uint64 `json:"lastSeq"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		s.t.Fatalf("user %d received invalid JSON: %s", c.UserID, data)
+	}
+
+	switch {
+	case msg.Type == MsgTypeHello:
+		if !c.synced {
+			c.lastSeq = msg.LastSeq
+			c.synced = true
+		}
+	case msg.Type == MsgTypeDraftState:
+		if msg.LastSeq < c.lastSeq {
+			s.t.Fatalf("user %d got a draft_state at seq %d after seq %d", c.UserID, msg.LastSeq, c.lastSeq)
+		}
+		c.lastSeq = msg.LastSeq
+	case msg.Seq != 0:
+		if msg.Seq != c.lastSeq+1 {
+			s.t.Fatalf("user %d got %s with seq %d after seq %d", c.UserID, msg.Type, msg.Seq, c.lastSeq)
+		}
+		c.lastSeq = msg.Seq
+	}
+	if msg.Type == MsgTypePickMade {
+		c.picksSeen++
+	}
+}
+
+// pickAvailable returns a random available player
+func (s *simulation) pickAvailable(rng *rand.Rand, snapshot DraftSnapshot) int {
+	return snapshot.AvailablePlayers[rng.Intn(len(snapshot.AvailablePlayers))]
+}
+
+// snakeTurn returns the team picking at index i of a snake draft
+func snakeTurn(pickOrder []int, i int) int {
+	position := i % len(pickOrder)
+	if (i/len(pickOrder))%2 == 1 {
+		position = len(pickOrder) - 1 - position
+	}
+	return pickOrder[position]
+}
+
+// waitFor waits for persistence, which runs on its own goroutines, to catch up
+func (s *simulation) waitFor(what string, done func() bool) {
+	s.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			s.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// checkCompleted asserts the engine's invariants over a finished draft: every
+// pick in snake order, no player drafted twice, every team's roster full, and
+// the saved picks, event status and draft log all agreeing with the room
+func (s *simulation) checkCompleted(pickOrder []int, rounds int, watchers ...*simClient) {
+	s.t.Helper()
+	snapshot := s.snapshot()
+	history := snapshot.PickHistory
+
+	if snapshot.Status != StatusCompleted {
+		s.t.Fatalf("status = %s, want completed", snapshot.Status)
+	}
+	if len(history) != len(pickOrder)*rounds {
+		s.t.Fatalf("%d picks, want %d", len(history), len(pickOrder)*rounds)
+	}
+
+	inPool := make(map[int]bool, len(s.pool))
+	for _, player := range s.pool {
+		inPool[player.ID] = true
+	}
+	drafted := make(map[int]bool, len(history))
+	rosters := make(map[int]int, len(pickOrder))
+	for i, pick := range history {
+		if pick.PickNumber != i+1 || pick.Round != i/len(pickOrder)+1 {
+			s.t.Fatalf("pick %d is numbered %d in round %d", i+1, pick.PickNumber, pick.Round)
+		}
+		if want := snakeTurn(pickOrder, i); pick.UserID != want {
+			s.t.Fatalf("pick %d made by user %d, want %d", pick.PickNumber, pick.UserID, want)
+		}
+		if !inPool[pick.PlayerID] {
+			s.t.Fatalf("pick %d drafted player %d, who isn't in the event", pick.PickNumber, pick.PlayerID)
+		}
+		if drafted[pick.PlayerID] {
+			s.t.Fatalf("player %d drafted twice", pick.PlayerID)
+		}
+		if pick.AutoDraft != (pick.Strategy != "") {
+			s.t.Fatalf("pick %d: autoDraft %v with strategy %q", pick.PickNumber, pick.AutoDraft, pick.Strategy)
+		}
+		drafted[pick.PlayerID] = true
+		rosters[pick.UserID]++
+	}
+	for _, userID := range pickOrder {
+		if rosters[userID] != rounds {
+			s.t.Fatalf("user %d drafted %d players, want %d", userID, rosters[userID], rounds)
+		}
+	}
+	if len(snapshot.AvailablePlayers)+len(drafted) != len(s.pool) {
+		s.t.Fatalf("%d available and %d drafted from a pool of %d", len(snapshot.AvailablePlayers), len(drafted), len(s.pool))
+	}
+	for _, playerID := range snapshot.AvailablePlayers {
+		if drafted[playerID] {
+			s.t.Fatalf("player %d is drafted but still available", playerID)
+		}
+	}
+
+	s.waitFor("picks to be saved", func() bool { return len(s.store.Picks()) == len(history) })
+	for i, stored := range s.store.Picks() {
+		pick := history[i]
+		if stored.PickNumber != pick.PickNumber || stored.UserID != pick.UserID || stored.PlayerID != pick.PlayerID ||
+			stored.Round != pick.Round || stored.IsAutoDraft != pick.AutoDraft || stored.AutoDraftStrategy != pick.Strategy {
+			s.t.Fatalf("pick %d saved as %+v, made as %+v", pick.PickNumber, stored, pick)
+		}
+	}
+
+	s.waitFor("the event to be completed", func() bool { return len(s.store.Statuses()) == 2 })
+	if statuses := s.store.Statuses(); !slices.Equal(statuses, []string{models.EventStatusInProgress, models.EventStatusCompleted}) {
+		s.t.Fatalf("event statuses = %v", statuses)
+	}
+
+	room := s.room()
+	var seq int
+	room.do(func() { seq = room.seq })
+	s.waitFor("the draft log to be saved", func() bool { return len(s.store.DraftEvents()) == seq })
+	rebuilt, err := Rebuild(simEventID, s.store.DraftEvents())
+	if err != nil {
+		s.t.Fatalf("Rebuild: %v", err)
+	}
+	if rebuilt.Status != StatusCompleted || !reflect.DeepEqual(rebuilt.PickHistory, history) {
+		s.t.Fatalf("draft log rebuilds to %s with %d picks, room has %d", rebuilt.Status, len(rebuilt.PickHistory), len(history))
+	}
+
+	s.drain(watchers...)
+	for _, c := range watchers {
+		if c.picksSeen != len(history) {
+			s.t.Fatalf("user %d saw %d picks, want %d", c.UserID, c.picksSeen, len(history))
+		}
+	}
+}
+
+// runRandomDraft runs a 12 team, 6 round draft with actors choosing at random:
+// picks, timeouts, invalid picks, pauses and timed pauses, a scheduled break,
+// auto-pilot, queues and clients reconnecting, with and without resuming.
+// Returns the picks made.
+func runRandomDraft(t *testing.T, seed int64) []PickResult {
+	const teams, rounds = 12, 6
+	s := newSimulation(t, seed, 100, models.Stipulations{StipulationMinAmateurs: float64(1)})
+	rng := rand.New(rand.NewSource(seed))
+
+	commissioner := s.connect(0, 0)
+	pickOrder := make([]int, teams)
+	for i := range pickOrder {
+		pickOrder[i] = i + 1
+		s.connect(i+1, 0)
+	}
+	rng.Shuffle(len(pickOrder), func(i, j int) { pickOrder[i], pickOrder[j] = pickOrder[j], pickOrder[i] })
+	// Teams set strategies before the draft
+	for _, userID := range pickOrder[:4] {
+		s.mustSend(s.clients[userID], SetStrategyMessage{
+			Type:     MsgTypeSetStrategy,
+			Strategy: []string{StrategyRandom, StrategyQueue, StrategyBestAvailable, StrategyRosterNeed}[userID%4],
+		})
+	}
+
+	s.mustSend(commissioner, StartDraftMessage{
+		Type:            MsgTypeStartDraft,
+		PickOrder:       pickOrder,
+		TotalRounds:     rounds,
+		TimerDuration:   60,
+		ScheduledBreaks: []ScheduledBreak{{AfterRound: 3, Duration: 600}},
+	})
+
+	for step := 0; ; step++ {
+		snapshot := s.snapshot()
+		if snapshot.Status == StatusCompleted {
+			break
+		}
+		if step > 5000 {
+			t.Fatalf("draft didn't finish: %d picks after %d steps", len(snapshot.PickHistory), step)
+		}
+		s.randomStep(rng, commissioner, snapshot)
+		s.drain(commissioner)
+	}
+
+	s.checkCompleted(pickOrder, rounds, commissioner)
+	return s.snapshot().PickHistory
+}
+
+// randomStep takes one random action
+func (s *simulation) randomStep(rng *rand.Rand, commissioner *simClient, snapshot DraftSnapshot) {
+	t := s.t
+	t.Helper()
+	onClock := s.clients[snapshot.CurrentTurn] // nil if disconnected
+	pickNumber := snapshot.CurrentPickIndex + 1
+
+	if snapshot.Status == StatusPaused {
+		switch {
+		case snapshot.ResumeAt != 0 && rng.Intn(2) == 0:
+			s.clock.AdvanceToNext() // The break or timed pause ends
+		case onClock != nil && rng.Intn(4) == 0:
+			// Picking during a pause resumes the draft
+			s.mustSend(onClock, MakePickMessage{Type: MsgTypeMakePick, UserID: onClock.UserID, PlayerID: s.pickAvailable(rng, snapshot), PickNumber: pickNumber})
+		default:
+			s.mustSend(commissioner, map[string]string{"type": MsgTypeResumeDraft})
+		}
+		return
+	}
+
+	switch r := rng.Intn(100); {
+	case r < 4:
+		s.mustSend(commissioner, PauseDraftMessage{Type: MsgTypePauseDraft, Duration: rng.Intn(2) * 30})
+
+	case r < 10:
+		// A team drops and reconnects, resuming from where it was or from scratch
+		userID := rng.Intn(len(snapshot.PickOrder)) + 1
+		if c := s.clients[userID]; c != nil {
+			s.disconnect(c)
+			if rng.Intn(2) == 0 {
+				s.drain(commissioner)
+				s.connect(userID, c.lastSeq)
+			}
+		} else {
+			s.connect(userID, 0)
+		}
+
+	case r < 15 && len(s.clients) > 1:
+		// Sorted, so the same team tries every run; trying takes it off auto-pilot
+		for _, userID := range slices.Sorted(maps.Keys(s.clients)) {
+			if userID != snapshot.CurrentTurn {
+				s.expectError(s.send(s.clients[userID], MakePickMessage{Type: MsgTypeMakePick, UserID: userID, PlayerID: s.pickAvailable(rng, snapshot)}), ErrCodeNotYourTurn)
+				break
+			}
+		}
+
+	case r < 20 && onClock != nil && len(snapshot.PickHistory) > 0:
+		taken := snapshot.PickHistory[rng.Intn(len(snapshot.PickHistory))].PlayerID
+		s.expectError(s.send(onClock, MakePickMessage{Type: MsgTypeMakePick, UserID: onClock.UserID, PlayerID: taken}), ErrCodePlayerUnavailable)
+
+	case r < 24 && onClock != nil && pickNumber > 1:
+		// A co-manager's pick for a pick that's already been made
+		s.expectError(s.send(onClock, MakePickMessage{Type: MsgTypeMakePick, UserID: onClock.UserID, PlayerID: s.pickAvailable(rng, snapshot), PickNumber: pickNumber - 1}), ErrCodeRuleViolation)
+
+	case r < 28 && onClock != nil:
+		s.mustSend(onClock, SetAutoPilotMessage{Type: MsgTypeSetAutoPilot, Enabled: rng.Intn(2) == 0})
+
+	case r < 31 && onClock != nil:
+		queue := slices.Clone(snapshot.AvailablePlayers)
+		rng.Shuffle(len(queue), func(i, j int) { queue[i], queue[j] = queue[j], queue[i] })
+		s.mustSend(onClock, SetQueueMessage{Type: MsgTypeSetQueue, PlayerIDs: queue[:min(5, len(queue))]})
+
+	case r < 55 || onClock == nil:
+		// Nobody picks: the timer runs out, or auto-pilot picks
+		if !s.clock.AdvanceToNext() {
+			t.Fatalf("draft in progress with no timer running at pick %d", pickNumber)
+		}
+
+	default:
+		s.mustSend(onClock, MakePickMessage{Type: MsgTypeMakePick, UserID: onClock.UserID, PlayerID: s.pickAvailable(rng, snapshot), PickNumber: pickNumber})
+	}
+}
@@ -0,0 +1,205 @@
+package draft
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+)
+
+// streamTicketTTL is how long a stream ticket can be redeemed for after it's issued
+const streamTicketTTL = 30 * time.Second
+
+// streamTicket is a single-use credential for opening an SSE stream: browsers'
+// EventSource can't send an Authorization header, so the web app trades its
+// token for a ticket and puts that in the stream URL instead
+type streamTicket struct {
+	client    *Client // Who the stream is opened for; not yet registered
+	expiresAt time.Time
+}
+
+// StreamTicket is the response to a stream ticket request
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// HandleStreamTicket issues a ticket that opens the event's stream once, within
+// streamTicketTTL, for the client the request authenticates as (see identifyClient)
+func (s *DraftService) HandleStreamTicket(w http.ResponseWriter, r *http.Request, eventID int) {
+	if _, err := s.roomForEvent(eventID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	client := s.identifyClient(w, r)
+	if client == nil {
+		return
+	}
+	token, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, "failed to issue stream ticket", http.StatusInternalServerError)
+		return
+	}
+
+	now := s.clock.Now()
+	ticket := StreamTicket{Ticket: token, ExpiresAt: now.Add(streamTicketTTL)}
+	s.ticketsMu.Lock()
+	for token, issued := range s.tickets {
+		if !now.Before(issued.expiresAt) {
+			delete(s.tickets, token)
+		}
+	}
+	s.tickets[ticket.Ticket] = streamTicket{client: client, expiresAt: ticket.ExpiresAt}
+	s.ticketsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+// redeemStreamTicket returns the client a ticket was issued for, and forgets the
+// ticket. Writes an HTTP error and returns nil if it's unknown, expired or for
+// another event.
+func (s *DraftService) redeemStreamTicket(w http.ResponseWriter, token string, eventID int) *Client {
+	s.ticketsMu.Lock()
+	issued, ok := s.tickets[token]
+	delete(s.tickets, token)
+	s.ticketsMu.Unlock()
+
+	if !ok || !s.clock.Now().Before(issued.expiresAt) {
+		http.Error(w, "invalid or expired stream ticket", http.StatusUnauthorized)
+		return nil
+	}
+	if issued.client.EventID != eventID {
+		http.Error(w, "stream ticket is for another event", http.StatusForbidden)
+		return nil
+	}
+	return issued.client
+}
+
+// HandleStream serves the draft room as Server-Sent Events for clients whose
+// network blocks WebSocket upgrades. It carries the same messages as /ws/draft,
+// always at the latest protocol version, with each broadcast's seq as its event ID
+// so EventSource reconnects resume via Last-Event-ID.
+// Clients authenticate as they do on /ws/draft, or with a ticket from
+// HandleStreamTicket. Actions are sent through the REST endpoints instead of the stream.
+func (s *DraftService) HandleStream(w http.ResponseWriter, r *http.Request, eventID int) {
+	if _, err := s.roomForEvent(eventID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// EventSource sends Last-Event-ID on automatic reconnects; clients opening
+	// a fresh stream can pass lastEventID instead, since they can't set headers
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventID")
+	}
+	var lastSeq uint64
+	if lastEventID != "" {
+		var err error
+		lastSeq, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Last-Event-ID must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var client *Client
+	if ticket := r.URL.Query().Get("ticket"); ticket != "" {
+		client = s.redeemStreamTicket(w, ticket, eventID)
+	} else {
+		client = s.identifyClient(w, r)
+	}
+	if client == nil {
+		return
+	}
+	client.ProtocolVersion = ProtocolVersion
+	s.manager.initQueue(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+
+	log.Printf("SSE stream established (userID: %d, spectator: %v)", client.UserID, client.IsSpectator)
+
+	client.enqueue(encodeMessage(&HelloMessage{
+		Envelope:        Envelope{Type: MsgTypeHello},
+		ProtocolVersion: client.ProtocolVersion,
+		LastSeq:         s.manager.LastSeq(),
+	}))
+	resumed := s.manager.Register(client, lastSeq)
+	defer s.manager.Unregister(client)
+	if !resumed {
+		s.sendStateToClient(client)
+	}
+
+	s.streamEvents(w, r, flusher, client)
+	log.Println("SSE client disconnected")
+}
+
+// streamEvents writes queued messages as SSE events until the client goes away
+// or its queue is closed. Comment lines keep idle connections open through proxies
+// and surface dead clients as write errors.
+func (s *DraftService) streamEvents(w http.ResponseWriter, r *http.Request, flusher http.Flusher, c *Client) {
+	var keepAlive chan struct{} // nil if heartbeats are disabled
+	if s.heartbeatConfig.PingInterval > 0 {
+		keepAlive = make(chan struct{}, 1)
+		every(s.clock, s.heartbeatConfig.PingInterval, r.Context().Done(), func(time.Time) {
+			select {
+			case keepAlive <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case msg, ok := <-c.Send:
+			if !ok {
+				if c.overflowed {
+					log.Printf("Send queue overflowed, closing slow SSE client (userID: %d, memberID: %d)", c.UserID, c.MemberID)
+				}
+				return
+			}
+			if err := writeEvent(w, msg); err != nil {
+				log.Printf("SSE write error: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one message as an SSE event, using its seq as the event ID.
+// Unsequenced messages (hello, draft_state, error) carry no ID, so they don't
+// move the client's Last-Event-ID.
+func writeEvent(w http.ResponseWriter, msg []byte) error {
+	var envelope Envelope
+	if err := json.Unmarshal(msg, &envelope); err == nil && envelope.Seq > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", envelope.Seq); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "data: %s\n\n", msg)
+	return err
+}
@@ -2,11 +2,15 @@ package draft
 
 import (
 	"encoding/json"
-	"fmt"
+	"log"
+	"maps"
 	"math/rand"
 	"slices"
-	"sync"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
 type DraftStatus string
@@ -26,6 +30,12 @@ type PickResult struct {
 	PickNumber int  `json:"pickNumber"`
 	Round      int  `json:"round"`
 	AutoDraft  bool `json:"autoDraft"`
+
+	Strategy string `json:"strategy,omitempty"` // The auto-draft strategy that chose the player
+
+	PickedAt      time.Time `json:"-"`
+	TurnStartedAt time.Time `json:"-"` // Excludes time spent paused
+	TurnDeadline  time.Time `json:"-"`
 }
 
 // DraftSnapshot captures the current state for client synchronization
@@ -41,303 +51,507 @@ type DraftSnapshot struct {
 	TurnDeadline     int64        `json:"turnDeadline"`
 	RemainingTime    float64      `json:"remainingTime"`
 	PickHistory      []PickResult `json:"pickHistory"`
+	LastSeq          uint64       `json:"lastSeq"` // Latest broadcast reflected in this snapshot
+
+	PauseReason     string           `json:"pauseReason,omitempty"`     // Why the draft is paused
+	ResumeAt        int64            `json:"resumeAt,omitempty"`        // Unix time the pause ends on its own
+	ScheduledBreaks []ScheduledBreak `json:"scheduledBreaks,omitempty"` // Breaks set when the draft started
+
+	AutoPilotUserIDs []int `json:"autoPilotUserIDs"` // Teams whose picks are made for them
+}
+
+// Publisher delivers server messages to everyone in the room, assigning sequence numbers
+type Publisher interface {
+	Publish(msg ServerMessage)
+	LastSeq() uint64
+	PublishAutoPilot(userID int, enabled bool) // Announces a team's auto-pilot in presence
 }
 
+// DraftState is a draft room: the projection of its draft log plus the timer and
+// outboxes that act on it. Every change is made by recording a log entry and
+// applying it; the room never changes the projection directly.
+//
+// The room is an actor. One goroutine, its loop, owns the state and runs every
+// operation on it one at a time: picks, timer expirations, pauses and admin
+// commands all arrive on the same channel and happen in the order they arrive.
 type DraftState struct {
-	mu               sync.Mutex      // Protects concurrent access to state
-	eventID          int             // ID of the event for which the draft is occurring
-	currentTurnID    int             // ID of the user whose turn it currently is
-	pickTimer        *time.Timer     // Stores the timer for a pick
-	roundNumber      int             // The number of what round it is
-	draftStatus      DraftStatus     // Status of the draft
-	outgoing         chan []byte     // Outgoing messages from the draft state
-	pickResults      chan PickResult // Channel for completed picks (for persistence)
-	completed        chan struct{}   // Closed when draft completes (signals DraftService)
-	pickOrder        []int           // Order of user IDs for drafting
-	currentPickIndex int             // Current position in pickOrder
-	timerDuration    time.Duration   // How long each user has to pick
-	turnDeadline     time.Time       // When the current turn expires (for client countdown)
-	remainingTime    time.Duration   // Time remaining when paused (for resume)
-	totalRounds      int             // Total rounds in the draft (picks per team)
-	availablePlayers []int           // Player IDs available to draft
-	pickHistory      []PickResult    // All picks made in order (for reconnection sync)
-}
-
-func NewDraftState(eventID int) *DraftState {
-	return &DraftState{
-		eventID:     eventID,
-		draftStatus: StatusNotStarted,
-		outgoing:    make(chan []byte, 256),
-		pickResults: make(chan PickResult, 256),
-		completed:   make(chan struct{}),
+	projection
+	inbox   chan func()   // Operations waiting for the loop
+	stopped chan struct{} // Closed when the loop exits
+	closing bool          // Set by stop; the loop exits after the operation that set it
+
+	pickTimer     Timer                      // Makes the pick on the clock when it's due
+	pickTimerID   int                        // The armed pick timer; a firing for any other is stale
+	resumeTimer   Timer                      // Ends a pause that has a resume time
+	resumeTimerID int                        // The armed resume timer; a firing for any other is stale
+	clock         Clock                      // Tells the time and runs the timers
+	rng           *rand.Rand                 // Random auto-draft picks
+	publisher     Publisher                  // Broadcasts outgoing messages to the room
+	outbox        *outbox[PickResult]        // Picks waiting to be saved
+	logOutbox     *outbox[models.DraftEvent] // Log entries waiting to be saved
+	completed     chan struct{}              // Closed when draft completes (signals DraftService)
+
+	maxRounds       int                   // The event's max picks per team; drafts can't have more rounds
+	rankedPlayers   []int                 // The event's players, best ranked first
+	players         map[int]models.Player // The event's players by ID
+	requirements    []RosterRequirement   // The event's roster stipulations
+	eventStrategy   string                // The event's auto-draft strategy for timeouts; empty for random
+	autoPilotConfig AutoPilotConfig
+}
+
+// NewDraftState creates a draft room for an event. Its timers run on clock, and
+// random choices come from rng, so a room given a fake clock and a seeded rng
+// behaves the same every time.
+//
+// The room's loop runs until stop is called.
+func NewDraftState(eventID, maxRounds int, publisher Publisher, autoPilotConfig AutoPilotConfig, clock Clock, rng *rand.Rand) *DraftState {
+	d := &DraftState{
+		projection: projection{
+			eventID:     eventID,
+			draftStatus: StatusNotStarted,
+		},
+		inbox:           make(chan func()),
+		stopped:         make(chan struct{}),
+		publisher:       publisher,
+		clock:           clock,
+		rng:             rng,
+		autoPilotConfig: autoPilotConfig,
+		maxRounds:       maxRounds,
+		outbox:          newOutbox[PickResult](),
+		logOutbox:       newOutbox[models.DraftEvent](),
+		completed:       make(chan struct{}),
 	}
+	go d.loop()
+	return d
 }
 
-// StartDraft initializes and starts the draft with the given pick order, total rounds, timer duration, and available players
-func (d *DraftState) StartDraft(pickOrder []int, totalRounds int, timerDuration time.Duration, availablePlayers []int) error {
-	if d.draftStatus != StatusNotStarted {
-		return fmt.Errorf("draft already started")
+// loop runs the room's operations one at a time, in the order they arrive,
+// until the room is stopped
+func (d *DraftState) loop() {
+	defer close(d.stopped)
+	for !d.closing {
+		op := <-d.inbox
+		op()
 	}
-	if len(pickOrder) == 0 {
-		return fmt.Errorf("pick order cannot be empty")
+}
+
+// do runs op on the room's loop and waits for it to finish. Every read and
+// change of the room's state goes through here, so a pick and a timer expiring
+// on it never overlap: whichever arrives second sees what the first did.
+// Returns an error without running op if the room has stopped. A panic in op
+// is raised again in the caller, as it would have been without the loop.
+func (d *DraftState) do(op func()) error {
+	done := make(chan struct{})
+	var panicked any
+	run := func() {
+		defer close(done)
+		defer func() { panicked = recover() }()
+		op()
 	}
-	if len(availablePlayers) == 0 {
-		return fmt.Errorf("available players cannot be empty")
+
+	select {
+	case d.inbox <- run:
+	case <-d.stopped:
+		return newError(ErrCodeDraftNotActive, "draft room is closed")
+	}
+	<-done
+	if panicked != nil {
+		panic(panicked)
 	}
+	return nil
+}
 
-	d.pickOrder = pickOrder
-	d.totalRounds = totalRounds
-	d.timerDuration = timerDuration
-	d.availablePlayers = availablePlayers
-	d.currentPickIndex = 0
-	d.currentTurnID = pickOrder[0]
-	d.roundNumber = 1
-	d.draftStatus = StatusInProgress
-
-	// Start the pick timer (sets turnDeadline)
-	d.startTimer(d.timerDuration)
-
-	// Emit draft started message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":             MsgTypeDraftStarted,
-		"eventID":          d.eventID,
-		"currentTurn":      d.currentTurnID,
-		"roundNumber":      d.roundNumber,
-		"turnDeadline":     d.turnDeadline.Unix(),
-		"pickOrder":        d.pickOrder,
-		"totalRounds":      d.totalRounds,
-		"availablePlayers": d.availablePlayers,
-	})
-	d.outgoing <- msg
+// exec runs op on the room's loop, returning its error
+func (d *DraftState) exec(op func() error) error {
+	var err error
+	if closed := d.do(func() { err = op() }); closed != nil {
+		return closed
+	}
+	return err
+}
 
+// record appends an entry to the draft log and applies it to the room's state.
+// The entry is queued for saving; the draft never waits on the database.
+// Must be called on the room's loop
+func (d *DraftState) record(eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event := models.DraftEvent{
+		EventID:   d.eventID,
+		Seq:       d.seq + 1,
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: d.clock.Now(),
+	}
+	if err := d.apply(event); err != nil {
+		log.Printf("Rejected draft event for event %d: %v", d.eventID, err)
+		return newError(ErrCodeInternal, "draft state is inconsistent")
+	}
+	d.logOutbox.push(event)
 	return nil
 }
 
-// startTimer starts the countdown for the current pick with the given duration
-func (d *DraftState) startTimer(duration time.Duration) {
-	// Stop existing timer if any
-	if d.pickTimer != nil {
-		d.pickTimer.Stop()
-	}
+// restore applies a draft log recorded by an earlier room for the event, and
+// queues every logged pick for saving again; saves are idempotent, so this only
+// fills in picks that were lost. A draft that was in progress is paused with the
+// time its turn had left at the last logged change, since nobody has been on the
+// clock since; the commissioner resumes it.
+func (d *DraftState) restore(events []models.DraftEvent) error {
+	return d.exec(func() error {
+		if err := d.apply(events...); err != nil {
+			return err
+		}
+		if d.totalRounds > d.maxRounds {
+			return newError(ErrCodeRuleViolation, "draft log has %d rounds, more than the event's %d picks per team", d.totalRounds, d.maxRounds)
+		}
+		for _, pick := range d.pickHistory {
+			d.outbox.push(pick)
+		}
+		for _, userID := range slices.Sorted(maps.Keys(d.autoPilot)) {
+			d.publisher.PublishAutoPilot(userID, true)
+		}
+
+		switch d.draftStatus {
+		case StatusInProgress:
+			lastChange := events[len(events)-1].CreatedAt
+			if err := d.record(DraftEventPaused, draftPausedPayload{
+				RemainingTime: max(d.turnDeadline.Sub(lastChange), 0).Seconds(),
+				Reason:        PauseReasonRestored,
+			}); err != nil {
+				return err
+			}
+			d.publishPaused()
+		case StatusPaused:
+			// A break still ends when it was due to; one that's overdue ends now
+			d.scheduleResume()
+		case StatusCompleted:
+			close(d.completed)
+			d.outbox.close()
+			d.logOutbox.close()
+		}
+		return nil
+	})
+}
 
-	d.turnDeadline = time.Now().Add(duration)
-	d.pickTimer = time.NewTimer(duration)
+// StartDraft initializes and starts the draft with the given pick order, total rounds, timer duration, and available players.
+// Scheduled breaks pause the draft after the rounds they name.
+func (d *DraftState) StartDraft(pickOrder []int, totalRounds int, timerDuration time.Duration, availablePlayers []int, breaks []ScheduledBreak) error {
+	return d.exec(func() error {
+		if d.draftStatus != StatusNotStarted {
+			return newError(ErrCodeRuleViolation, "draft already started")
+		}
+		if len(pickOrder) == 0 {
+			return newError(ErrCodeRuleViolation, "pick order cannot be empty")
+		}
+		if totalRounds < 1 {
+			return newError(ErrCodeRuleViolation, "total rounds must be at least 1")
+		}
+		if totalRounds > d.maxRounds {
+			return newError(ErrCodeRuleViolation, "total rounds cannot exceed the event's %d picks per team", d.maxRounds)
+		}
+		if timerDuration <= 0 {
+			return newError(ErrCodeRuleViolation, "timer duration must be positive")
+		}
+		if len(availablePlayers) == 0 {
+			return newError(ErrCodeRuleViolation, "available players cannot be empty")
+		}
+		if err := validateBreaks(breaks, totalRounds); err != nil {
+			return err
+		}
+
+		if err := d.record(DraftEventStarted, draftStartedPayload{
+			PickOrder:        pickOrder,
+			TotalRounds:      totalRounds,
+			TimerDuration:    timerDuration.Seconds(),
+			AvailablePlayers: availablePlayers,
+			ScheduledBreaks:  breaks,
+		}); err != nil {
+			return err
+		}
+
+		// Start the pick timer
+		d.startTimer()
+
+		// Emit draft started message
+		d.publisher.Publish(&DraftStartedMessage{
+			Envelope:         Envelope{Type: MsgTypeDraftStarted},
+			EventID:          d.eventID,
+			CurrentTurn:      d.currentTurnID,
+			RoundNumber:      d.roundNumber,
+			TurnDeadline:     d.turnDeadline.Unix(),
+			PickOrder:        d.pickOrder,
+			TotalRounds:      d.totalRounds,
+			AvailablePlayers: d.availablePlayers,
+			ScheduledBreaks:  d.scheduledBreaks,
+		})
+
+		return nil
+	})
+}
 
-	// Wait for timer in a goroutine
-	go func() {
-		<-d.pickTimer.C
-		d.handleTimerExpired()
-	}()
+// startTimer runs the countdown until the current pick is due. When it fires,
+// the expiration waits its turn on the loop like any other operation.
+// Must be called on the room's loop
+func (d *DraftState) startTimer() {
+	d.stopTimer()
+	id := d.pickTimerID
+	d.pickTimer = d.clock.AfterFunc(d.pickDue().Sub(d.clock.Now()), func() {
+		d.do(func() { d.handleTimerExpired(id) })
+	})
 }
 
-// handleTimerExpired is called when the pick timer runs out - triggers auto-draft
-func (d *DraftState) handleTimerExpired() {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+// stopTimer stops the pick timer. If it already fired, the expiration waiting
+// for the loop finds the timer is no longer armed and does nothing.
+// Must be called on the room's loop
+func (d *DraftState) stopTimer() {
+	if d.pickTimer != nil {
+		d.pickTimer.Stop()
+		d.pickTimer = nil
+	}
+	d.pickTimerID++
+}
 
-	if d.draftStatus != StatusInProgress {
+// handleTimerExpired is called when the current pick is due - makes it for a team
+// on auto-pilot, or auto-drafts once the timer runs out. id is the timer that
+// fired; a pick, pause or new turn since then replaced it.
+// Must be called on the room's loop
+func (d *DraftState) handleTimerExpired(id int) {
+	if id != d.pickTimerID || d.draftStatus != StatusInProgress {
 		return
 	}
 
-	// Pick a random available player
 	if len(d.availablePlayers) == 0 {
 		return // No players left to draft
 	}
 
-	randomIndex := rand.Intn(len(d.availablePlayers))
-	playerID := d.availablePlayers[randomIndex]
+	userID := d.currentTurnID
+	if d.autoPilot[userID] {
+		d.autoDraftPick(userID, true)
+		return
+	}
+
+	if err := d.record(DraftEventTimerExpired, timerExpiredPayload{
+		PickNumber: d.currentPickIndex + 1,
+		UserID:     userID,
+	}); err != nil {
+		return
+	}
+
+	// A team that keeps running out of time isn't there; stop making everyone
+	// wait for it, starting with this pick
+	if after := d.autoPilotConfig.AfterTimeouts; after > 0 && d.missedTurns[userID] >= after {
+		if err := d.setAutoPilot(userID, true, AutoPilotTimeouts); err != nil {
+			return
+		}
+		d.autoDraftPick(userID, true)
+		return
+	}
 
-	d.recordPick(d.currentTurnID, playerID, true)
+	d.autoDraftPick(userID, false)
 }
 
-// recordPick handles the common logic for recording a pick (manual or auto-draft)
-// Must be called while holding the mutex
-func (d *DraftState) recordPick(userID, playerID int, autoDraft bool) {
-	// Remove player from available list
-	d.removePlayer(playerID)
+// autoDraftPick makes the pick on the clock for a team, with its auto-draft strategy
+// Must be called on the room's loop
+func (d *DraftState) autoDraftPick(userID int, autoPilot bool) {
+	playerID, strategy := d.chooseAutoDraft(userID, autoPilot)
+	d.recordPick(userID, playerID, true, strategy)
+}
 
-	// Create pick result (pick_number is 1-indexed)
-	pickResult := PickResult{
-		EventID:    d.eventID,
+// recordPick handles the common logic for recording a pick (manual or auto-draft).
+// strategy names the auto-draft strategy that chose the player, if one did.
+// Must be called on the room's loop
+func (d *DraftState) recordPick(userID, playerID int, autoDraft bool, strategy string) (PickResult, error) {
+	if err := d.record(DraftEventPickMade, PickResult{
 		UserID:     userID,
 		PlayerID:   playerID,
 		PickNumber: d.currentPickIndex + 1,
 		Round:      d.roundNumber,
 		AutoDraft:  autoDraft,
+		Strategy:   strategy,
+	}); err != nil {
+		return PickResult{}, err
 	}
+	pickResult := d.pickHistory[len(d.pickHistory)-1]
 
-	// Add to pick history for reconnection sync
-	d.pickHistory = append(d.pickHistory, pickResult)
+	// A pick made while paused ended the pause
+	d.scheduleResume()
 
 	// Emit pick made message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":       MsgTypePickMade,
-		"userID":     userID,
-		"playerID":   playerID,
-		"pickNumber": pickResult.PickNumber,
-		"round":      d.roundNumber,
-		"autoDraft":  autoDraft,
+	d.publisher.Publish(&PickMadeMessage{
+		Envelope:   Envelope{Type: MsgTypePickMade},
+		UserID:     userID,
+		PlayerID:   playerID,
+		PickNumber: pickResult.PickNumber,
+		Round:      pickResult.Round,
+		AutoDraft:  autoDraft,
+		Strategy:   strategy,
 	})
-	d.outgoing <- msg
 
-	// Send pick result for persistence
-	d.pickResults <- pickResult
+	// Queue the pick for saving; never blocks on the database
+	d.outbox.push(pickResult)
 
 	// Move to next turn
-	d.advanceTurn()
-}
-
-// advanceTurn moves to the next player in the pick order
-// Uses snake draft: 1→2→3→4→4→3→2→1→1→2→3→4...
-func (d *DraftState) advanceTurn() {
-	d.currentPickIndex++
-	numPlayers := len(d.pickOrder)
-	totalPicks := numPlayers * d.totalRounds
-
-	// Check if draft is complete
-	if d.currentPickIndex >= totalPicks {
+	if d.draftStatus == StatusCompleted {
 		d.completeDraft()
-		return
+		return pickResult, nil
 	}
 
-	// Check if we've completed a round
-	if d.currentPickIndex >= numPlayers*d.roundNumber {
-		d.roundNumber++
-	}
+	// Start timer for next pick
+	d.startTimer()
 
-	// Snake draft logic: odd rounds go forward, even rounds go backward
-	var positionInRound int
-	if d.roundNumber%2 == 1 {
-		// Odd round: forward (0, 1, 2, 3)
-		positionInRound = d.currentPickIndex % numPlayers
-	} else {
-		// Even round: backward (3, 2, 1, 0)
-		positionInRound = numPlayers - 1 - (d.currentPickIndex % numPlayers)
-	}
+	// Emit turn changed message
+	d.publisher.Publish(&TurnChangedMessage{
+		Envelope:     Envelope{Type: MsgTypeTurnChanged},
+		CurrentTurn:  d.currentTurnID,
+		RoundNumber:  d.roundNumber,
+		TurnDeadline: d.turnDeadline.Unix(),
+	})
 
-	d.currentTurnID = d.pickOrder[positionInRound]
+	// Take the break scheduled after the round this pick completed
+	if d.roundNumber > pickResult.Round {
+		if brk, ok := d.breakAfter(pickResult.Round); ok {
+			if err := d.pause(brk.reason(), time.Duration(brk.Duration)*time.Second); err != nil {
+				log.Printf("Failed to start break after round %d for event %d: %v", brk.AfterRound, d.eventID, err)
+			}
+		}
+	}
 
-	// Start timer for next pick
-	d.startTimer(d.timerDuration)
+	return pickResult, nil
+}
 
-	// Emit turn changed message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":         MsgTypeTurnChanged,
-		"currentTurn":  d.currentTurnID,
-		"roundNumber":  d.roundNumber,
-		"turnDeadline": d.turnDeadline.Unix(),
+// stop stops the room's timers, closes its outboxes and ends its loop, for a
+// room that's discarded; persistence exits once the outboxes drain, or when an
+// item can't be saved. Operations on a stopped room fail with DRAFT_NOT_ACTIVE.
+func (d *DraftState) stop() {
+	d.do(func() {
+		d.stopTimer()
+		d.stopResumeTimer()
+		d.outbox.close()
+		d.logOutbox.close()
+		d.closing = true
 	})
-	d.outgoing <- msg
 }
 
 // completeDraft finalizes the draft when all picks are made
+// Must be called on the room's loop
 func (d *DraftState) completeDraft() {
-	d.draftStatus = StatusCompleted
-
 	// Stop any running timer
-	if d.pickTimer != nil {
-		d.pickTimer.Stop()
-	}
+	d.stopTimer()
 
 	// Emit draft completed message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":        MsgTypeDraftCompleted,
-		"eventID":     d.eventID,
-		"totalPicks":  d.currentPickIndex,
-		"totalRounds": d.totalRounds,
+	d.publisher.Publish(&DraftCompletedMessage{
+		Envelope:    Envelope{Type: MsgTypeDraftCompleted},
+		EventID:     d.eventID,
+		TotalPicks:  d.currentPickIndex,
+		TotalRounds: d.totalRounds,
 	})
-	d.outgoing <- msg
 
-	// Signal completion to DraftService
+	// Signal completion to DraftService; persistence exits once the last pick is saved
 	close(d.completed)
+	d.outbox.close()
+	d.logOutbox.close()
 }
 
 // MakePick processes a pick from a user
-// Returns error if invalid (not your turn, player unavailable, etc.)
-func (d *DraftState) MakePick(userID, playerID int, autoDraft bool) error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+// If pickNumber is non-zero it must match the pick on the clock; the first valid
+// submission for a pick wins and later ones for the same pick are rejected.
+// Returns the recorded pick, or an *Error if invalid (not your turn, player unavailable, etc.)
+func (d *DraftState) MakePick(userID, playerID, pickNumber int) (pick PickResult, err error) {
+	if closed := d.do(func() { pick, err = d.makePick(userID, playerID, pickNumber) }); closed != nil {
+		return PickResult{}, closed
+	}
+	return pick, err
+}
 
+// makePick is MakePick on the room's loop
+func (d *DraftState) makePick(userID, playerID, pickNumber int) (PickResult, error) {
 	if d.draftStatus != StatusInProgress && d.draftStatus != StatusPaused {
-		return fmt.Errorf("draft is not active")
+		return PickResult{}, newError(ErrCodeDraftNotActive, "draft is not active")
 	}
 
-	if userID != d.currentTurnID {
-		return fmt.Errorf("not your turn")
+	if pickNumber != 0 && pickNumber != d.currentPickIndex+1 {
+		return PickResult{}, newError(ErrCodeRuleViolation, "pick %d has already been made", pickNumber)
 	}
 
-	if !d.isPlayerAvailable(playerID) {
-		return fmt.Errorf("player not available")
+	if userID != d.currentTurnID {
+		return PickResult{}, newError(ErrCodeNotYourTurn, "not your turn")
 	}
 
-	// Stop the current timer (pick was made in time)
-	if d.pickTimer != nil {
-		d.pickTimer.Stop()
+	if !d.isPlayerAvailable(playerID) {
+		return PickResult{}, newError(ErrCodePlayerUnavailable, "player not available")
 	}
 
-	// If paused, resume the draft
+	// If paused, the pick resumes the draft, unless it was paused because picks can't be saved
 	if d.draftStatus == StatusPaused {
-		d.draftStatus = StatusInProgress
+		if _, failure := d.outbox.status(); failure != nil {
+			return PickResult{}, newError(ErrCodeDraftNotActive, "draft is paused: picks are not being saved")
+		}
 	}
 
-	d.recordPick(userID, playerID, autoDraft)
+	// Stop the current timer (pick was made in time)
+	d.stopTimer()
 
-	return nil
+	// Picks sent by a team are never auto-drafts; only the timer and
+	// auto-pilot, bots included, make those (autoDraftPick)
+	return d.recordPick(userID, playerID, false, "")
 }
 
-// PauseDraft pauses the draft, stopping the timer and saving remaining time
-func (d *DraftState) PauseDraft() error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	if d.draftStatus != StatusInProgress {
-		return fmt.Errorf("can only pause an in-progress draft")
-	}
-
-	// Calculate remaining time before stopping timer
-	d.remainingTime = max(time.Until(d.turnDeadline), 0)
-
-	// Stop the timer
-	if d.pickTimer != nil {
-		d.pickTimer.Stop()
-	}
-
-	d.draftStatus = StatusPaused
-
-	// Emit draft paused message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":          MsgTypeDraftPaused,
-		"eventID":       d.eventID,
-		"remainingTime": d.remainingTime.Seconds(),
+// PauseDraft pauses the draft, stopping the timer and saving remaining time.
+// A positive duration resumes the draft on its own once it has passed; an empty
+// reason defaults to PauseReasonCommissioner.
+func (d *DraftState) PauseDraft(reason string, duration time.Duration) error {
+	return d.exec(func() error {
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			reason = PauseReasonCommissioner
+		}
+		if utf8.RuneCountInString(reason) > maxPauseReasonLength {
+			return newError(ErrCodeRuleViolation, "pause reason cannot exceed %d characters", maxPauseReasonLength)
+		}
+		if duration < 0 {
+			return newError(ErrCodeRuleViolation, "pause duration cannot be negative")
+		}
+
+		return d.pause(reason, duration)
 	})
-	d.outgoing <- msg
-
-	return nil
 }
 
 // ResumeDraft resumes a paused draft, restarting the timer with remaining time
 func (d *DraftState) ResumeDraft() error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+	return d.exec(func() error {
+		return d.resume()
+	})
+}
 
+// resume resumes a paused draft, restarting the timer with remaining time
+// Must be called on the room's loop
+func (d *DraftState) resume() error {
 	if d.draftStatus != StatusPaused {
-		return fmt.Errorf("can only resume a paused draft")
+		return newError(ErrCodeRuleViolation, "can only resume a paused draft")
 	}
 
-	d.draftStatus = StatusInProgress
+	if err := d.record(DraftEventResumed, struct{}{}); err != nil {
+		return err
+	}
 
 	// Restart timer with remaining time
-	d.startTimer(d.remainingTime)
+	d.startTimer()
+	d.scheduleResume()
+
+	// If picks couldn't be saved, try again now rather than waiting
+	d.outbox.retry()
+	d.logOutbox.retry()
 
 	// Emit draft resumed message
-	msg, _ := json.Marshal(map[string]interface{}{
-		"type":         MsgTypeDraftResumed,
-		"eventID":      d.eventID,
-		"currentTurn":  d.currentTurnID,
-		"roundNumber":  d.roundNumber,
-		"turnDeadline": d.turnDeadline.Unix(),
+	d.publisher.Publish(&DraftResumedMessage{
+		Envelope:     Envelope{Type: MsgTypeDraftResumed},
+		EventID:      d.eventID,
+		CurrentTurn:  d.currentTurnID,
+		RoundNumber:  d.roundNumber,
+		TurnDeadline: d.turnDeadline.Unix(),
 	})
-	d.outgoing <- msg
 
 	return nil
 }
@@ -347,63 +561,67 @@ func (d *DraftState) isPlayerAvailable(playerID int) bool {
 	return slices.Contains(d.availablePlayers, playerID)
 }
 
-// removePlayer removes a player from the available list
-func (d *DraftState) removePlayer(playerID int) {
-	d.availablePlayers = slices.DeleteFunc(d.availablePlayers, func(id int) bool {
-		return id == playerID
-	})
-}
-
-// Outgoing returns the channel for reading outgoing messages
-func (d *DraftState) Outgoing() <-chan []byte {
-	return d.outgoing
-}
-
-// PickResults returns the channel for reading completed picks (for persistence)
-func (d *DraftState) PickResults() <-chan PickResult {
-	return d.pickResults
+// GetCurrentTurn returns the user ID of the current turn
+func (d *DraftState) GetCurrentTurn() (userID int) {
+	d.do(func() { userID = d.currentTurnID })
+	return userID
 }
 
-// GetCurrentTurn returns the user ID of the current turn
-func (d *DraftState) GetCurrentTurn() int {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.currentTurnID
+// OnTheClock returns the user whose turn it is and when their timer started.
+// ok is false unless the draft is in progress.
+func (d *DraftState) OnTheClock() (userID int, since time.Time, ok bool) {
+	d.do(func() {
+		if d.draftStatus == StatusInProgress {
+			userID, since, ok = d.currentTurnID, d.turnStartedAt, true
+		}
+	})
+	return userID, since, ok
 }
 
 // GetStatus returns the current draft status
-func (d *DraftState) GetStatus() DraftStatus {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.draftStatus
+func (d *DraftState) GetStatus() (status DraftStatus) {
+	d.do(func() { status = d.draftStatus })
+	return status
 }
 
 // GetRoundNumber returns the current round number
-func (d *DraftState) GetRoundNumber() int {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.roundNumber
+func (d *DraftState) GetRoundNumber() (round int) {
+	d.do(func() { round = d.roundNumber })
+	return round
 }
 
 // GetEventID returns the event ID for this draft
-func (d *DraftState) GetEventID() int {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.eventID
+func (d *DraftState) GetEventID() (eventID int) {
+	d.do(func() { eventID = d.eventID })
+	return eventID
 }
 
-// SetAvailablePlayers sets the available players for the draft
-func (d *DraftState) SetAvailablePlayers(playerIDs []int) {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.availablePlayers = playerIDs
+// SetPlayerPool sets the event's players, best ranked first, as the players
+// available for the draft, and reads the auto-draft strategy and roster
+// requirements from the event's stipulations
+func (d *DraftState) SetPlayerPool(players []models.Player, stipulations models.Stipulations) error {
+	strategy, requirements, err := parseStipulations(stipulations)
+	if err != nil {
+		return err
+	}
+
+	return d.do(func() {
+		d.availablePlayers = make([]int, 0, len(players))
+		d.players = make(map[int]models.Player, len(players))
+		for _, player := range players {
+			d.availablePlayers = append(d.availablePlayers, player.ID)
+			d.players[player.ID] = player
+		}
+		d.rankedPlayers = slices.Clone(d.availablePlayers)
+		d.requirements = requirements
+		d.eventStrategy = strategy
+	})
 }
 
 // GetAvailablePlayers returns the available players for the draft
-func (d *DraftState) GetAvailablePlayers() []int {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	return d.availablePlayers
+func (d *DraftState) GetAvailablePlayers() (players []int) {
+	d.do(func() { players = d.availablePlayers })
+	return players
 }
 
 // Completed returns a channel that is closed when the draft completes
@@ -412,40 +630,11 @@ func (d *DraftState) Completed() <-chan struct{} {
 }
 
 // GetSnapshot returns a snapshot of the current draft state for client synchronization
-func (d *DraftState) GetSnapshot() DraftSnapshot {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	// Calculate remaining time for paused state, or time until deadline for active state
-	var remainingTime float64
-	switch d.draftStatus {
-	case StatusPaused:
-		remainingTime = d.remainingTime.Seconds()
-	case StatusInProgress:
-		remainingTime = max(time.Until(d.turnDeadline).Seconds(), 0)
-	}
-
-	// Copy slices to avoid data races
-	pickOrder := make([]int, len(d.pickOrder))
-	copy(pickOrder, d.pickOrder)
-
-	availablePlayers := make([]int, len(d.availablePlayers))
-	copy(availablePlayers, d.availablePlayers)
-
-	pickHistory := make([]PickResult, len(d.pickHistory))
-	copy(pickHistory, d.pickHistory)
-
-	return DraftSnapshot{
-		EventID:          d.eventID,
-		Status:           d.draftStatus,
-		CurrentTurn:      d.currentTurnID,
-		RoundNumber:      d.roundNumber,
-		CurrentPickIndex: d.currentPickIndex,
-		TotalRounds:      d.totalRounds,
-		PickOrder:        pickOrder,
-		AvailablePlayers: availablePlayers,
-		TurnDeadline:     d.turnDeadline.Unix(),
-		RemainingTime:    remainingTime,
-		PickHistory:      pickHistory,
-	}
+// All state broadcasts are published from the room's loop, so LastSeq is exact
+func (d *DraftState) GetSnapshot() (snapshot DraftSnapshot) {
+	d.do(func() {
+		snapshot = d.snapshot(d.clock.Now())
+		snapshot.LastSeq = d.publisher.LastSeq()
+	})
+	return snapshot
 }
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// lastSecondWindow is how close to the deadline a pick must be to count as last-second
+const lastSecondWindow = 5 * time.Second
+
+// AnalyticsHandler handles post-draft statistics
+type AnalyticsHandler struct {
+	draftResultRepo *repository.DraftResultRepository
+	eventRepo       *repository.EventRepository
+	userRepo        *repository.UserRepository
+	eventPlayerRepo *repository.EventPlayerRepository
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler
+func NewAnalyticsHandler(draftResultRepo *repository.DraftResultRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository, eventPlayerRepo *repository.EventPlayerRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		draftResultRepo: draftResultRepo,
+		eventRepo:       eventRepo,
+		userRepo:        userRepo,
+		eventPlayerRepo: eventPlayerRepo,
+	}
+}
+
+// pickTimes summarizes how long picks took. Only picks with stored turn times count.
+type pickTimes struct {
+	TimedPicks         int     `json:"timedPicks"`
+	AvgPickSeconds     float64 `json:"avgPickSeconds"`
+	SlowestPickSeconds float64 `json:"slowestPickSeconds"`
+	SlowestPickNumber  int     `json:"slowestPickNumber,omitempty"`
+	LastSecondPicks    int     `json:"lastSecondPicks"` // Made by the team within lastSecondWindow of the deadline
+
+	total time.Duration
+}
+
+// add counts a pick's time on the clock
+func (t *pickTimes) add(pick *models.DraftPickDetail) {
+	if pick.TurnStartedAt == nil || pick.TurnDeadline == nil {
+		return
+	}
+	took := max(pick.PickedAt.Sub(*pick.TurnStartedAt), 0)
+	t.TimedPicks++
+	t.total += took
+	t.AvgPickSeconds = seconds(t.total / time.Duration(t.TimedPicks))
+	if seconds(took) > t.SlowestPickSeconds || t.SlowestPickNumber == 0 {
+		t.SlowestPickSeconds = seconds(took)
+		t.SlowestPickNumber = pick.PickNumber
+	}
+	// Auto-drafts are made for the team when its time runs out or it's on
+	// auto-pilot; they don't say anything about how close it cut it
+	if !pick.IsAutoDraft && pick.TurnDeadline.Sub(pick.PickedAt) <= lastSecondWindow {
+		t.LastSecondPicks++
+	}
+}
+
+// teamAnalytics is one team's recap
+type teamAnalytics struct {
+	UserID         int    `json:"userID"`
+	TeamName       string `json:"teamName"`
+	Picks          int    `json:"picks"`
+	AutoDraftCount int    `json:"autoDraftCount"`
+	pickTimes
+	Countries         map[string]int `json:"countries"` // Picks per country code
+	AmateurCount      int            `json:"amateurCount"`
+	ProfessionalCount int            `json:"professionalCount"`
+}
+
+// rankedPick is a pick compared against where its player's ranking says it should have gone
+type rankedPick struct {
+	PickNumber   int    `json:"pickNumber"`
+	UserID       int    `json:"userID"`
+	TeamName     string `json:"teamName"`
+	PlayerID     int    `json:"playerID"`
+	Player       string `json:"player"`
+	Ranking      int    `json:"ranking"`
+	ExpectedPick int    `json:"expectedPick"` // The player's ranking among the event's ranked players
+	Difference   int    `json:"difference"`   // pickNumber - expectedPick
+}
+
+// draftAnalytics is the recap of a whole draft
+type draftAnalytics struct {
+	EventID        int `json:"eventID"`
+	TotalPicks     int `json:"totalPicks"`
+	AutoDraftCount int `json:"autoDraftCount"`
+	pickTimes
+	Teams   []teamAnalytics `json:"teams"`
+	Reaches []rankedPick    `json:"reaches"`
+	Values  []rankedPick    `json:"values"`
+}
+
+// GetAnalytics handles GET /events/{id}/analytics
+func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	_, board, err := loadDraftBoard(r.Context(), h.draftResultRepo, h.eventRepo, h.userRepo, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	pool, err := h.eventPlayerRepo.GetPlayersByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildDraftAnalytics(board, pool))
+}
+
+// buildDraftAnalytics computes the recap from the board, with teams in draft order.
+// A pick is a reach if it was made more than a round earlier than its player's
+// ranking among the event's pool suggests, and a value if more than a round later.
+// Auto-drafted picks are chosen by a strategy rather than the team, so they're
+// never reaches or values.
+func buildDraftAnalytics(board *draftBoard, pool []models.Player) *draftAnalytics {
+	analytics := &draftAnalytics{
+		EventID: board.EventID,
+		Teams:   make([]teamAnalytics, len(board.Teams)),
+		Reaches: []rankedPick{},
+		Values:  []rankedPick{},
+	}
+	for i, team := range board.Teams {
+		analytics.Teams[i] = teamAnalytics{
+			UserID:    team.UserID,
+			TeamName:  team.TeamName,
+			Countries: map[string]int{},
+		}
+	}
+
+	expectedPicks := expectedPicksByRanking(pool)
+	threshold := max(len(board.Teams), 1)
+
+	var picks []*models.DraftPickDetail
+	for _, round := range board.Rounds {
+		for col, pick := range round.Picks {
+			if pick == nil {
+				continue
+			}
+			picks = append(picks, pick)
+
+			team := &analytics.Teams[col]
+			team.Picks++
+			team.pickTimes.add(pick)
+			team.Countries[pick.Player.CountryCode]++
+			if pick.Player.Status == "amateur" {
+				team.AmateurCount++
+			} else {
+				team.ProfessionalCount++
+			}
+			if pick.IsAutoDraft {
+				team.AutoDraftCount++
+				continue
+			}
+
+			expected, ok := expectedPicks[pick.Player.ID]
+			if !ok {
+				continue
+			}
+			ranked := rankedPick{
+				PickNumber:   pick.PickNumber,
+				UserID:       pick.UserID,
+				TeamName:     pick.TeamName,
+				PlayerID:     pick.Player.ID,
+				Player:       pick.Player.FirstName + " " + pick.Player.LastName,
+				Ranking:      *pick.Player.Ranking,
+				ExpectedPick: expected,
+				Difference:   pick.PickNumber - expected,
+			}
+			switch {
+			case ranked.Difference < -threshold:
+				analytics.Reaches = append(analytics.Reaches, ranked)
+			case ranked.Difference > threshold:
+				analytics.Values = append(analytics.Values, ranked)
+			}
+		}
+	}
+
+	// Totals are timed in pick order so the slowest pick is the earliest on a tie
+	slices.SortFunc(picks, func(a, b *models.DraftPickDetail) int {
+		return a.PickNumber - b.PickNumber
+	})
+	for _, pick := range picks {
+		analytics.TotalPicks++
+		analytics.pickTimes.add(pick)
+		if pick.IsAutoDraft {
+			analytics.AutoDraftCount++
+		}
+	}
+
+	// Biggest reaches and values first
+	slices.SortFunc(analytics.Reaches, func(a, b rankedPick) int {
+		return a.Difference - b.Difference
+	})
+	slices.SortFunc(analytics.Values, func(a, b rankedPick) int {
+		return b.Difference - a.Difference
+	})
+
+	return analytics
+}
+
+// expectedPicksByRanking maps each ranked player in the pool to their position
+// among the pool's ranked players, best first: where they'd go in a draft by ranking
+func expectedPicksByRanking(pool []models.Player) map[int]int {
+	ranked := slices.DeleteFunc(slices.Clone(pool), func(p models.Player) bool {
+		return p.Ranking == nil
+	})
+	slices.SortStableFunc(ranked, func(a, b models.Player) int {
+		return *a.Ranking - *b.Ranking
+	})
+
+	expected := make(map[int]int, len(ranked))
+	for i, player := range ranked {
+		expected[player.ID] = i + 1
+	}
+	return expected
+}
+
+// seconds converts a duration to seconds, rounded to hundredths
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*100) / 100
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// timedPick is a pick made after took of a 60 second turn
+func timedPick(pickNumber, userID, playerID int, took time.Duration, autoDraft bool) *models.DraftPickDetail {
+	started := time.Date(2026, 4, 9, 18, 0, 0, 0, time.UTC).Add(time.Duration(pickNumber) * time.Minute)
+	deadline := started.Add(time.Minute)
+	return &models.DraftPickDetail{
+		PickNumber:    pickNumber,
+		Round:         1,
+		UserID:        userID,
+		Player:        models.Player{ID: playerID, CountryCode: "USA", Status: "professional"},
+		IsAutoDraft:   autoDraft,
+		PickedAt:      started.Add(took),
+		TurnStartedAt: &started,
+		TurnDeadline:  &deadline,
+	}
+}
+
+func TestLastSecondPicksExcludeAutoDrafts(t *testing.T) {
+	board := &draftBoard{
+		EventID: 1,
+		Teams:   []boardTeam{{UserID: 1, TeamName: "Team 1"}, {UserID: 2, TeamName: "Team 2"}, {UserID: 3, TeamName: "Team 3"}},
+		Rounds: []boardRound{{Round: 1, Picks: []*models.DraftPickDetail{
+			timedPick(1, 1, 10, 57*time.Second, false), // Beat the clock
+			timedPick(2, 2, 11, time.Minute, true),     // Timed out
+			timedPick(3, 3, 12, 20*time.Second, false),
+		}}},
+	}
+
+	analytics := buildDraftAnalytics(board, nil)
+	if analytics.LastSecondPicks != 1 || analytics.AutoDraftCount != 1 || analytics.TimedPicks != 3 {
+		t.Fatalf("%d last-second picks, %d auto-drafts and %d timed picks; want 1, 1 and 3",
+			analytics.LastSecondPicks, analytics.AutoDraftCount, analytics.TimedPicks)
+	}
+	for i, want := range []int{1, 0, 0} {
+		if got := analytics.Teams[i].LastSecondPicks; got != want {
+			t.Fatalf("team %d has %d last-second picks, want %d", i+1, got, want)
+		}
+	}
+	if analytics.SlowestPickNumber != 2 {
+		t.Fatalf("slowest pick %d, want the timed-out pick 2", analytics.SlowestPickNumber)
+	}
+}
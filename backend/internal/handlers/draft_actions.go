@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// DraftActionHandler exposes the draft room over plain HTTP: an SSE stream of
+// room messages plus REST actions, for scripts and clients that can't use WebSocket.
+// Actions authenticate with "Authorization: Bearer <token>": the ADMIN_KEY for
+// commissioner actions, or a team's rejoin or member token (or the ADMIN_KEY) for picks.
+type DraftActionHandler struct {
+	userRepo        *repository.UserRepository
+	draftResultRepo *repository.DraftResultRepository
+	draftEventRepo  *repository.DraftEventRepository
+	draftService    *draft.DraftService
+}
+
+// NewDraftActionHandler creates a new DraftActionHandler
+func NewDraftActionHandler(userRepo *repository.UserRepository, draftResultRepo *repository.DraftResultRepository, draftEventRepo *repository.DraftEventRepository, draftService *draft.DraftService) *DraftActionHandler {
+	return &DraftActionHandler{
+		userRepo:        userRepo,
+		draftResultRepo: draftResultRepo,
+		draftEventRepo:  draftEventRepo,
+		draftService:    draftService,
+	}
+}
+
+// Stream handles GET /events/{id}/draft/stream
+// Authenticates like /ws/draft, or with a ticket query parameter from StreamTicket
+func (h *DraftActionHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	h.draftService.HandleStream(w, r, eventID)
+}
+
+// StreamTicket handles POST /events/{id}/draft/stream-ticket
+// Authenticates like /ws/draft and returns a single-use ticket for opening the
+// stream from a browser, whose EventSource can't send an Authorization header
+func (h *DraftActionHandler) StreamTicket(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	h.draftService.HandleStreamTicket(w, r, eventID)
+}
+
+// StartDraft handles POST /events/{id}/draft/start (commissioner only)
+// Accepts: {"pickOrder": [1, 2, 3], "totalRounds": 5, "timerDuration": 60,
+// "scheduledBreaks": [{"afterRound": 3, "duration": 600}]} (scheduledBreaks optional)
+func (h *DraftActionHandler) StartDraft(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var body struct {
+		PickOrder     []int `json:"pickOrder"`
+		TotalRounds   int   `json:"totalRounds"`
+		TimerDuration int   `json:"timerDuration"` // in seconds
+
+		ScheduledBreaks []draft.ScheduledBreak `json:"scheduledBreaks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.draftService.StartDraft(eventID, body.PickOrder, body.TotalRounds, time.Duration(body.TimerDuration)*time.Second, body.ScheduledBreaks)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	writeActionResult(w, result)
+}
+
+// MakePick handles POST /events/{id}/draft/pick
+// Accepts: {"userID": 2, "playerID": 14, "pickNumber": 7} (pickNumber optional)
+// Authorized by the team's rejoin token, or the ADMIN_KEY to pick for any team.
+func (h *DraftActionHandler) MakePick(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		UserID     int `json:"userID"`
+		PlayerID   int `json:"playerID"`
+		PickNumber int `json:"pickNumber"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if body.UserID <= 0 || body.PlayerID <= 0 {
+		http.Error(w, `{"error": "userID and playerID are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !h.authorizeTeam(r, eventID, body.UserID) {
+		http.Error(w, `{"error": "team authorization required", "code": "FORBIDDEN"}`, http.StatusUnauthorized)
+		return
+	}
+
+	result, err := h.draftService.MakePick(eventID, body.UserID, body.PlayerID, body.PickNumber)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	writeActionResult(w, result)
+}
+
+// PauseDraft handles POST /events/{id}/draft/pause (commissioner only)
+// Accepts an optional body: {"reason": "Dinner break", "duration": 600} (both optional)
+func (h *DraftActionHandler) PauseDraft(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var body struct {
+		Reason   string `json:"reason"`
+		Duration int    `json:"duration"` // in seconds
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.draftService.PauseDraft(eventID, body.Reason, time.Duration(body.Duration)*time.Second)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	writeActionResult(w, result)
+}
+
+// ResumeDraft handles POST /events/{id}/draft/resume (commissioner only)
+func (h *DraftActionHandler) ResumeDraft(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	result, err := h.draftService.ResumeDraft(eventID)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	writeActionResult(w, result)
+}
+
+// Reconcile handles GET /events/{id}/draft/reconcile (commissioner only)
+// Compares the draft room's picks with those stored in the database
+func (h *DraftActionHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	// Load stored picks first, so picks saved meanwhile aren't reported missing
+	stored, err := h.draftResultRepo.GetByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	result, err := h.draftService.Reconcile(eventID, stored)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// draftLog is an event's draft log and the state it folds to
+type draftLog struct {
+	EventID      int                  `json:"eventID"`
+	Events       []models.DraftEvent  `json:"events"`
+	State        *draft.DraftSnapshot `json:"state"`                  // Nil if the log can't be folded
+	RebuildError string               `json:"rebuildError,omitempty"` // Why the log can't be folded
+}
+
+// Log handles GET /events/{id}/draft/log (commissioner only)
+// Returns the stored draft log and the state rebuilt from it, for auditing
+func (h *DraftActionHandler) Log(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	events, err := h.draftEventRepo.GetByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	result := draftLog{EventID: eventID, Events: events}
+	if state, err := draft.Rebuild(eventID, events); err != nil {
+		result.RebuildError = err.Error()
+	} else {
+		result.State = &state
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// authorizeTeam reports whether the request's bearer token may act for the team:
+// the ADMIN_KEY, or the team's rejoin token or one of its members' tokens, for a
+// team in this event
+func (h *DraftActionHandler) authorizeTeam(r *http.Request, eventID, userID int) bool {
+	token := bearerToken(r)
+	if token == "" {
+		return false
+	}
+	if auth.IsAdminKey(token) {
+		return true
+	}
+	if user, _, err := h.userRepo.GetUserByMemberToken(r.Context(), token); err == nil {
+		return user.ID == userID && user.EventID == eventID
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user.EventID != eventID || user.RejoinTokenHash == nil {
+		return false
+	}
+	return auth.VerifySecret(token, *user.RejoinTokenHash)
+}
+
+// bearerToken returns the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// requireAdmin reports whether the request carries the ADMIN_KEY, as a bearer
+// token or an X-Admin-Key header. If not, it writes a 401 and returns false.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if auth.IsAdminKey(bearerToken(r)) || auth.IsAdminKey(r.Header.Get("X-Admin-Key")) {
+		return true
+	}
+	http.Error(w, `{"error": "commissioner authorization required", "code": "FORBIDDEN"}`, http.StatusUnauthorized)
+	return false
+}
+
+// writeActionResult writes the state of the draft after a successful action
+func writeActionResult(w http.ResponseWriter, result *draft.ActionResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// draftErrorStatus maps draft error codes to HTTP status codes
+var draftErrorStatus = map[draft.ErrorCode]int{
+	draft.ErrCodeNotYourTurn:       http.StatusConflict,
+	draft.ErrCodePlayerUnavailable: http.StatusConflict,
+	draft.ErrCodeDraftNotActive:    http.StatusConflict,
+	draft.ErrCodeRuleViolation:     http.StatusUnprocessableEntity,
+	draft.ErrCodeForbidden:         http.StatusForbidden,
+	draft.ErrCodeRateLimited:       http.StatusTooManyRequests,
+	draft.ErrCodeInvalidMessage:    http.StatusBadRequest,
+	draft.ErrCodeInternal:          http.StatusInternalServerError,
+}
+
+// writeDraftError writes a draft error as {"error": ..., "code": ...}, with the
+// same codes the WebSocket uses
+func writeDraftError(w http.ResponseWriter, err error) {
+	code := draft.ErrorCodeOf(err)
+	status, ok := draftErrorStatus[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+		"code":  string(code),
+	})
+}
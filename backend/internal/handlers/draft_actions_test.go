@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDraftControlsRequireAdminKey(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	h := &DraftActionHandler{}
+
+	controls := map[string]http.HandlerFunc{
+		"start":     h.StartDraft,
+		"pause":     h.PauseDraft,
+		"resume":    h.ResumeDraft,
+		"reconcile": h.Reconcile,
+	}
+	for name, handler := range controls {
+		for _, bearer := range []string{"", "wrong-key"} {
+			rec := serve(t, handler, http.MethodPost, "/events/{id}/draft/"+name, "/events/1/draft/"+name, map[string]any{}, bearer)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("%s with bearer %q: status %d, want 401", name, bearer, rec.Code)
+			}
+		}
+	}
+}
+
+func TestAdminKeyAcceptedInEitherHeader(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		if requireAdmin(w, r) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+
+	tests := []struct {
+		header http.Header
+		want   int
+	}{
+		{header: http.Header{}, want: http.StatusUnauthorized},
+		{header: http.Header{"Authorization": {"Bearer wrong-key"}}, want: http.StatusUnauthorized},
+		{header: http.Header{"X-Admin-Key": {"wrong-key"}}, want: http.StatusUnauthorized},
+		{header: http.Header{"Authorization": {"Bearer " + testAdminKey}}, want: http.StatusNoContent},
+		{header: http.Header{"X-Admin-Key": {testAdminKey}}, want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header = tt.header
+		rec := httptest.NewRecorder()
+		ok(rec, req)
+		if rec.Code != tt.want {
+			t.Fatalf("with headers %v: status %d, want %d", tt.header, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// DraftResultHandler handles HTTP endpoints for viewing draft results
+type DraftResultHandler struct {
+	draftResultRepo *repository.DraftResultRepository
+	eventRepo       *repository.EventRepository
+	userRepo        *repository.UserRepository
+}
+
+// NewDraftResultHandler creates a new DraftResultHandler
+func NewDraftResultHandler(draftResultRepo *repository.DraftResultRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository) *DraftResultHandler {
+	return &DraftResultHandler{
+		draftResultRepo: draftResultRepo,
+		eventRepo:       eventRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// boardTeam is a column of the draft board
+type boardTeam struct {
+	UserID   int    `json:"userID"`
+	TeamName string `json:"teamName"`
+}
+
+// boardRound is a row of the draft board, with one cell per team column
+// A cell is null if that team hasn't picked in the round yet
+type boardRound struct {
+	Round int                       `json:"round"`
+	Picks []*models.DraftPickDetail `json:"picks"`
+}
+
+// draftBoard is the rounds × teams grid of an event's picks
+type draftBoard struct {
+	EventID int          `json:"eventID"`
+	Teams   []boardTeam  `json:"teams"`
+	Rounds  []boardRound `json:"rounds"`
+}
+
+// GetResults handles GET /events/{id}/results
+// Optional query parameters: round, userID
+func (h *DraftResultHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	round, userID, ok := parseResultFilters(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.eventRepo.GetByID(r.Context(), eventID); err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	picks, err := h.draftResultRepo.GetDetailsByEvent(r.Context(), eventID, round, userID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"eventID": eventID,
+		"picks":   picks,
+	})
+}
+
+// GetRoster handles GET /events/{id}/teams/{userID}/roster
+func (h *DraftResultHandler) GetRoster(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "team not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if user.EventID != eventID {
+		http.Error(w, `{"error": "team not found"}`, http.StatusNotFound)
+		return
+	}
+
+	picks, err := h.draftResultRepo.GetDetailsByEvent(r.Context(), eventID, 0, userID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"eventID":  eventID,
+		"userID":   user.ID,
+		"teamName": user.Username,
+		"picks":    picks,
+	})
+}
+
+// GetBoard handles GET /events/{id}/board
+// Returns the draft as a grid of rounds × teams. Optional query parameters:
+// round (a single row) and userID (a single column)
+func (h *DraftResultHandler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	round, userID, ok := parseResultFilters(w, r)
+	if !ok {
+		return
+	}
+
+	_, board, err := loadDraftBoard(r.Context(), h.draftResultRepo, h.eventRepo, h.userRepo, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	board.filter(round, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(board)
+}
+
+// parseResultFilters reads the optional round and userID query parameters
+// Writes a 400 and returns ok=false if either is invalid
+func parseResultFilters(w http.ResponseWriter, r *http.Request) (round, userID int, ok bool) {
+	query := r.URL.Query()
+	if roundStr := query.Get("round"); roundStr != "" {
+		var err error
+		round, err = strconv.Atoi(roundStr)
+		if err != nil || round <= 0 {
+			http.Error(w, `{"error": "round must be a positive integer"}`, http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	if userIDStr := query.Get("userID"); userIDStr != "" {
+		var err error
+		userID, err = strconv.Atoi(userIDStr)
+		if err != nil || userID <= 0 {
+			http.Error(w, `{"error": "userID must be a positive integer"}`, http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	return round, userID, true
+}
+
+// loadDraftBoard loads an event and builds its full board
+// Returns pgx.ErrNoRows if the event doesn't exist
+func loadDraftBoard(ctx context.Context, draftResultRepo *repository.DraftResultRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository, eventID int) (*models.Event, *draftBoard, error) {
+	event, err := eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, err := userRepo.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	picks, err := draftResultRepo.GetDetailsByEvent(ctx, eventID, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return event, buildDraftBoard(event, users, picks), nil
+}
+
+// buildDraftBoard lays picks out as rounds × teams. Teams are ordered by their
+// first pick, so columns follow the draft order; teams that haven't picked come
+// last. There is a row for every round up to the event's picks per team, so a
+// draft in progress shows its empty cells.
+func buildDraftBoard(event *models.Event, users []models.User, picks []models.DraftPickDetail) *draftBoard {
+	firstPick := make(map[int]int)
+	totalRounds := event.MaxPicksPerTeam
+	for _, pick := range picks {
+		if _, ok := firstPick[pick.UserID]; !ok {
+			firstPick[pick.UserID] = pick.PickNumber
+		}
+		totalRounds = max(totalRounds, pick.Round)
+	}
+
+	slices.SortStableFunc(users, func(a, b models.User) int {
+		pa, aPicked := firstPick[a.ID]
+		pb, bPicked := firstPick[b.ID]
+		switch {
+		case aPicked && bPicked:
+			return pa - pb
+		case aPicked:
+			return -1
+		case bPicked:
+			return 1
+		default:
+			return a.ID - b.ID
+		}
+	})
+
+	board := &draftBoard{
+		EventID: event.ID,
+		Teams:   make([]boardTeam, len(users)),
+		Rounds:  make([]boardRound, totalRounds),
+	}
+	column := make(map[int]int, len(users))
+	for i, user := range users {
+		board.Teams[i] = boardTeam{UserID: user.ID, TeamName: user.Username}
+		column[user.ID] = i
+	}
+	for i := range board.Rounds {
+		board.Rounds[i] = boardRound{Round: i + 1, Picks: make([]*models.DraftPickDetail, len(users))}
+	}
+	for i := range picks {
+		col, ok := column[picks[i].UserID]
+		if !ok {
+			continue
+		}
+		board.Rounds[picks[i].Round-1].Picks[col] = &picks[i]
+	}
+
+	return board
+}
+
+// filter narrows the board to a single round and/or team; zero means no filter
+func (b *draftBoard) filter(round, userID int) {
+	if round > 0 {
+		b.Rounds = slices.DeleteFunc(b.Rounds, func(row boardRound) bool {
+			return row.Round != round
+		})
+	}
+	if userID > 0 {
+		col := slices.IndexFunc(b.Teams, func(team boardTeam) bool {
+			return team.UserID == userID
+		})
+		if col < 0 {
+			b.Teams = []boardTeam{}
+			for i := range b.Rounds {
+				b.Rounds[i].Picks = []*models.DraftPickDetail{}
+			}
+			return
+		}
+		b.Teams = b.Teams[col : col+1]
+		for i := range b.Rounds {
+			b.Rounds[i].Picks = b.Rounds[i].Picks[col : col+1]
+		}
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
@@ -18,6 +20,10 @@ type DraftRoomHandler struct {
 	eventPlayerRepo *repository.EventPlayerRepository
 	eventRepo       *repository.EventRepository
 	userRepo        *repository.UserRepository
+	memberRepo      *repository.TeamMemberRepository
+	inviteRepo      *repository.InviteRepository
+	draftEventRepo  *repository.DraftEventRepository
+	inviteSigner    *auth.Signer
 	draftService    *draft.DraftService
 }
 
@@ -26,18 +32,27 @@ func NewDraftRoomHandler(
 	eventPlayerRepo *repository.EventPlayerRepository,
 	eventRepo *repository.EventRepository,
 	userRepo *repository.UserRepository,
+	memberRepo *repository.TeamMemberRepository,
+	inviteRepo *repository.InviteRepository,
+	draftEventRepo *repository.DraftEventRepository,
+	inviteSigner *auth.Signer,
 	draftService *draft.DraftService,
 ) *DraftRoomHandler {
 	return &DraftRoomHandler{
 		eventPlayerRepo: eventPlayerRepo,
 		eventRepo:       eventRepo,
 		userRepo:        userRepo,
+		memberRepo:      memberRepo,
+		inviteRepo:      inviteRepo,
+		draftEventRepo:  draftEventRepo,
+		inviteSigner:    inviteSigner,
 		draftService:    draftService,
 	}
 }
 
 // CreateDraftRoom handles POST /events/{id}/draft-room
-// Fetches available players from the database and creates a draft room
+// Fetches the event's players and stipulations from the database and creates a
+// draft room. If the event already has a draft log, the room is restored from it instead.
 func (h *DraftRoomHandler) CreateDraftRoom(w http.ResponseWriter, r *http.Request) {
 	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
@@ -45,21 +60,60 @@ func (h *DraftRoomHandler) CreateDraftRoom(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	event, err := h.eventRepo.GetByID(r.Context(), eventID)
+	if err == pgx.ErrNoRows {
+		http.Error(w, `{"error": "Event not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Failed to get event"}`, http.StatusInternalServerError)
+		return
+	}
+
 	// Get available players for this event from the database
-	playerIDs, err := h.eventPlayerRepo.GetPlayerIDsByEvent(r.Context(), eventID)
+	players, err := h.eventPlayerRepo.GetPlayersByEvent(r.Context(), eventID)
 	if err != nil {
 		http.Error(w, `{"error": "Failed to get players"}`, http.StatusInternalServerError)
 		return
 	}
 
-	if len(playerIDs) == 0 {
+	if len(players) == 0 {
 		http.Error(w, `{"error": "No players assigned to this event"}`, http.StatusBadRequest)
 		return
 	}
 
+	events, err := h.draftEventRepo.GetByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to get draft log"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if len(events) > 0 {
+		if err := h.draftService.RestoreRoom(eventID, event.MaxPicksPerTeam, players, event.Stipulations, events); err != nil {
+			if draft.ErrorCodeOf(err) == draft.ErrCodeRuleViolation {
+				writeDraftError(w, err)
+				return
+			}
+			http.Error(w, `{"error": "Failed to restore draft room from its draft log"}`, http.StatusInternalServerError)
+			return
+		}
+
+		room := h.draftService.GetRoom()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"status":           "draft room restored",
+			"eventID":          eventID,
+			"availablePlayers": len(room.GetAvailablePlayers()),
+			"draftStatus":      room.GetStatus(),
+			"draftEvents":      len(events),
+		})
+		return
+	}
+
 	// Delegate to draft handler to create the room
-	if err := h.draftService.CreateRoom(eventID, playerIDs); err != nil {
-		http.Error(w, `{"error": "Failed to create draft room"}`, http.StatusInternalServerError)
+	if err := h.draftService.CreateRoom(eventID, event.MaxPicksPerTeam, players, event.Stipulations); err != nil {
+		writeDraftError(w, err)
 		return
 	}
 
@@ -68,7 +122,7 @@ func (h *DraftRoomHandler) CreateDraftRoom(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(map[string]any{
 		"status":           "draft room created",
 		"eventID":          eventID,
-		"availablePlayers": len(playerIDs),
+		"availablePlayers": len(players),
 	})
 }
 
@@ -97,13 +151,35 @@ func (h *DraftRoomHandler) GetDraftRoom(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// maxMembersPerTeam limits how many people can co-manage one team
+const maxMembersPerTeam = 4
+
+// maxNameLength is the longest team or member name, in characters. Names are
+// stored in VARCHAR(100) columns, and member names are copied into chat.
+const maxNameLength = 100
+
+// joinResponse is the user returned by JoinEvent, plus the rejoin token when one is issued,
+// and the team member the caller is joining as with their member token
+type joinResponse struct {
+	*models.User
+	RejoinToken string             `json:"rejoinToken,omitempty"`
+	Member      *models.TeamMember `json:"member"`
+	MemberToken string             `json:"memberToken,omitempty"`
+}
+
 // JoinEvent handles POST /events/join
-// Validates passkey and registers/authenticates user for the draft
+// Validates passkey (or invite token) and registers/authenticates user for the draft.
+// New teams receive a rejoin token that must be presented to reconnect as that team.
+// Anyone holding the rejoin token can join as a co-manager under their own member name.
+// Every join issues the member a token of their own for connecting to the draft room.
 func (h *DraftRoomHandler) JoinEvent(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var req struct {
-		TeamName string `json:"teamName"`
-		Passkey  string `json:"passkey"`
+		TeamName    string `json:"teamName"`
+		Passkey     string `json:"passkey"`
+		InviteToken string `json:"inviteToken"`
+		RejoinToken string `json:"rejoinToken"`
+		MemberName  string `json:"memberName"` // Defaults to the team name
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error": "Invalid JSON"}`, http.StatusBadRequest)
@@ -112,34 +188,68 @@ func (h *DraftRoomHandler) JoinEvent(w http.ResponseWriter, r *http.Request) {
 
 	req.TeamName = strings.TrimSpace(req.TeamName)
 
-	if req.TeamName == "" {
-		http.Error(w, `{"error": "Team Name is required"}`, http.StatusBadRequest)
-		return
-	}
-
-	if req.Passkey == "" {
+	if req.Passkey == "" && req.InviteToken == "" {
 		http.Error(w, `{"error": "Passkey is required"}`, http.StatusBadRequest)
 		return
 	}
 
-	// Look up event by passkey
-	event, err := h.eventRepo.GetByPasskey(r.Context(), req.Passkey)
-	if err != nil {
+	var event *models.Event
+	var invite *models.Invite
+	var err error
+	if req.InviteToken != "" {
+		// Look up event by invite token
+		invite, err = lookupInvite(r.Context(), h.inviteRepo, h.inviteSigner, req.InviteToken)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				http.Error(w, `{"error": "Invalid invite"}`, http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+
+		// Invites pre-bound to a team fill in or enforce the team name
+		if invite.TeamName != nil {
+			if req.TeamName == "" {
+				req.TeamName = *invite.TeamName
+			} else if !strings.EqualFold(req.TeamName, *invite.TeamName) {
+				http.Error(w, `{"error": "Invite is for a different team"}`, http.StatusForbidden)
+				return
+			}
+		}
+
+		event, err = h.eventRepo.GetByID(r.Context(), invite.EventID)
+	} else {
+		// Look up event by passkey
+		event, err = h.eventRepo.GetByPasskey(r.Context(), req.Passkey)
 		if err == pgx.ErrNoRows {
 			http.Error(w, `{"error": "Invalid Passkey"}`, http.StatusUnauthorized)
 			return
 		}
+	}
+	if err != nil {
 		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	if req.TeamName == "" {
+		http.Error(w, `{"error": "Team Name is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	req.MemberName = strings.TrimSpace(req.MemberName)
+	if req.MemberName == "" {
+		req.MemberName = req.TeamName
+	}
+	if utf8.RuneCountInString(req.TeamName) > maxNameLength || utf8.RuneCountInString(req.MemberName) > maxNameLength {
+		http.Error(w, `{"error": "Team and member names cannot exceed 100 characters"}`, http.StatusBadRequest)
+		return
+	}
+
 	// Check if user already exists for this event
 	existingUser, err := h.userRepo.GetByEventAndUsername(r.Context(), event.ID, req.TeamName)
 	if err == nil {
-		// User exists - return success (reconnection case)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(existingUser)
+		h.rejoinTeam(w, r, existingUser, req.RejoinToken, req.MemberName)
 		return
 	}
 
@@ -161,17 +271,148 @@ func (h *DraftRoomHandler) JoinEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create new user for this event
+	token, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	tokenHash := auth.HashSecret(token)
+	memberToken, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	memberTokenHash := auth.HashSecret(memberToken)
+
+	// Create the team and its founding member, consuming one use of the invite;
+	// rejoining an existing team above does not
 	newUser := &models.User{
-		EventID:  event.ID,
-		Username: req.TeamName,
+		EventID:         event.ID,
+		Username:        req.TeamName,
+		RejoinTokenHash: &tokenHash,
+	}
+	member := &models.TeamMember{Name: req.MemberName, TokenHash: &memberTokenHash}
+	var inviteID *int
+	if invite != nil {
+		inviteID = &invite.ID
 	}
-	if err := h.userRepo.Create(r.Context(), newUser); err != nil {
+	if err := h.userRepo.RegisterTeam(r.Context(), newUser, member, inviteID); err != nil {
+		if err == repository.ErrInviteUsedUp {
+			http.Error(w, `{"error": "Invite has expired or already been used"}`, http.StatusGone)
+			return
+		}
 		http.Error(w, `{"error": "Failed to register team"}`, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(newUser)
+	json.NewEncoder(w).Encode(joinResponse{User: newUser, RejoinToken: token, Member: member, MemberToken: memberToken})
+}
+
+// rejoinTeam authenticates a reconnect to an existing team using its rejoin token.
+// Teams created before rejoin tokens existed can't be claimed by name; the
+// commissioner issues their token with IssueRejoinToken.
+// Joining under a new member name adds a co-manager to the team; joining under
+// an existing one issues that member a new token, revoking the old one, so it
+// takes that member's current token or the ADMIN_KEY as a bearer token. The
+// rejoin token is shared by the team, and would let any co-manager lock out another.
+func (h *DraftRoomHandler) rejoinTeam(w http.ResponseWriter, r *http.Request, user *models.User, rejoinToken, memberName string) {
+	resp := joinResponse{User: user}
+
+	if user.RejoinTokenHash == nil {
+		http.Error(w, `{"error": "Team has no rejoin token - ask the commissioner for one"}`, http.StatusUnauthorized)
+		return
+	}
+	if rejoinToken == "" {
+		http.Error(w, `{"error": "Team Name is taken - rejoin token required"}`, http.StatusUnauthorized)
+		return
+	}
+	if !auth.VerifySecret(rejoinToken, *user.RejoinTokenHash) {
+		http.Error(w, `{"error": "Invalid rejoin token"}`, http.StatusUnauthorized)
+		return
+	}
+
+	memberToken, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	memberTokenHash := auth.HashSecret(memberToken)
+
+	member, err := h.memberRepo.GetByUserAndName(r.Context(), user.ID, memberName)
+	if err == nil {
+		bearer := bearerToken(r)
+		if member.TokenHash != nil && !auth.VerifySecret(bearer, *member.TokenHash) && !auth.IsAdminKey(bearer) {
+			http.Error(w, `{"error": "Member name is taken - member token required"}`, http.StatusUnauthorized)
+			return
+		}
+		err = h.memberRepo.SetTokenHash(r.Context(), member.ID, memberTokenHash)
+	} else if err == pgx.ErrNoRows {
+		count, countErr := h.memberRepo.CountByUser(r.Context(), user.ID)
+		if countErr != nil {
+			http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if count >= maxMembersPerTeam {
+			http.Error(w, `{"error": "Team already has the maximum number of members"}`, http.StatusConflict)
+			return
+		}
+		member = &models.TeamMember{UserID: user.ID, Name: memberName, TokenHash: &memberTokenHash}
+		err = h.memberRepo.Create(r.Context(), member)
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	resp.Member = member
+	resp.MemberToken = memberToken
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// IssueRejoinToken handles POST /events/{id}/teams/{userID}/rejoin-token
+// Commissioner only. Issues a team a new rejoin token, revoking any previous one.
+// Teams created before rejoin tokens existed get theirs this way.
+func (h *DraftRoomHandler) IssueRejoinToken(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err == pgx.ErrNoRows || (err == nil && user.EventID != eventID) {
+		http.Error(w, `{"error": "Team not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := h.userRepo.SetRejoinTokenHash(r.Context(), user.ID, auth.HashSecret(token)); err != nil {
+		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(joinResponse{User: user, RejoinToken: token})
 }
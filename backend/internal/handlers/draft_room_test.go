@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+const testAdminKey = "test-admin-key"
+
+// serve sends a request with an optional JSON body and bearer token to handler,
+// routed under pattern so URL parameters resolve
+func serve(t *testing.T, handler http.HandlerFunc, method, pattern, path string, body any, bearer string) *httptest.ResponseRecorder {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	router := chi.NewRouter()
+	router.MethodFunc(method, pattern, handler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// newDraftRoomHandler creates a DraftRoomHandler on pool, without a draft service
+func newDraftRoomHandler(pool *pgxpool.Pool) *DraftRoomHandler {
+	return NewDraftRoomHandler(
+		repository.NewEventPlayerRepository(pool),
+		repository.NewEventRepository(pool),
+		repository.NewUserRepository(pool),
+		repository.NewTeamMemberRepository(pool),
+		repository.NewInviteRepository(pool),
+		repository.NewDraftEventRepository(pool),
+		auth.NewSigner([]byte("test-invite-key")),
+		nil,
+	)
+}
+
+// createEvent creates an event joined with passkey
+func createEvent(t *testing.T, pool *pgxpool.Pool, passkey string) *models.Event {
+	t.Helper()
+	event := &models.Event{Name: "Test Event", MaxPicksPerTeam: 6, MaxTeamsPerPlayer: 1, Status: models.EventStatusNotStarted, Passkey: &passkey}
+	if err := repository.NewEventRepository(pool).Create(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestIssueRejoinTokenRequiresAdminKey(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	h := &DraftRoomHandler{}
+
+	for _, bearer := range []string{"", "wrong-key"} {
+		rec := serve(t, h.IssueRejoinToken, http.MethodPost, "/events/{id}/teams/{userID}/rejoin-token", "/events/1/teams/2/rejoin-token", nil, bearer)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("with bearer %q: status %d, want 401", bearer, rec.Code)
+		}
+	}
+}
+
+func TestJoinRefusesTeamWithoutRejoinToken(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	pool := dbtest.New(t)
+	h := newDraftRoomHandler(pool)
+	event := createEvent(t, pool, "dye")
+
+	// A team created before rejoin tokens existed
+	legacy := &models.User{EventID: event.ID, Username: "Legacy"}
+	if err := repository.NewUserRepository(pool).Create(context.Background(), legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	join := map[string]string{"teamName": "legacy", "passkey": "dye"}
+	if rec := serve(t, h.JoinEvent, http.MethodPost, "/events/join", "/events/join", join, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("claiming a team without a rejoin token: status %d, want 401", rec.Code)
+	}
+
+	rec := serve(t, h.IssueRejoinToken, http.MethodPost, "/events/{id}/teams/{userID}/rejoin-token",
+		"/events/"+strconv.Itoa(event.ID)+"/teams/"+strconv.Itoa(legacy.ID)+"/rejoin-token", nil, testAdminKey)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("issuing a rejoin token: status %d: %s", rec.Code, rec.Body)
+	}
+	var issued joinResponse
+	if err := json.NewDecoder(rec.Body).Decode(&issued); err != nil || issued.RejoinToken == "" {
+		t.Fatalf("issuing a rejoin token: %+v, %v", issued, err)
+	}
+
+	join["rejoinToken"] = issued.RejoinToken
+	if rec := serve(t, h.JoinEvent, http.MethodPost, "/events/join", "/events/join", join, ""); rec.Code != http.StatusOK {
+		t.Fatalf("rejoining with the issued token: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCoManagersGetTheirOwnMemberTokens(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	pool := dbtest.New(t)
+	h := newDraftRoomHandler(pool)
+	createEvent(t, pool, "dye")
+
+	join := func(body map[string]string, bearer string, want int) joinResponse {
+		t.Helper()
+		rec := serve(t, h.JoinEvent, http.MethodPost, "/events/join", "/events/join", body, bearer)
+		if rec.Code != want {
+			t.Fatalf("joining as %s: status %d: %s", body["memberName"], rec.Code, rec.Body)
+		}
+		var resp joinResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
+	founder := join(map[string]string{"teamName": "Team 1", "passkey": "dye", "memberName": "Sam"}, "", http.StatusCreated)
+	coManager := join(map[string]string{"teamName": "Team 1", "passkey": "dye", "memberName": "Alex", "rejoinToken": founder.RejoinToken}, "", http.StatusOK)
+	if founder.MemberToken == "" || coManager.MemberToken == "" || founder.MemberToken == coManager.MemberToken {
+		t.Fatalf("member tokens %q and %q, want two different tokens", founder.MemberToken, coManager.MemberToken)
+	}
+
+	users := repository.NewUserRepository(pool)
+	_, member, err := users.GetUserByMemberToken(context.Background(), coManager.MemberToken)
+	if err != nil || member.Name != "Alex" {
+		t.Fatalf("co-manager's token resolves to %+v, %v", member, err)
+	}
+
+	// The shared rejoin token doesn't let Alex take over Sam's name
+	asSam := map[string]string{"teamName": "Team 1", "passkey": "dye", "memberName": "Sam", "rejoinToken": founder.RejoinToken}
+	join(asSam, "", http.StatusUnauthorized)
+	join(asSam, coManager.MemberToken, http.StatusUnauthorized)
+
+	// Joining again as Sam, with Sam's token, revokes it
+	again := join(asSam, founder.MemberToken, http.StatusOK)
+	if _, _, err := users.GetUserByMemberToken(context.Background(), founder.MemberToken); err == nil {
+		t.Fatal("Sam's old member token still works")
+	}
+	if _, member, err := users.GetUserByMemberToken(context.Background(), again.MemberToken); err != nil || member.Name != "Sam" {
+		t.Fatalf("Sam's new token resolves to %+v, %v", member, err)
+	}
+
+	// The commissioner can issue Sam a new token, for a lost one
+	join(asSam, testAdminKey, http.StatusOK)
+}
+
+func TestJoinRefusesNamesLongerThanTheirColumns(t *testing.T) {
+	pool := dbtest.New(t)
+	h := newDraftRoomHandler(pool)
+	createEvent(t, pool, "dye")
+
+	long := strings.Repeat("x", maxNameLength+1)
+	for _, body := range []map[string]string{
+		{"teamName": long, "passkey": "dye"},
+		{"teamName": "Team 1", "passkey": "dye", "memberName": long},
+	} {
+		if rec := serve(t, h.JoinEvent, http.MethodPost, "/events/join", "/events/join", body, ""); rec.Code != http.StatusBadRequest {
+			t.Fatalf("joining with a %d-character name: status %d, want 400", len(long), rec.Code)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
 )
@@ -33,9 +34,6 @@ func (h *EventHandler) GetNextEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Strip passkey from response — this endpoint is public
-	event.Passkey = nil
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(event)
@@ -91,10 +89,17 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.repo.Create(r.Context(), &event); err != nil {
+		if err == repository.ErrPasskeyInUse {
+			http.Error(w, `{"error": "passkey already in use by an active event"}`, http.StatusConflict)
+			return
+		}
 		http.Error(w, `{"error": "failed to create event"}`, http.StatusInternalServerError)
 		return
 	}
 
+	// Never echo the plaintext passkey back
+	event.Passkey = nil
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(event)
@@ -123,11 +128,22 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, `{"error": "failed to find event to update"}`, http.StatusNotFound)
 			return
 		}
+		if err == repository.ErrPasskeyInUse {
+			http.Error(w, `{"error": "passkey already in use by an active event"}`, http.StatusConflict)
+			return
+		}
+		if err == repository.ErrPasskeyRequired {
+			http.Error(w, `{"error": "reopening a completed event requires its passkey"}`, http.StatusConflict)
+			return
+		}
 
 		http.Error(w, `{"error": "failed to update event"}`, http.StatusInternalServerError)
 		return
 	}
 
+	// Never echo the plaintext passkey back
+	event.Passkey = nil
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(event)
@@ -154,3 +170,41 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// CreateSpectatorLink handles POST /events/{id}/spectator-link
+// Commissioner only. Generates a new spectator token for read-only draft viewing,
+// revoking any previous one.
+func (h *EventHandler) CreateSpectatorLink(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.repo.SetSpectatorTokenHash(r.Context(), id, auth.HashSecret(token)); err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "failed to create spectator link"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"eventID":        id,
+		"spectatorToken": token,
+	})
+}
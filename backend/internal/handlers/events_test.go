@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+func TestCreateSpectatorLinkRequiresAdminKey(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	h := &EventHandler{}
+
+	for _, bearer := range []string{"", "wrong-key"} {
+		rec := serve(t, h.CreateSpectatorLink, http.MethodPost, "/events/{id}/spectator-link", "/events/1/spectator-link", nil, bearer)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("with bearer %q: status %d, want 401", bearer, rec.Code)
+		}
+	}
+}
+
+func TestSpectatorLinkRevokesThePreviousOne(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	pool := dbtest.New(t)
+	repo := repository.NewEventRepository(pool)
+	h := NewEventHandler(repo)
+	event := createEvent(t, pool, "dye")
+
+	link := func() string {
+		t.Helper()
+		rec := serve(t, h.CreateSpectatorLink, http.MethodPost, "/events/{id}/spectator-link", "/events/"+strconv.Itoa(event.ID)+"/spectator-link", nil, testAdminKey)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("creating a spectator link: status %d: %s", rec.Code, rec.Body)
+		}
+		var resp struct {
+			SpectatorToken string `json:"spectatorToken"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp.SpectatorToken
+	}
+	first, second := link(), link()
+
+	if _, err := repo.GetEventIDBySpectatorToken(context.Background(), first); err == nil {
+		t.Fatal("the first spectator link still works")
+	}
+	if eventID, err := repo.GetEventIDBySpectatorToken(context.Background(), second); err != nil || eventID != event.ID {
+		t.Fatalf("the new spectator link resolves to event %d, %v", eventID, err)
+	}
+}
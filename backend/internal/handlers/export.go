@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+//go:embed templates/draft_export.html
+var exportTemplates embed.FS
+
+// draftExportTemplate renders the printable HTML export
+var draftExportTemplate = template.Must(template.ParseFS(exportTemplates, "templates/draft_export.html"))
+
+// ExportHandler handles exporting completed drafts
+type ExportHandler struct {
+	draftResultRepo *repository.DraftResultRepository
+	eventRepo       *repository.EventRepository
+	userRepo        *repository.UserRepository
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(draftResultRepo *repository.DraftResultRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository) *ExportHandler {
+	return &ExportHandler{
+		draftResultRepo: draftResultRepo,
+		eventRepo:       eventRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// exportPick is one row of an export
+type exportPick struct {
+	PickNumber int    `json:"pickNumber"`
+	Round      int    `json:"round"`
+	UserID     int    `json:"userID"`
+	Team       string `json:"team"`
+	PlayerID   int    `json:"playerID"`
+	Player     string `json:"player"`
+	Country    string `json:"country"`
+	Amateur    bool   `json:"amateur"`
+	AutoDraft  bool   `json:"autoDraft"`
+}
+
+// exportTeam is a team's roster summary
+type exportTeam struct {
+	UserID         int          `json:"userID"`
+	TeamName       string       `json:"teamName"`
+	Picks          []exportPick `json:"picks"`
+	AmateurCount   int          `json:"amateurCount"`
+	AutoDraftCount int          `json:"autoDraftCount"`
+}
+
+// draftExport is everything included in an export
+type draftExport struct {
+	Event *models.Event `json:"event"`
+	Picks []exportPick  `json:"picks"`
+	Teams []exportTeam  `json:"teams"`
+	Board *draftBoard   `json:"-"` // HTML only
+}
+
+// ExportDraft handles GET /events/{id}/export?format=csv|json|html
+// Only completed drafts can be exported; format defaults to json
+func (h *ExportHandler) ExportDraft(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" && format != "html" {
+		http.Error(w, `{"error": "format must be csv, json or html"}`, http.StatusBadRequest)
+		return
+	}
+
+	event, board, err := loadDraftBoard(r.Context(), h.draftResultRepo, h.eventRepo, h.userRepo, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if event.Status != models.EventStatusCompleted {
+		http.Error(w, `{"error": "draft is not completed"}`, http.StatusConflict)
+		return
+	}
+
+	export := buildDraftExport(event, board)
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", exportDisposition(event, "csv"))
+		w.WriteHeader(http.StatusOK)
+		if err := writeExportCSV(w, export); err != nil {
+			log.Printf("Failed to write CSV export: %v", err)
+		}
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := draftExportTemplate.Execute(w, export); err != nil {
+			log.Printf("Failed to render HTML export: %v", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", exportDisposition(event, "json"))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(export)
+	}
+}
+
+// buildDraftExport flattens the board into pick rows and per-team rosters,
+// with teams in board (draft) order
+func buildDraftExport(event *models.Event, board *draftBoard) *draftExport {
+	export := &draftExport{
+		Event: event,
+		Picks: []exportPick{},
+		Teams: make([]exportTeam, len(board.Teams)),
+		Board: board,
+	}
+	for i, team := range board.Teams {
+		export.Teams[i] = exportTeam{UserID: team.UserID, TeamName: team.TeamName, Picks: []exportPick{}}
+	}
+
+	for _, round := range board.Rounds {
+		for col, pick := range round.Picks {
+			if pick == nil {
+				continue
+			}
+			row := exportPick{
+				PickNumber: pick.PickNumber,
+				Round:      pick.Round,
+				UserID:     pick.UserID,
+				Team:       pick.TeamName,
+				PlayerID:   pick.Player.ID,
+				Player:     pick.Player.FirstName + " " + pick.Player.LastName,
+				Country:    pick.Player.CountryCode,
+				Amateur:    pick.Player.Status == "amateur",
+				AutoDraft:  pick.IsAutoDraft,
+			}
+			export.Picks = append(export.Picks, row)
+
+			team := &export.Teams[col]
+			team.Picks = append(team.Picks, row)
+			if row.Amateur {
+				team.AmateurCount++
+			}
+			if row.AutoDraft {
+				team.AutoDraftCount++
+			}
+		}
+	}
+
+	// Board rows are by round; exports list picks in pick order
+	slices.SortFunc(export.Picks, func(a, b exportPick) int {
+		return a.PickNumber - b.PickNumber
+	})
+	return export
+}
+
+// writeExportCSV writes the picks table, a blank line, then the roster summary table.
+// Names come from players and teams, so they are escaped with csvText.
+func writeExportCSV(w http.ResponseWriter, export *draftExport) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"Pick", "Round", "Team", "Player", "Country", "Amateur", "Auto Draft"})
+	for _, pick := range export.Picks {
+		cw.Write([]string{
+			strconv.Itoa(pick.PickNumber),
+			strconv.Itoa(pick.Round),
+			csvText(pick.Team),
+			csvText(pick.Player),
+			csvText(pick.Country),
+			yesNo(pick.Amateur),
+			yesNo(pick.AutoDraft),
+		})
+	}
+
+	cw.Write(nil)
+	cw.Write([]string{"Team", "Picks", "Amateurs", "Auto Drafts", "Roster"})
+	for _, team := range export.Teams {
+		players := make([]string, len(team.Picks))
+		for i, pick := range team.Picks {
+			players[i] = pick.Player
+		}
+		cw.Write([]string{
+			csvText(team.TeamName),
+			strconv.Itoa(len(team.Picks)),
+			strconv.Itoa(team.AmateurCount),
+			strconv.Itoa(team.AutoDraftCount),
+			csvText(strings.Join(players, "; ")),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText keeps a spreadsheet from running a cell as a formula: text starting
+// with =, +, -, @, a tab or a carriage return is prefixed with a quote
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// exportDisposition returns a Content-Disposition header that downloads the
+// export as "<event-name>-draft.<ext>"
+func exportDisposition(event *models.Event, ext string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, event.Name)
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "event-" + strconv.Itoa(event.ID)
+	}
+	return fmt.Sprintf(`attachment; filename="%s-draft.%s"`, slug, ext)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
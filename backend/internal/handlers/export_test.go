@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+func TestCSVExportEscapesFormulas(t *testing.T) {
+	event := &models.Event{ID: 1, Name: "Test Event", MaxPicksPerTeam: 1}
+	users := []models.User{{ID: 1, Username: `=HYPERLINK("http://evil")`}, {ID: 2, Username: "Team 2"}}
+	picks := []models.DraftPickDetail{
+		{PickNumber: 1, Round: 1, UserID: 1, TeamName: `=HYPERLINK("http://evil")`, Player: models.Player{ID: 10, FirstName: "@SUM(A1)", LastName: "Smith", CountryCode: "USA"}},
+		{PickNumber: 2, Round: 1, UserID: 2, TeamName: "Team 2", Player: models.Player{ID: 11, FirstName: "-2+3", LastName: "Jones", CountryCode: "+44"}},
+	}
+	export := buildDraftExport(event, buildDraftBoard(event, users, picks))
+
+	rec := httptest.NewRecorder()
+	if err := writeExportCSV(rec, export); err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(rec.Body)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range rows {
+		for _, cell := range row {
+			if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+				t.Fatalf("cell %q would run as a formula", cell)
+			}
+		}
+	}
+	if team, player := rows[1][2], rows[1][3]; team != `'=HYPERLINK("http://evil")` || player != "'@SUM(A1) Smith" {
+		t.Fatalf("first pick exported as team %q, player %q", team, player)
+	}
+	if player := rows[2][3]; player != "'-2+3 Jones" {
+		t.Fatalf("second pick's player exported as %q", player)
+	}
+}
+
+func TestExportRejectsUnknownFormats(t *testing.T) {
+	h := &ExportHandler{}
+	rec := serve(t, h.ExportDraft, http.MethodGet, "/events/{id}/export", "/events/1/export?format=xml", nil, "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	pool := dbtest.New(t)
+	events := repository.NewEventRepository(pool)
+	h := NewExportHandler(repository.NewDraftResultRepository(pool), events, repository.NewUserRepository(pool))
+	event := createEvent(t, pool, "dye")
+	path := "/events/" + strconv.Itoa(event.ID) + "/export"
+
+	if rec := serve(t, h.ExportDraft, http.MethodGet, "/events/{id}/export", path, nil, ""); rec.Code != http.StatusConflict {
+		t.Fatalf("exporting a draft that isn't completed: status %d, want 409", rec.Code)
+	}
+	if err := events.UpdateStatus(context.Background(), event.ID, models.EventStatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query       string
+		contentType string
+		check       func(body string) bool
+	}{
+		{query: "", contentType: "application/json", check: func(body string) bool {
+			var export draftExport
+			return json.Unmarshal([]byte(body), &export) == nil && export.Event.ID == event.ID
+		}},
+		{query: "?format=csv", contentType: "text/csv; charset=utf-8", check: func(body string) bool {
+			return strings.HasPrefix(body, "Pick,Round,Team,Player,Country,Amateur,Auto Draft\n")
+		}},
+		{query: "?format=html", contentType: "text/html; charset=utf-8", check: func(body string) bool {
+			return strings.Contains(body, "Test Event")
+		}},
+	}
+	for _, tt := range tests {
+		rec := serve(t, h.ExportDraft, http.MethodGet, "/events/{id}/export", path+tt.query, nil, "")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != tt.contentType || !tt.check(rec.Body.String()) {
+			t.Fatalf("export%s: status %d, %s: %s", tt.query, rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+		}
+	}
+}
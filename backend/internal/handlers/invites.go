@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// Invite defaults when the request doesn't specify them
+const (
+	defaultInviteExpiry  = 72 * time.Hour
+	defaultInviteMaxUses = 1
+)
+
+// InviteHandler handles HTTP endpoints for event invite links
+type InviteHandler struct {
+	inviteRepo *repository.InviteRepository
+	eventRepo  *repository.EventRepository
+	signer     *auth.Signer
+}
+
+// NewInviteHandler creates a new InviteHandler
+func NewInviteHandler(inviteRepo *repository.InviteRepository, eventRepo *repository.EventRepository, signer *auth.Signer) *InviteHandler {
+	return &InviteHandler{
+		inviteRepo: inviteRepo,
+		eventRepo:  eventRepo,
+		signer:     signer,
+	}
+}
+
+// CreateInvite handles POST /events/{id}/invites (commissioner only)
+// Accepts: {"teamName": "Team Alpha", "expiresInHours": 48, "maxUses": 1} (all optional)
+// The token is only returned here; the database stores its hash.
+func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		TeamName       string `json:"teamName"`
+		ExpiresInHours int    `json:"expiresInHours"`
+		MaxUses        int    `json:"maxUses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if body.ExpiresInHours < 0 || body.MaxUses < 0 {
+		http.Error(w, `{"error": "expiresInHours and maxUses must be positive"}`, http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepo.GetByID(r.Context(), eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if event.Status == models.EventStatusCompleted {
+		http.Error(w, `{"error": "event is already completed"}`, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.signer.NewToken()
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	expiry := defaultInviteExpiry
+	if body.ExpiresInHours > 0 {
+		expiry = time.Duration(body.ExpiresInHours) * time.Hour
+	}
+
+	invite := &models.Invite{
+		EventID:   eventID,
+		TokenHash: auth.HashSecret(token),
+		MaxUses:   defaultInviteMaxUses,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if body.MaxUses > 0 {
+		invite.MaxUses = body.MaxUses
+	}
+	if teamName := strings.TrimSpace(body.TeamName); teamName != "" {
+		invite.TeamName = &teamName
+	}
+
+	if err := h.inviteRepo.Create(r.Context(), invite); err != nil {
+		http.Error(w, `{"error": "failed to create invite"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		*models.Invite
+		Token string `json:"token"`
+	}{invite, token})
+}
+
+// GetInvite handles GET /invites/{token}
+// Returns what the invite grants so the join page can show it before redeeming
+func (h *InviteHandler) GetInvite(w http.ResponseWriter, r *http.Request) {
+	invite, err := lookupInvite(r.Context(), h.inviteRepo, h.signer, chi.URLParam(r, "token"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "invite not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if !inviteUsable(invite) {
+		http.Error(w, `{"error": "invite has expired or already been used"}`, http.StatusGone)
+		return
+	}
+
+	event, err := h.eventRepo.GetByID(r.Context(), invite.EventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"eventID":       event.ID,
+		"eventName":     event.Name,
+		"teamName":      invite.TeamName,
+		"expiresAt":     invite.ExpiresAt,
+		"remainingUses": invite.MaxUses - invite.UseCount,
+	})
+}
+
+// lookupInvite verifies an invite token's signature and loads the invite
+// Returns pgx.ErrNoRows for forged or unknown tokens
+func lookupInvite(ctx context.Context, repo *repository.InviteRepository, signer *auth.Signer, token string) (*models.Invite, error) {
+	if !signer.Verify(token) {
+		return nil, pgx.ErrNoRows
+	}
+	return repo.GetByTokenHash(ctx, auth.HashSecret(token))
+}
+
+// inviteUsable reports whether an invite can still register a new team
+func inviteUsable(invite *models.Invite) bool {
+	return invite.UseCount < invite.MaxUses && time.Now().Before(invite.ExpiresAt)
+}
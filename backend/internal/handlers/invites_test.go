@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+func TestCreateInviteRequiresAdminKey(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	h := &InviteHandler{}
+
+	for _, bearer := range []string{"", "wrong-key"} {
+		rec := serve(t, h.CreateInvite, http.MethodPost, "/events/{id}/invites", "/events/1/invites", map[string]any{}, bearer)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("with bearer %q: status %d, want 401", bearer, rec.Code)
+		}
+	}
+}
+
+func TestInviteRegistersOneTeamPerUse(t *testing.T) {
+	t.Setenv("ADMIN_KEY", testAdminKey)
+	pool := dbtest.New(t)
+	signer := auth.NewSigner([]byte("test-invite-key"))
+	invites := NewInviteHandler(repository.NewInviteRepository(pool), repository.NewEventRepository(pool), signer)
+	rooms := newDraftRoomHandler(pool)
+	event := createEvent(t, pool, "dye")
+
+	rec := serve(t, invites.CreateInvite, http.MethodPost, "/events/{id}/invites", "/events/"+strconv.Itoa(event.ID)+"/invites", map[string]any{"maxUses": 1}, testAdminKey)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating an invite: status %d: %s", rec.Code, rec.Body)
+	}
+	var invite struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	json.NewDecoder(rec.Body).Decode(&invite)
+
+	join := func(teamName string) int {
+		body := map[string]string{"teamName": teamName, "inviteToken": invite.Token}
+		return serve(t, rooms.JoinEvent, http.MethodPost, "/events/join", "/events/join", body, "").Code
+	}
+	if code := join("Team 1"); code != http.StatusCreated {
+		t.Fatalf("first team: status %d, want 201", code)
+	}
+	if code := join("Team 2"); code != http.StatusGone {
+		t.Fatalf("second team on a single-use invite: status %d, want 410", code)
+	}
+
+	var useCount int
+	pool.QueryRow(context.Background(), `SELECT use_count FROM invites WHERE id = $1`, invite.ID).Scan(&useCount)
+	if useCount != 1 {
+		t.Fatalf("use_count = %d, want 1", useCount)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// MockDraftHandler handles practice drafts against bot teams
+type MockDraftHandler struct {
+	eventRepo       *repository.EventRepository
+	eventPlayerRepo *repository.EventPlayerRepository
+	userRepo        *repository.UserRepository
+	mockDrafts      *draft.MockDrafts
+}
+
+// NewMockDraftHandler creates a new MockDraftHandler
+func NewMockDraftHandler(eventRepo *repository.EventRepository, eventPlayerRepo *repository.EventPlayerRepository, userRepo *repository.UserRepository, mockDrafts *draft.MockDrafts) *MockDraftHandler {
+	return &MockDraftHandler{
+		eventRepo:       eventRepo,
+		eventPlayerRepo: eventPlayerRepo,
+		userRepo:        userRepo,
+		mockDrafts:      mockDrafts,
+	}
+}
+
+// CreateMockDraft handles POST /events/{id}/mock-drafts
+// Starts a mock draft of the event's players against bot teams for the team
+// whose member or rejoin token is the bearer token. Nothing is saved, and the
+// event's status is left alone.
+func (h *MockDraftHandler) CreateMockDraft(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	team := h.authenticatedTeam(r)
+	if team == nil || team.EventID != eventID {
+		http.Error(w, `{"error": "team authorization required", "code": "FORBIDDEN"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Teams       int    `json:"teams"`       // Defaults to draft.DefaultMockTeams
+		Rounds      int    `json:"rounds"`      // Defaults to the event's max picks per team
+		Position    int    `json:"position"`    // Defaults to a random slot
+		BotStrategy string `json:"botStrategy"` // Defaults to a mix
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepo.GetByID(r.Context(), eventID)
+	if err == pgx.ErrNoRows {
+		http.Error(w, `{"error": "Event not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Failed to get event"}`, http.StatusInternalServerError)
+		return
+	}
+	if req.Rounds == 0 {
+		req.Rounds = event.MaxPicksPerTeam
+	}
+
+	players, err := h.eventPlayerRepo.GetPlayersByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to get players"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if len(players) == 0 {
+		http.Error(w, `{"error": "No players assigned to this event"}`, http.StatusBadRequest)
+		return
+	}
+
+	mock, err := h.mockDrafts.Create(eventID, event.MaxPicksPerTeam, players, event.Stipulations, team.ID, draft.MockDraftOptions{
+		Teams:       req.Teams,
+		Rounds:      req.Rounds,
+		Position:    req.Position,
+		BotStrategy: req.BotStrategy,
+	})
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mock)
+}
+
+// DeleteMockDraft handles DELETE /mock-drafts/{mockID}
+// Ends a mock draft early, disconnecting its team
+func (h *MockDraftHandler) DeleteMockDraft(w http.ResponseWriter, r *http.Request) {
+	if !h.mockDrafts.Close(chi.URLParam(r, "mockID")) {
+		http.Error(w, `{"error": "Mock draft not found"}`, http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RouteMockDrafts sends GET /ws/draft?mockID= connections to that mock draft's
+// room, which speaks the same protocol, so the draft room UI connects to a mock
+// draft by adding the parameter. Other connections go on to the draft room.
+func (h *MockDraftHandler) RouteMockDrafts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mockID := r.URL.Query().Get("mockID"); mockID != "" {
+			h.mockDrafts.HandleWebSocket(w, r, mockID)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticatedTeam returns the team whose member or rejoin token is the
+// request's bearer token, or nil if it's neither
+func (h *MockDraftHandler) authenticatedTeam(r *http.Request) *models.User {
+	token := bearerToken(r)
+	if token == "" {
+		return nil
+	}
+	if user, _, err := h.userRepo.GetUserByMemberToken(r.Context(), token); err == nil {
+		return user
+	}
+	if user, err := h.userRepo.GetUserByRejoinToken(r.Context(), token); err == nil {
+		return user
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+func TestDraftSocketRoutesMockIDsToMockDrafts(t *testing.T) {
+	h := &MockDraftHandler{mockDrafts: draft.NewMockDrafts(draft.DefaultConfig(), nil)}
+	router := chi.NewRouter()
+	router.With(h.RouteMockDrafts).Get("/ws/draft", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot) // Stands in for the draft room
+	})
+
+	for path, want := range map[string]int{
+		"/ws/draft?protocol=2":                http.StatusTeapot,
+		"/ws/draft?mockID=unknown&protocol=2": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Fatalf("GET %s: status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestCreateMockDraftRequiresTeamToken(t *testing.T) {
+	h := &MockDraftHandler{}
+	rec := serve(t, h.CreateMockDraft, http.MethodPost, "/events/{id}/mock-drafts", "/events/1/mock-drafts", map[string]int{}, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without a token: status %d, want 401", rec.Code)
+	}
+}
+
+func TestMockDraftIsForTheTokensTeam(t *testing.T) {
+	pool := dbtest.New(t)
+	ctx := context.Background()
+	event := createEvent(t, pool, "dye")
+	createEvent(t, pool, "gator")
+
+	var players []int
+	for _, name := range []string{"Paul", "Nate"} {
+		player := &models.Player{FirstName: name, LastName: "Test", Status: "professional"}
+		if err := repository.NewPlayerRepository(pool).Create(ctx, player); err != nil {
+			t.Fatal(err)
+		}
+		players = append(players, player.ID)
+	}
+	if err := repository.NewEventPlayerRepository(pool).AddPlayersToEvent(ctx, event.ID, players); err != nil {
+		t.Fatal(err)
+	}
+
+	join := func(passkey string) joinResponse {
+		t.Helper()
+		rec := serve(t, newDraftRoomHandler(pool).JoinEvent, http.MethodPost, "/events/join", "/events/join",
+			map[string]string{"teamName": "Team " + passkey, "passkey": passkey}, "")
+		var resp joinResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.MemberToken == "" {
+			t.Fatalf("joining with %s: status %d, %v", passkey, rec.Code, err)
+		}
+		return resp
+	}
+	team := join("dye")
+	outsider := join("gator")
+
+	users := repository.NewUserRepository(pool)
+	mocks := draft.NewMockDrafts(draft.DefaultConfig(), users)
+	h := NewMockDraftHandler(repository.NewEventRepository(pool), repository.NewEventPlayerRepository(pool), users, mocks)
+	path := "/events/" + strconv.Itoa(event.ID) + "/mock-drafts"
+	create := func(bearer string) *httptest.ResponseRecorder {
+		return serve(t, h.CreateMockDraft, http.MethodPost, "/events/{id}/mock-drafts", path, map[string]int{"teams": 2, "rounds": 1}, bearer)
+	}
+
+	if rec := create(outsider.MemberToken); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("with another event's team: status %d, want 401", rec.Code)
+	}
+	rec := create(team.RejoinToken)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("with the team's rejoin token: status %d: %s", rec.Code, rec.Body)
+	}
+	var mock draft.MockDraft
+	if err := json.NewDecoder(rec.Body).Decode(&mock); err != nil || mock.UserID != team.ID {
+		t.Fatalf("mock draft %+v, %v; want it for user %d", mock, err, team.ID)
+	}
+	t.Cleanup(func() { mocks.Close(mock.ID) })
+
+	if rec := create(team.MemberToken); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("a second mock draft for the team: status %d, want 429", rec.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/draft"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+	"github.com/sblackwood23/fantasy-draft-app/internal/repository"
+)
+
+// ReplayHandler handles playing back completed drafts
+type ReplayHandler struct {
+	draftResultRepo *repository.DraftResultRepository
+	eventRepo       *repository.EventRepository
+	eventPlayerRepo *repository.EventPlayerRepository
+	draftService    *draft.DraftService
+}
+
+// NewReplayHandler creates a new ReplayHandler
+func NewReplayHandler(draftResultRepo *repository.DraftResultRepository, eventRepo *repository.EventRepository, eventPlayerRepo *repository.EventPlayerRepository, draftService *draft.DraftService) *ReplayHandler {
+	return &ReplayHandler{
+		draftResultRepo: draftResultRepo,
+		eventRepo:       eventRepo,
+		eventPlayerRepo: eventPlayerRepo,
+		draftService:    draftService,
+	}
+}
+
+// Replay handles GET /events/{id}/replay?speed=1|5|20 (WebSocket)
+// Plays a completed draft back to the connecting client; speed defaults to 1
+func (h *ReplayHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid event ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	speed := 1
+	if speedStr := r.URL.Query().Get("speed"); speedStr != "" {
+		speed, err = strconv.Atoi(speedStr)
+		if err != nil || !slices.Contains(draft.ReplaySpeeds, speed) {
+			http.Error(w, `{"error": "speed must be 1, 5 or 20"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	event, err := h.eventRepo.GetByID(r.Context(), eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, `{"error": "event not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if event.Status != models.EventStatusCompleted {
+		http.Error(w, `{"error": "draft is not completed"}`, http.StatusConflict)
+		return
+	}
+
+	picks, err := h.draftResultRepo.GetByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if len(picks) == 0 {
+		http.Error(w, `{"error": "draft has no picks to replay"}`, http.StatusConflict)
+		return
+	}
+
+	playerIDs, err := h.eventPlayerRepo.GetPlayerIDsByEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	replay := &draft.Replay{
+		EventID:   eventID,
+		PlayerIDs: playerIDs,
+		Picks:     picks,
+	}
+	if event.StartedAt != nil {
+		replay.StartedAt = *event.StartedAt
+	}
+
+	h.draftService.HandleReplay(w, r, replay, speed)
+}
@@ -21,7 +21,7 @@ type Event struct {
 	MaxTeamsPerPlayer int          `json:"maxTeamsPerPlayer"`
 	Stipulations      Stipulations `json:"stipulations"`
 	Status            string       `json:"status"`
-	Passkey           *string      `json:"passkey,omitempty"`
+	Passkey           *string      `json:"passkey,omitempty"` // Write-only: stored hashed, never returned
 	EventDate         *time.Time   `json:"eventDate,omitempty"`
 	CreatedAt         time.Time    `json:"createdAt"`
 	StartedAt         *time.Time   `json:"startedAt,omitempty"`
@@ -56,6 +56,7 @@ type Player struct {
 	LastName    string `json:"lastName"`
 	Status      string `json:"status"`
 	CountryCode string `json:"countryCode"`
+	Ranking     *int   `json:"ranking,omitempty"` // World ranking, 1 is best; nil if unranked
 }
 
 // User represents a team/participant in the draft
@@ -64,6 +65,18 @@ type User struct {
 	EventID   int       `json:"eventID"`
 	Username  string    `json:"username"`
 	CreatedAt time.Time `json:"createdAt"`
+
+	RejoinTokenHash *string `json:"-"` // Hash of the secret token required to rejoin as this team
+}
+
+// TeamMember represents a person managing a team; a team may have several co-managers
+type TeamMember struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	TokenHash *string `json:"-"` // Hash of the member's own token for connecting to the draft room
 }
 
 // DraftResult represents a pick made during a draft
@@ -75,5 +88,65 @@ type DraftResult struct {
 	PickNumber  int       `json:"pickNumber"`
 	Round       int       `json:"round"`
 	IsAutoDraft bool      `json:"isAutoDraft"`
-	CreatedAt   time.Time `json:"createdAt"`
+	CreatedAt   time.Time `json:"createdAt"` // When the pick was made
+
+	// The auto-draft strategy that chose the player; empty for picks the team
+	// made and auto-drafts recorded before strategies were stored
+	AutoDraftStrategy string `json:"autoDraftStrategy,omitempty"`
+
+	// When the pick's turn started, excluding time spent paused, and the deadline
+	// it was made against. Nil for picks recorded before these were stored.
+	TurnStartedAt *time.Time `json:"turnStartedAt,omitempty"`
+	TurnDeadline  *time.Time `json:"turnDeadline,omitempty"`
+}
+
+// DraftPickDetail is a draft result joined with its team and player
+type DraftPickDetail struct {
+	PickNumber  int       `json:"pickNumber"`
+	Round       int       `json:"round"`
+	UserID      int       `json:"userID"`
+	TeamName    string    `json:"teamName"`
+	Player      Player    `json:"player"`
+	IsAutoDraft bool      `json:"isAutoDraft"`
+	PickedAt    time.Time `json:"pickedAt"`
+
+	AutoDraftStrategy string `json:"autoDraftStrategy,omitempty"`
+
+	TurnStartedAt *time.Time `json:"turnStartedAt,omitempty"`
+	TurnDeadline  *time.Time `json:"turnDeadline,omitempty"`
+}
+
+// Invite represents a per-person invite link to join an event
+type Invite struct {
+	ID        int       `json:"id"`
+	EventID   int       `json:"eventID"`
+	TeamName  *string   `json:"teamName,omitempty"` // If set, the invite can only register this team
+	MaxUses   int       `json:"maxUses"`
+	UseCount  int       `json:"useCount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	TokenHash string `json:"-"`
+}
+
+// ChatMessage represents a chat message sent in a draft room
+type ChatMessage struct {
+	ID         int       `json:"id"`
+	EventID    int       `json:"eventID"`
+	UserID     int       `json:"userID"`
+	MemberID   *int      `json:"memberID,omitempty"`
+	SenderName string    `json:"senderName"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// DraftEvent is one entry in an event's append-only draft log. Seq numbers each
+// event's entries from 1; Payload is the JSON body for the entry's Type.
+type DraftEvent struct {
+	ID        int64           `json:"id"`
+	EventID   int             `json:"eventID"`
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"` // When the change happened
 }
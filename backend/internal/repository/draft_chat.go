@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+type DraftChatRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDraftChatRepository(pool *pgxpool.Pool) *DraftChatRepository {
+	return &DraftChatRepository{pool: pool}
+}
+
+// SaveChatMessage inserts a chat message (implements draft.ChatStore interface)
+func (r *DraftChatRepository) SaveChatMessage(ctx context.Context, msg *models.ChatMessage) error {
+	query := `
+		INSERT INTO draft_chat (event_id, user_id, member_id, sender_name, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		msg.EventID,
+		msg.UserID,
+		msg.MemberID,
+		msg.SenderName,
+		msg.Body,
+	).Scan(&msg.ID, &msg.CreatedAt)
+
+	return err
+}
+
+// GetRecentChatMessages returns the most recent chat messages for an event, oldest first
+func (r *DraftChatRepository) GetRecentChatMessages(ctx context.Context, eventID, limit int) ([]models.ChatMessage, error) {
+	query := `
+		SELECT id, event_id, user_id, member_id, sender_name, body, created_at
+		FROM draft_chat
+		WHERE event_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, eventID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		var msg models.ChatMessage
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.EventID,
+			&msg.UserID,
+			&msg.MemberID,
+			&msg.SenderName,
+			&msg.Body,
+			&msg.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	slices.Reverse(messages)
+	return messages, nil
+}
+
+// DeleteChatMessage removes a chat message from an event
+func (r *DraftChatRepository) DeleteChatMessage(ctx context.Context, eventID, id int) error {
+	query := `DELETE FROM draft_chat WHERE event_id = $1 AND id = $2`
+
+	commandTag, err := r.pool.Exec(ctx, query, eventID, id)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// DraftEventError is a log entry the database refuses to store. Saving it again can't succeed.
+type DraftEventError struct {
+	Message string
+}
+
+func (e *DraftEventError) Error() string {
+	return e.Message
+}
+
+// Permanent tells the draft engine not to retry the entry (see draft.EventStore)
+func (e *DraftEventError) Permanent() bool {
+	return true
+}
+
+// ErrDraftEventConflict is returned when a different entry is already stored under the seq
+var ErrDraftEventConflict = &DraftEventError{Message: "a different draft event is already stored for this seq"}
+
+type DraftEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDraftEventRepository(pool *pgxpool.Pool) *DraftEventRepository {
+	return &DraftEventRepository{pool: pool}
+}
+
+// AppendDraftEvent appends an entry to an event's draft log (implements draft.EventStore
+// interface). It's idempotent on (event_id, seq): appending an entry that's already
+// stored succeeds, and ErrDraftEventConflict is returned if a different one is.
+func (r *DraftEventRepository) AppendDraftEvent(ctx context.Context, event *models.DraftEvent) error {
+	payload := event.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO draft_events (event_id, seq, type, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id, seq) DO NOTHING
+		RETURNING id
+	`,
+		event.EventID,
+		event.Seq,
+		event.Type,
+		payload,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != pgx.ErrNoRows {
+		return err
+	}
+
+	// Already stored: fine if it's this entry being retried
+	var same bool
+	err = r.pool.QueryRow(ctx, `
+		SELECT id, type = $3 AND payload = $4::jsonb
+		FROM draft_events
+		WHERE event_id = $1 AND seq = $2
+	`, event.EventID, event.Seq, event.Type, payload).Scan(&event.ID, &same)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return ErrDraftEventConflict
+	}
+	return nil
+}
+
+// GetByEvent returns an event's draft log in seq order
+func (r *DraftEventRepository) GetByEvent(ctx context.Context, eventID int) ([]models.DraftEvent, error) {
+	query := `
+		SELECT id, event_id, seq, type, payload, created_at
+		FROM draft_events
+		WHERE event_id = $1
+		ORDER BY seq
+	`
+
+	rows, err := r.pool.Query(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.DraftEvent{}
+	for rows.Next() {
+		var event models.DraftEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.EventID,
+			&event.Seq,
+			&event.Type,
+			&event.Payload,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -2,11 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
+// PickError is a pick the database refuses to store. Saving it again can't succeed.
+// Reason is a stable code for the rule the pick broke.
+type PickError struct {
+	Reason  string
+	Message string
+}
+
+func (e *PickError) Error() string {
+	return e.Message
+}
+
+// Permanent tells the draft engine not to retry the pick (see draft.PickSaver)
+func (e *PickError) Permanent() bool {
+	return true
+}
+
+// PickReason returns the code of the rule the pick broke
+func (e *PickError) PickReason() string {
+	return e.Reason
+}
+
 type DraftResultRepository struct {
 	pool *pgxpool.Pool
 }
@@ -15,42 +40,182 @@ func NewDraftResultRepository(pool *pgxpool.Pool) *DraftResultRepository {
 	return &DraftResultRepository{pool: pool}
 }
 
-// SavePick inserts a pick into the database (implements draft.PickSaver interface)
-func (r *DraftResultRepository) SavePick(ctx context.Context, eventID, userID, playerID, pickNumber, round int, isAutoDraft bool) error {
-	_, err := r.Create(ctx, eventID, userID, playerID, pickNumber, round, isAutoDraft)
+// Errors returned by SavePick for picks that break the draft's rules
+var (
+	ErrPickEventNotFound   = &PickError{Reason: "EVENT_NOT_FOUND", Message: "event not found"}
+	ErrPickConflict        = &PickError{Reason: "PICK_CONFLICT", Message: "a different pick is already stored for this pick number"}
+	ErrPickOutOfSequence   = &PickError{Reason: "PICK_OUT_OF_SEQUENCE", Message: "pick number does not follow the last stored pick"}
+	ErrPickTeamNotInEvent  = &PickError{Reason: "TEAM_NOT_IN_EVENT", Message: "team is not part of this event"}
+	ErrPickPlayerNotInPool = &PickError{Reason: "PLAYER_NOT_IN_EVENT", Message: "player is not in this event's player pool"}
+	ErrPickTeamFull        = &PickError{Reason: "TEAM_FULL", Message: "team already has the maximum picks for this event"}
+	ErrPickPlayerTaken     = &PickError{Reason: "PLAYER_TAKEN", Message: "player is already on the maximum number of teams"}
+	ErrPickDuplicate       = &PickError{Reason: "DUPLICATE_PLAYER", Message: "team already has this player"}
+)
+
+// SavePick validates and inserts a pick in one transaction (implements draft.PickSaver
+// interface). The event row is locked, so picks for an event are validated one at a
+// time against the stored ones: the pick number must be the next in sequence, the team
+// and player must belong to the event, the team must have picks left and the player
+// must be on fewer than max_teams_per_player teams.
+//
+// It's idempotent on (event_id, pick_number): saving a pick that's already stored
+// succeeds, so a retry after a lost response is safe. Rule violations return a
+// *PickError; other errors are database failures worth retrying.
+func (r *DraftResultRepository) SavePick(ctx context.Context, pick *models.DraftResult) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var maxPicksPerTeam, maxTeamsPerPlayer int
+	err = tx.QueryRow(ctx, `
+		SELECT max_picks_per_team, max_teams_per_player
+		FROM events
+		WHERE id = $1
+		FOR UPDATE
+	`, pick.EventID).Scan(&maxPicksPerTeam, &maxTeamsPerPlayer)
+	if err == pgx.ErrNoRows {
+		return ErrPickEventNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// A pick already stored under this number is either this pick being retried or a conflict
+	var existing models.DraftResult
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id, player_id
+		FROM draft_results
+		WHERE event_id = $1 AND pick_number = $2
+	`, pick.EventID, pick.PickNumber).Scan(&existing.ID, &existing.UserID, &existing.PlayerID)
+	switch {
+	case err == nil && existing.UserID == pick.UserID && existing.PlayerID == pick.PlayerID:
+		pick.ID = existing.ID
+		return nil
+	case err == nil:
+		return ErrPickConflict
+	case err != pgx.ErrNoRows:
+		return err
+	}
+
+	var (
+		storedPicks, teamPicks, playerTeams int
+		teamEventID                         *int
+		playerInPool, teamHasPlayer         bool
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM draft_results WHERE event_id = $1),
+			(SELECT COUNT(*) FROM draft_results WHERE event_id = $1 AND user_id = $2),
+			(SELECT COUNT(*) FROM draft_results WHERE event_id = $1 AND player_id = $3),
+			(SELECT event_id FROM users WHERE id = $2),
+			EXISTS (SELECT 1 FROM event_players WHERE event_id = $1 AND player_id = $3),
+			EXISTS (SELECT 1 FROM draft_results WHERE event_id = $1 AND user_id = $2 AND player_id = $3)
+	`, pick.EventID, pick.UserID, pick.PlayerID).Scan(
+		&storedPicks,
+		&teamPicks,
+		&playerTeams,
+		&teamEventID,
+		&playerInPool,
+		&teamHasPlayer,
+	)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case pick.PickNumber != storedPicks+1:
+		return ErrPickOutOfSequence
+	case teamEventID == nil || *teamEventID != pick.EventID:
+		return ErrPickTeamNotInEvent
+	case !playerInPool:
+		return ErrPickPlayerNotInPool
+	case teamHasPlayer:
+		return ErrPickDuplicate
+	case teamPicks >= maxPicksPerTeam:
+		return ErrPickTeamFull
+	case playerTeams >= maxTeamsPerPlayer:
+		return ErrPickPlayerTaken
+	}
+
+	if pick.CreatedAt.IsZero() {
+		pick.CreatedAt = time.Now()
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO draft_results (event_id, user_id, player_id, pick_number, round, is_auto_draft, auto_draft_strategy, created_at, turn_started_at, turn_deadline)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
+		RETURNING id
+	`,
+		pick.EventID,
+		pick.UserID,
+		pick.PlayerID,
+		pick.PickNumber,
+		pick.Round,
+		pick.IsAutoDraft,
+		pick.AutoDraftStrategy,
+		pick.CreatedAt,
+		pick.TurnStartedAt,
+		pick.TurnDeadline,
+	).Scan(&pick.ID)
+	if err != nil {
+		return mapPickConflict(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// mapPickConflict converts a violation of the draft_results unique constraints into
+// the matching *PickError. The event lock makes these unreachable through SavePick,
+// but they back its checks against any other writer.
+func mapPickConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "draft_results_event_pick_number_key":
+		return ErrPickConflict
+	case "draft_results_event_id_user_id_player_id_key":
+		return ErrPickDuplicate
+	}
 	return err
 }
 
-// Create inserts a new draft result (pick) into the database
-func (r *DraftResultRepository) Create(ctx context.Context, eventID, userID, playerID, pickNumber, round int, isAutoDraft bool) (*models.DraftResult, error) {
+// Create inserts a new draft result (pick) into the database, setting its ID.
+// A zero CreatedAt is set to the current time. The pick isn't validated; the draft saves
+// picks with SavePick.
+func (r *DraftResultRepository) Create(ctx context.Context, result *models.DraftResult) error {
 	query := `
-		INSERT INTO draft_results (event_id, user_id, player_id, pick_number, round, is_auto_draft)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, event_id, user_id, player_id, pick_number, round, is_auto_draft, created_at
+		INSERT INTO draft_results (event_id, user_id, player_id, pick_number, round, is_auto_draft, auto_draft_strategy, created_at, turn_started_at, turn_deadline)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
+		RETURNING id
 	`
 
-	var result models.DraftResult
-	err := r.pool.QueryRow(ctx, query, eventID, userID, playerID, pickNumber, round, isAutoDraft).Scan(
-		&result.ID,
-		&result.EventID,
-		&result.UserID,
-		&result.PlayerID,
-		&result.PickNumber,
-		&result.Round,
-		&result.IsAutoDraft,
-		&result.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
 	}
 
-	return &result, nil
+	return r.pool.QueryRow(ctx, query,
+		result.EventID,
+		result.UserID,
+		result.PlayerID,
+		result.PickNumber,
+		result.Round,
+		result.IsAutoDraft,
+		result.AutoDraftStrategy,
+		result.CreatedAt,
+		result.TurnStartedAt,
+		result.TurnDeadline,
+	).Scan(&result.ID)
 }
 
 // GetByEvent returns all draft results for a given event
 func (r *DraftResultRepository) GetByEvent(ctx context.Context, eventID int) ([]models.DraftResult, error) {
 	query := `
-		SELECT id, event_id, user_id, player_id, pick_number, round, is_auto_draft, created_at
+		SELECT id, event_id, user_id, player_id, pick_number, round, is_auto_draft,
+			COALESCE(auto_draft_strategy, ''), created_at, turn_started_at, turn_deadline
 		FROM draft_results
 		WHERE event_id = $1
 		ORDER BY pick_number
@@ -73,7 +238,10 @@ func (r *DraftResultRepository) GetByEvent(ctx context.Context, eventID int) ([]
 			&result.PickNumber,
 			&result.Round,
 			&result.IsAutoDraft,
+			&result.AutoDraftStrategy,
 			&result.CreatedAt,
+			&result.TurnStartedAt,
+			&result.TurnDeadline,
 		); err != nil {
 			return nil, err
 		}
@@ -83,10 +251,62 @@ func (r *DraftResultRepository) GetByEvent(ctx context.Context, eventID int) ([]
 	return results, nil
 }
 
+// GetDetailsByEvent returns an event's picks joined with their teams and players,
+// in pick order. A non-zero round or userID restricts the results to it.
+func (r *DraftResultRepository) GetDetailsByEvent(ctx context.Context, eventID, round, userID int) ([]models.DraftPickDetail, error) {
+	query := `
+		SELECT dr.pick_number, dr.round, dr.user_id, u.username,
+			p.id, p.first_name, p.last_name, p.status, p.country_code,
+			p.ranking, dr.is_auto_draft, COALESCE(dr.auto_draft_strategy, ''),
+			dr.created_at, dr.turn_started_at, dr.turn_deadline
+		FROM draft_results dr
+		JOIN users u ON u.id = dr.user_id
+		JOIN players p ON p.id = dr.player_id
+		WHERE dr.event_id = $1
+			AND ($2 = 0 OR dr.round = $2)
+			AND ($3 = 0 OR dr.user_id = $3)
+		ORDER BY dr.pick_number
+	`
+
+	rows, err := r.pool.Query(ctx, query, eventID, round, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	picks := []models.DraftPickDetail{}
+	for rows.Next() {
+		var pick models.DraftPickDetail
+		if err := rows.Scan(
+			&pick.PickNumber,
+			&pick.Round,
+			&pick.UserID,
+			&pick.TeamName,
+			&pick.Player.ID,
+			&pick.Player.FirstName,
+			&pick.Player.LastName,
+			&pick.Player.Status,
+			&pick.Player.CountryCode,
+			&pick.Player.Ranking,
+			&pick.IsAutoDraft,
+			&pick.AutoDraftStrategy,
+			&pick.PickedAt,
+			&pick.TurnStartedAt,
+			&pick.TurnDeadline,
+		); err != nil {
+			return nil, err
+		}
+		picks = append(picks, pick)
+	}
+
+	return picks, rows.Err()
+}
+
 // GetByEventAndUser returns all draft results for a given event and user
 func (r *DraftResultRepository) GetByEventAndUser(ctx context.Context, eventID, userID int) ([]models.DraftResult, error) {
 	query := `
-		SELECT id, event_id, user_id, player_id, pick_number, round, is_auto_draft, created_at
+		SELECT id, event_id, user_id, player_id, pick_number, round, is_auto_draft,
+			COALESCE(auto_draft_strategy, ''), created_at, turn_started_at, turn_deadline
 		FROM draft_results
 		WHERE event_id = $1 AND user_id = $2
 		ORDER BY pick_number
@@ -109,7 +329,10 @@ func (r *DraftResultRepository) GetByEventAndUser(ctx context.Context, eventID,
 			&result.PickNumber,
 			&result.Round,
 			&result.IsAutoDraft,
+			&result.AutoDraftStrategy,
 			&result.CreatedAt,
+			&result.TurnStartedAt,
+			&result.TurnDeadline,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+func TestSavePickEnforcesTheDraftRules(t *testing.T) {
+	pool := dbtest.New(t)
+	events := NewEventRepository(pool)
+	users := NewUserRepository(pool)
+	picks := NewDraftResultRepository(pool)
+	ctx := context.Background()
+
+	event := createEvent(t, events, "dye", models.EventStatusInProgress)
+	other := createEvent(t, events, "gator", models.EventStatusInProgress)
+	var teams []*models.User
+	for _, user := range []*models.User{
+		{EventID: event.ID, Username: "Team 1"},
+		{EventID: event.ID, Username: "Team 2"},
+		{EventID: other.ID, Username: "Elsewhere"},
+	} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		teams = append(teams, user)
+	}
+	var players []int
+	for _, name := range []string{"Paul", "Nate", "Ryan"} {
+		player := &models.Player{FirstName: name, LastName: "Test", Status: "professional"}
+		if err := NewPlayerRepository(pool).Create(ctx, player); err != nil {
+			t.Fatal(err)
+		}
+		players = append(players, player.ID)
+	}
+	if err := NewEventPlayerRepository(pool).AddPlayersToEvent(ctx, event.ID, players[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	save := func(eventID, pickNumber int, team *models.User, playerID int, want error) *models.DraftResult {
+		t.Helper()
+		pick := &models.DraftResult{EventID: eventID, UserID: team.ID, PlayerID: playerID, PickNumber: pickNumber, Round: 1}
+		if err := picks.SavePick(ctx, pick); err != want {
+			t.Fatalf("saving pick %d of player %d for %s: %v, want %v", pickNumber, playerID, team.Username, err, want)
+		}
+		return pick
+	}
+
+	save(event.ID+other.ID, 1, teams[0], players[0], ErrPickEventNotFound)
+	save(event.ID, 2, teams[0], players[0], ErrPickOutOfSequence)
+	save(event.ID, 1, teams[2], players[0], ErrPickTeamNotInEvent)
+	save(event.ID, 1, teams[0], players[2], ErrPickPlayerNotInPool)
+
+	first := save(event.ID, 1, teams[0], players[0], nil)
+	if retried := save(event.ID, 1, teams[0], players[0], nil); retried.ID != first.ID {
+		t.Fatalf("retry stored pick %d, want %d", retried.ID, first.ID)
+	}
+	save(event.ID, 1, teams[1], players[1], ErrPickConflict)
+	save(event.ID, 2, teams[0], players[0], ErrPickDuplicate)
+	save(event.ID, 2, teams[1], players[0], ErrPickPlayerTaken) // Max one team per player
+	save(event.ID, 2, teams[1], players[1], nil)
+}
+
+func TestSavePickRefusesPicksPastTheTeamLimit(t *testing.T) {
+	pool := dbtest.New(t)
+	picks := NewDraftResultRepository(pool)
+	ctx := context.Background()
+
+	event := &models.Event{Name: "One pick each", MaxPicksPerTeam: 1, MaxTeamsPerPlayer: 1, Status: models.EventStatusInProgress}
+	if err := NewEventRepository(pool).Create(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	team := &models.User{EventID: event.ID, Username: "Team 1"}
+	if err := NewUserRepository(pool).Create(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	var players []int
+	for _, name := range []string{"Paul", "Nate"} {
+		player := &models.Player{FirstName: name, LastName: "Test", Status: "professional"}
+		if err := NewPlayerRepository(pool).Create(ctx, player); err != nil {
+			t.Fatal(err)
+		}
+		players = append(players, player.ID)
+	}
+	if err := NewEventPlayerRepository(pool).AddPlayersToEvent(ctx, event.ID, players); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := picks.SavePick(ctx, &models.DraftResult{EventID: event.ID, UserID: team.ID, PlayerID: players[0], PickNumber: 1, Round: 1}); err != nil {
+		t.Fatalf("first pick: %v", err)
+	}
+	if err := picks.SavePick(ctx, &models.DraftResult{EventID: event.ID, UserID: team.ID, PlayerID: players[1], PickNumber: 2, Round: 2}); err != ErrPickTeamFull {
+		t.Fatalf("second pick: %v, want ErrPickTeamFull", err)
+	}
+}
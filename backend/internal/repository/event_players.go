@@ -15,9 +15,16 @@ func NewEventPlayerRepository(pool *pgxpool.Pool) *EventPlayerRepository {
 	return &EventPlayerRepository{pool: pool}
 }
 
-// GetPlayerIDsByEvent returns all player IDs for a given event
+// GetPlayerIDsByEvent returns all player IDs for a given event, best world
+// ranking first; unranked players come last
 func (r *EventPlayerRepository) GetPlayerIDsByEvent(ctx context.Context, eventID int) ([]int, error) {
-	query := `SELECT player_id FROM event_players WHERE event_id = $1`
+	query := `
+		SELECT ep.player_id
+		FROM event_players ep
+		INNER JOIN players p ON p.id = ep.player_id
+		WHERE ep.event_id = $1
+		ORDER BY p.ranking NULLS LAST, ep.player_id
+	`
 
 	rows, err := r.pool.Query(ctx, query, eventID)
 	if err != nil {
@@ -37,13 +44,15 @@ func (r *EventPlayerRepository) GetPlayerIDsByEvent(ctx context.Context, eventID
 	return playerIDs, nil
 }
 
-// GetPlayersByEvent returns full player objects for a given event
+// GetPlayersByEvent returns full player objects for a given event, best world
+// ranking first; unranked players come last
 func (r *EventPlayerRepository) GetPlayersByEvent(ctx context.Context, eventID int) ([]models.Player, error) {
 	query := `
-		SELECT p.id, p.first_name, p.last_name, p.status, p.country_code
+		SELECT p.id, p.first_name, p.last_name, p.status, p.country_code, p.ranking
 		FROM players p
 		INNER JOIN event_players ep ON p.id = ep.player_id
 		WHERE ep.event_id = $1
+		ORDER BY p.ranking NULLS LAST, p.id
 	`
 
 	rows, err := r.pool.Query(ctx, query, eventID)
@@ -61,6 +70,7 @@ func (r *EventPlayerRepository) GetPlayersByEvent(ctx context.Context, eventID i
 			&player.LastName,
 			&player.Status,
 			&player.CountryCode,
+			&player.Ranking,
 		); err != nil {
 			return nil, err
 		}
@@ -2,13 +2,22 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
+// ErrPasskeyInUse is returned when another active event already uses the same passkey
+var ErrPasskeyInUse = errors.New("passkey already in use by an active event")
+
+// ErrPasskeyRequired is returned when a completed event with a passkey is
+// reopened without the passkey, which is needed to check it's still unique
+var ErrPasskeyRequired = errors.New("reopening a completed event requires its passkey")
+
 type EventRepository struct {
 	pool *pgxpool.Pool
 }
@@ -21,7 +30,7 @@ func NewEventRepository(pool *pgxpool.Pool) *EventRepository {
 func (r *EventRepository) GetByID(ctx context.Context, id int) (*models.Event, error) {
 	query := `
 		SELECT id, name, max_picks_per_team, max_teams_per_player,
-		       stipulations, status, passkey, event_date, created_at, started_at, completed_at
+		       stipulations, status, event_date, created_at, started_at, completed_at
 		FROM events
 		WHERE id = $1
 	`
@@ -34,7 +43,6 @@ func (r *EventRepository) GetByID(ctx context.Context, id int) (*models.Event, e
 		&event.MaxTeamsPerPlayer,
 		&event.Stipulations,
 		&event.Status,
-		&event.Passkey,
 		&event.EventDate,
 		&event.CreatedAt,
 		&event.StartedAt,
@@ -52,7 +60,7 @@ func (r *EventRepository) GetByID(ctx context.Context, id int) (*models.Event, e
 func (r *EventRepository) GetAll(ctx context.Context) ([]models.Event, error) {
 	query := `
 		SELECT id, name, max_picks_per_team, max_teams_per_player,
-		       stipulations, status, passkey, event_date, created_at, started_at, completed_at
+		       stipulations, status, event_date, created_at, started_at, completed_at
 		FROM events
 	`
 
@@ -72,7 +80,6 @@ func (r *EventRepository) GetAll(ctx context.Context) ([]models.Event, error) {
 			&event.MaxTeamsPerPlayer,
 			&event.Stipulations,
 			&event.Status,
-			&event.Passkey,
 			&event.EventDate,
 			&event.CreatedAt,
 			&event.StartedAt,
@@ -90,50 +97,61 @@ func (r *EventRepository) GetAll(ctx context.Context) ([]models.Event, error) {
 // Create new record in events table
 func (r *EventRepository) Create(ctx context.Context, event *models.Event) error {
 	query := `
-    INSERT INTO events (name, max_picks_per_team, max_teams_per_player, stipulations, status, passkey, event_date)
-    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    INSERT INTO events (name, max_picks_per_team, max_teams_per_player, stipulations, status, passkey_hash, event_date)
+    VALUES ($1, $2, $3, $4, $5, crypt($6, gen_salt('bf', 10)), $7)
     RETURNING id, created_at
 `
-	err := r.pool.QueryRow(ctx, query,
-		event.Name,
-		event.MaxPicksPerTeam,
-		event.MaxTeamsPerPlayer,
-		event.Stipulations,
-		event.Status,
-		event.Passkey,
-		event.EventDate,
-	).Scan(&event.ID, &event.CreatedAt)
-
-	return err
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := checkPasskeyFree(ctx, tx, 0, event.Status, event.Passkey); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, query,
+			event.Name,
+			event.MaxPicksPerTeam,
+			event.MaxTeamsPerPlayer,
+			event.Stipulations,
+			event.Status,
+			passkeyDigest(event.Passkey),
+			event.EventDate,
+		).Scan(&event.ID, &event.CreatedAt)
+	})
 }
 
 // Update record in events table
+// A nil passkey leaves the existing passkey unchanged, except when reopening a
+// completed event that has one (ErrPasskeyRequired)
 func (r *EventRepository) Update(ctx context.Context, event *models.Event) error {
 	query := `
-		UPDATE events SET name=$1, max_picks_per_team=$2, max_teams_per_player=$3, stipulations=$4, status=$5, passkey=$6, event_date=$7
+		UPDATE events SET name=$1, max_picks_per_team=$2, max_teams_per_player=$3, stipulations=$4, status=$5,
+		       passkey_hash=COALESCE(crypt($6, gen_salt('bf', 10)), passkey_hash), event_date=$7
 		WHERE id=$8
 	`
 
-	commandTag, err := r.pool.Exec(ctx, query,
-		event.Name,
-		event.MaxPicksPerTeam,
-		event.MaxTeamsPerPlayer,
-		event.Stipulations,
-		event.Status,
-		event.Passkey,
-		event.EventDate,
-		event.ID,
-	)
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := checkPasskeyFree(ctx, tx, event.ID, event.Status, event.Passkey); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		commandTag, err := tx.Exec(ctx, query,
+			event.Name,
+			event.MaxPicksPerTeam,
+			event.MaxTeamsPerPlayer,
+			event.Stipulations,
+			event.Status,
+			passkeyDigest(event.Passkey),
+			event.EventDate,
+			event.ID,
+		)
+		if err != nil {
+			return err
+		}
 
-	if commandTag.RowsAffected() == 0 {
-		return pgx.ErrNoRows
-	}
+		if commandTag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // Delete record from events table
@@ -156,26 +174,32 @@ func (r *EventRepository) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-// GetByPasskey retrieves an event by its passkey
+// GetByPasskey retrieves the active event with a passkey; completed events
+// can't be joined. Hashes are salted, so the stored hash of every active event
+// is checked, but only theirs: the events are filtered before crypt runs.
 func (r *EventRepository) GetByPasskey(ctx context.Context, passkey string) (*models.Event, error) {
 	query := `
+		WITH active AS MATERIALIZED (
+			SELECT id, name, max_picks_per_team, max_teams_per_player,
+			       stipulations, status, event_date, created_at, started_at, completed_at, passkey_hash
+			FROM events
+			WHERE status <> 'completed' AND passkey_hash IS NOT NULL
+		)
 		SELECT id, name, max_picks_per_team, max_teams_per_player,
-		       stipulations, status, passkey, event_date, created_at, started_at, completed_at
-		FROM events
-		WHERE passkey = $1
-		ORDER BY id DESC
+		       stipulations, status, event_date, created_at, started_at, completed_at
+		FROM active
+		WHERE passkey_hash = crypt($1, passkey_hash)
 		LIMIT 1
 	`
 
 	var event models.Event
-	err := r.pool.QueryRow(ctx, query, passkey).Scan(
+	err := r.pool.QueryRow(ctx, query, auth.HashSecret(passkey)).Scan(
 		&event.ID,
 		&event.Name,
 		&event.MaxPicksPerTeam,
 		&event.MaxTeamsPerPlayer,
 		&event.Stipulations,
 		&event.Status,
-		&event.Passkey,
 		&event.EventDate,
 		&event.CreatedAt,
 		&event.StartedAt,
@@ -192,6 +216,7 @@ func (r *EventRepository) GetByPasskey(ctx context.Context, passkey string) (*mo
 // UpdateStatus updates only the status field and corresponding timestamp
 // For "in_progress" status, sets started_at to now
 // For "completed" status, sets completed_at to now
+// A completed event with a passkey can't be reopened here (ErrPasskeyRequired)
 func (r *EventRepository) UpdateStatus(ctx context.Context, eventID int, status string) error {
 	var query string
 	switch status {
@@ -203,23 +228,29 @@ func (r *EventRepository) UpdateStatus(ctx context.Context, eventID int, status
 		query = `UPDATE events SET status = $1 WHERE id = $2`
 	}
 
-	commandTag, err := r.pool.Exec(ctx, query, status, eventID)
-	if err != nil {
-		return err
-	}
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := checkPasskeyFree(ctx, tx, eventID, status, nil); err != nil {
+			return err
+		}
 
-	if commandTag.RowsAffected() == 0 {
-		return pgx.ErrNoRows
-	}
+		commandTag, err := tx.Exec(ctx, query, status, eventID)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if commandTag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return nil
+	})
 }
 
 // GetNextUpcoming returns the next event whose event_date is in the future and status is not_started.
 func (r *EventRepository) GetNextUpcoming(ctx context.Context) (*models.Event, error) {
 	query := `
 		SELECT id, name, max_picks_per_team, max_teams_per_player,
-		       stipulations, status, passkey, event_date, created_at, started_at, completed_at
+		       stipulations, status, event_date, created_at, started_at, completed_at
 		FROM events
 		WHERE event_date > NOW() AND status = 'not_started'
 		ORDER BY event_date ASC
@@ -234,7 +265,6 @@ func (r *EventRepository) GetNextUpcoming(ctx context.Context) (*models.Event, e
 		&event.MaxTeamsPerPlayer,
 		&event.Stipulations,
 		&event.Status,
-		&event.Passkey,
 		&event.EventDate,
 		&event.CreatedAt,
 		&event.StartedAt,
@@ -247,3 +277,97 @@ func (r *EventRepository) GetNextUpcoming(ctx context.Context) (*models.Event, e
 
 	return &event, nil
 }
+
+// SetSpectatorTokenHash stores the spectator link token hash for an event,
+// replacing (and so revoking) any previous spectator link
+func (r *EventRepository) SetSpectatorTokenHash(ctx context.Context, eventID int, hash string) error {
+	query := `UPDATE events SET spectator_token_hash = $1 WHERE id = $2`
+
+	commandTag, err := r.pool.Exec(ctx, query, hash, eventID)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetEventIDBySpectatorToken returns the ID of the event a spectator token belongs to
+// (implements draft.SpectatorAuthenticator interface)
+func (r *EventRepository) GetEventIDBySpectatorToken(ctx context.Context, token string) (int, error) {
+	query := `SELECT id FROM events WHERE spectator_token_hash = $1`
+
+	var eventID int
+	err := r.pool.QueryRow(ctx, query, auth.HashSecret(token)).Scan(&eventID)
+	if err != nil {
+		return 0, err
+	}
+
+	return eventID, nil
+}
+
+// passkeyDigest returns the SHA-256 digest of an optional plaintext passkey.
+// The database stores a salted bcrypt hash of the digest.
+func passkeyDigest(passkey *string) *string {
+	if passkey == nil || *passkey == "" {
+		return nil
+	}
+	digest := auth.HashSecret(*passkey)
+	return &digest
+}
+
+// checkPasskeyFree returns ErrPasskeyInUse if saving an event with status and
+// passkey would give it the same passkey as another active event: a new passkey,
+// or its stored one if a completed event is reopened. Salted hashes can't carry
+// a unique index or be compared with each other, so the plaintext passkey is
+// looked up under a lock held until tx ends. Reopening without it returns
+// ErrPasskeyRequired. eventID is 0 for a new event; a nil passkey keeps the stored one.
+func checkPasskeyFree(ctx context.Context, tx pgx.Tx, eventID int, status string, passkey *string) error {
+	if status == models.EventStatusCompleted {
+		return nil
+	}
+
+	digest := passkeyDigest(passkey)
+	if digest == nil {
+		if eventID == 0 {
+			return nil
+		}
+		var reopening bool
+		err := tx.QueryRow(ctx, `
+			SELECT status = 'completed' AND passkey_hash IS NOT NULL FROM events WHERE id = $1 FOR UPDATE
+		`, eventID).Scan(&reopening)
+		if err == pgx.ErrNoRows {
+			return nil // The update reports the missing event
+		}
+		if err != nil {
+			return err
+		}
+		if reopening {
+			return ErrPasskeyRequired
+		}
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('events.passkey_hash'))`); err != nil {
+		return err
+	}
+
+	query := `
+		WITH active AS MATERIALIZED (
+			SELECT passkey_hash FROM events
+			WHERE id <> $1 AND status <> 'completed' AND passkey_hash IS NOT NULL
+		)
+		SELECT EXISTS (SELECT 1 FROM active WHERE passkey_hash = crypt($2, passkey_hash))
+	`
+	var inUse bool
+	if err := tx.QueryRow(ctx, query, eventID, *digest).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrPasskeyInUse
+	}
+	return nil
+}
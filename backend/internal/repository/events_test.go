@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// createEvent creates an event with a passkey and status
+func createEvent(t *testing.T, repo *EventRepository, passkey, status string) *models.Event {
+	t.Helper()
+	event := &models.Event{Name: "Test " + passkey, MaxPicksPerTeam: 6, MaxTeamsPerPlayer: 1, Status: status}
+	if passkey != "" {
+		event.Passkey = &passkey
+	}
+	if err := repo.Create(context.Background(), event); err != nil {
+		t.Fatalf("creating event: %v", err)
+	}
+	return event
+}
+
+func TestPasskeysAreSaltedAndMatched(t *testing.T) {
+	pool := dbtest.New(t)
+	repo := NewEventRepository(pool)
+	ctx := context.Background()
+
+	first := createEvent(t, repo, "dye", models.EventStatusCompleted)
+	second := createEvent(t, repo, "dye", models.EventStatusNotStarted)
+
+	rows, err := pool.Query(ctx, `SELECT passkey_hash FROM events WHERE id IN ($1, $2)`, first.ID, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 || hashes[0] == hashes[1] || !strings.HasPrefix(hashes[0], "$2a$") {
+		t.Fatalf("stored passkey hashes %q, want two different bcrypt hashes", hashes)
+	}
+
+	// Only the active event is matched
+	event, err := repo.GetByPasskey(ctx, "dye")
+	if err != nil || event.ID != second.ID {
+		t.Fatalf("GetByPasskey: event %v, %v; want %d", event, err, second.ID)
+	}
+	if _, err := repo.GetByPasskey(ctx, "Dye"); err != pgx.ErrNoRows {
+		t.Fatalf("GetByPasskey with the wrong passkey: %v, want no rows", err)
+	}
+
+	// Completed events can't be joined
+	if err := repo.UpdateStatus(ctx, second.ID, models.EventStatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if event, err := repo.GetByPasskey(ctx, "dye"); err != pgx.ErrNoRows {
+		t.Fatalf("GetByPasskey with only completed events: event %v, %v; want no rows", event, err)
+	}
+}
+
+func TestPasskeyUniqueAmongActiveEvents(t *testing.T) {
+	repo := NewEventRepository(dbtest.New(t))
+	ctx := context.Background()
+
+	active := createEvent(t, repo, "dye", models.EventStatusInProgress)
+	createEvent(t, repo, "dye", models.EventStatusCompleted)
+
+	taken := &models.Event{Name: "Taken", MaxPicksPerTeam: 6, MaxTeamsPerPlayer: 1, Status: models.EventStatusNotStarted}
+	passkey := "dye"
+	taken.Passkey = &passkey
+	if err := repo.Create(ctx, taken); !errors.Is(err, ErrPasskeyInUse) {
+		t.Fatalf("creating a second active event with the passkey: %v, want ErrPasskeyInUse", err)
+	}
+
+	// An event keeps its own passkey on update
+	active.Passkey = &passkey
+	if err := repo.Update(ctx, active); err != nil {
+		t.Fatalf("updating the event with its own passkey: %v", err)
+	}
+
+	other := createEvent(t, repo, "sawgrass", models.EventStatusNotStarted)
+	other.Passkey = &passkey
+	if err := repo.Update(ctx, other); !errors.Is(err, ErrPasskeyInUse) {
+		t.Fatalf("updating another active event to the passkey: %v, want ErrPasskeyInUse", err)
+	}
+}
+
+func TestReopeningAnEventChecksItsPasskey(t *testing.T) {
+	repo := NewEventRepository(dbtest.New(t))
+	ctx := context.Background()
+
+	completed := createEvent(t, repo, "dye", models.EventStatusCompleted)
+	createEvent(t, repo, "dye", models.EventStatusNotStarted)
+
+	// The stored passkey can't be checked without the plaintext
+	if err := repo.UpdateStatus(ctx, completed.ID, models.EventStatusInProgress); !errors.Is(err, ErrPasskeyRequired) {
+		t.Fatalf("reopening through UpdateStatus: %v, want ErrPasskeyRequired", err)
+	}
+	completed.Status = models.EventStatusNotStarted
+	completed.Passkey = nil
+	if err := repo.Update(ctx, completed); !errors.Is(err, ErrPasskeyRequired) {
+		t.Fatalf("reopening through Update without the passkey: %v, want ErrPasskeyRequired", err)
+	}
+
+	passkey := "dye"
+	completed.Passkey = &passkey
+	if err := repo.Update(ctx, completed); !errors.Is(err, ErrPasskeyInUse) {
+		t.Fatalf("reopening with a passkey another active event uses: %v, want ErrPasskeyInUse", err)
+	}
+	passkey = "sawgrass"
+	if err := repo.Update(ctx, completed); err != nil {
+		t.Fatalf("reopening with a new passkey: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+// ErrInviteUsedUp is returned when an invite has expired or has no uses left
+var ErrInviteUsedUp = errors.New("invite has expired or already been used")
+
+type InviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInviteRepository(pool *pgxpool.Pool) *InviteRepository {
+	return &InviteRepository{pool: pool}
+}
+
+// Create inserts a new invite for an event
+func (r *InviteRepository) Create(ctx context.Context, invite *models.Invite) error {
+	query := `
+		INSERT INTO invites (event_id, token_hash, team_name, max_uses, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, use_count, created_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		invite.EventID,
+		invite.TokenHash,
+		invite.TeamName,
+		invite.MaxUses,
+		invite.ExpiresAt,
+	).Scan(&invite.ID, &invite.UseCount, &invite.CreatedAt)
+
+	return err
+}
+
+// GetByTokenHash retrieves an invite by the hash of its token
+func (r *InviteRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invite, error) {
+	query := `
+		SELECT id, event_id, token_hash, team_name, max_uses, use_count, expires_at, created_at
+		FROM invites
+		WHERE token_hash = $1
+	`
+
+	var invite models.Invite
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&invite.ID,
+		&invite.EventID,
+		&invite.TokenHash,
+		&invite.TeamName,
+		&invite.MaxUses,
+		&invite.UseCount,
+		&invite.ExpiresAt,
+		&invite.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
@@ -18,7 +18,7 @@ func NewPlayerRepository(pool *pgxpool.Pool) *PlayerRepository {
 
 func (r *PlayerRepository) GetByID(ctx context.Context, id int) (*models.Player, error) {
 	query := `
-		SELECT id, first_name, last_name, status, country_code
+		SELECT id, first_name, last_name, status, country_code, ranking
 		FROM players
 		WHERE id = $1
 	`
@@ -30,6 +30,7 @@ func (r *PlayerRepository) GetByID(ctx context.Context, id int) (*models.Player,
 		&player.LastName,
 		&player.Status,
 		&player.CountryCode,
+		&player.Ranking,
 	)
 
 	if err != nil {
@@ -42,7 +43,7 @@ func (r *PlayerRepository) GetByID(ctx context.Context, id int) (*models.Player,
 // Retrieves all players
 func (r *PlayerRepository) GetAll(ctx context.Context) ([]models.Player, error) {
 	query := `
-		SELECT id, first_name, last_name, status, country_code
+		SELECT id, first_name, last_name, status, country_code, ranking
 		FROM players
 	`
 
@@ -61,6 +62,7 @@ func (r *PlayerRepository) GetAll(ctx context.Context) ([]models.Player, error)
 			&player.LastName,
 			&player.Status,
 			&player.CountryCode,
+			&player.Ranking,
 		)
 		if err != nil {
 			return nil, err
@@ -74,8 +76,8 @@ func (r *PlayerRepository) GetAll(ctx context.Context) ([]models.Player, error)
 // Create new record in players table
 func (r *PlayerRepository) Create(ctx context.Context, player *models.Player) error {
 	query := `
-		INSERT INTO players (first_name, last_name, status, country_code)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO players (first_name, last_name, status, country_code, ranking)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
 	err := r.pool.QueryRow(ctx, query,
@@ -83,6 +85,7 @@ func (r *PlayerRepository) Create(ctx context.Context, player *models.Player) er
 		player.LastName,
 		player.Status,
 		player.CountryCode,
+		player.Ranking,
 	).Scan(&player.ID)
 
 	return err
@@ -91,8 +94,8 @@ func (r *PlayerRepository) Create(ctx context.Context, player *models.Player) er
 // Update record in players table
 func (r *PlayerRepository) Update(ctx context.Context, player *models.Player) error {
 	query := `
-		UPDATE players SET first_name=$1, last_name=$2, status=$3, country_code=$4
-		WHERE id=$5
+		UPDATE players SET first_name=$1, last_name=$2, status=$3, country_code=$4, ranking=$5
+		WHERE id=$6
 	`
 
 	commandTag, err := r.pool.Exec(ctx, query,
@@ -100,6 +103,7 @@ func (r *PlayerRepository) Update(ctx context.Context, player *models.Player) er
 		player.LastName,
 		player.Status,
 		player.CountryCode,
+		player.Ranking,
 		player.ID,
 	)
 
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+type TeamMemberRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTeamMemberRepository(pool *pgxpool.Pool) *TeamMemberRepository {
+	return &TeamMemberRepository{pool: pool}
+}
+
+// Create new record in team_members table
+func (r *TeamMemberRepository) Create(ctx context.Context, member *models.TeamMember) error {
+	query := `
+		INSERT INTO team_members (user_id, name, token_hash)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		member.UserID,
+		member.Name,
+		member.TokenHash,
+	).Scan(&member.ID, &member.CreatedAt)
+
+	return err
+}
+
+// GetByUserAndName finds a team member by team (user) ID and name
+func (r *TeamMemberRepository) GetByUserAndName(ctx context.Context, userID int, name string) (*models.TeamMember, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, created_at
+		FROM team_members
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2)
+	`
+
+	var member models.TeamMember
+	err := r.pool.QueryRow(ctx, query, userID, name).Scan(
+		&member.ID,
+		&member.UserID,
+		&member.Name,
+		&member.TokenHash,
+		&member.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// GetByUser retrieves all members of a team
+func (r *TeamMemberRepository) GetByUser(ctx context.Context, userID int) ([]models.TeamMember, error) {
+	query := `
+		SELECT id, user_id, name, created_at
+		FROM team_members
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.TeamMember{}
+	for rows.Next() {
+		var member models.TeamMember
+		if err := rows.Scan(
+			&member.ID,
+			&member.UserID,
+			&member.Name,
+			&member.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// SetTokenHash stores a member's token hash, replacing (and so revoking) any previous token
+func (r *TeamMemberRepository) SetTokenHash(ctx context.Context, id int, hash string) error {
+	query := `UPDATE team_members SET token_hash = $1 WHERE id = $2`
+
+	commandTag, err := r.pool.Exec(ctx, query, hash, id)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountByUser returns the number of members on a team
+func (r *TeamMemberRepository) CountByUser(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM team_members WHERE user_id = $1`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
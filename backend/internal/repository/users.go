@@ -5,6 +5,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
 	"github.com/sblackwood23/fantasy-draft-app/internal/models"
 )
 
@@ -18,7 +19,7 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	query := `
-		SELECT id, event_id, username, created_at
+		SELECT id, event_id, username, created_at, rejoin_token_hash
 		FROM users
 		WHERE id = $1
 	`
@@ -29,6 +30,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, err
 		&user.EventID,
 		&user.Username,
 		&user.CreatedAt,
+		&user.RejoinTokenHash,
 	)
 
 	if err != nil {
@@ -72,18 +74,67 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 // Create new record in users table
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (event_id, username)
-		VALUES ($1, $2)
+		INSERT INTO users (event_id, username, rejoin_token_hash)
+		VALUES ($1, $2, $3)
 		RETURNING id, created_at
 	`
 	err := r.pool.QueryRow(ctx, query,
 		user.EventID,
 		user.Username,
+		user.RejoinTokenHash,
 	).Scan(&user.ID, &user.CreatedAt)
 
 	return err
 }
 
+// RegisterTeam creates a team and its founding member in one transaction,
+// first consuming one use of the invite it registers with, if any. Nothing is
+// saved if any step fails, so a failed registration doesn't use up the invite.
+// Returns ErrInviteUsedUp if the invite has expired or has no uses left.
+func (r *UserRepository) RegisterTeam(ctx context.Context, user *models.User, member *models.TeamMember, inviteID *int) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if inviteID != nil {
+			query := `
+				UPDATE invites SET use_count = use_count + 1
+				WHERE id = $1 AND use_count < max_uses AND expires_at > NOW()
+			`
+			commandTag, err := tx.Exec(ctx, query, *inviteID)
+			if err != nil {
+				return err
+			}
+			if commandTag.RowsAffected() == 0 {
+				return ErrInviteUsedUp
+			}
+		}
+
+		query := `
+			INSERT INTO users (event_id, username, rejoin_token_hash)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at
+		`
+		err := tx.QueryRow(ctx, query,
+			user.EventID,
+			user.Username,
+			user.RejoinTokenHash,
+		).Scan(&user.ID, &user.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		member.UserID = user.ID
+		query = `
+			INSERT INTO team_members (user_id, name, token_hash)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at
+		`
+		return tx.QueryRow(ctx, query,
+			member.UserID,
+			member.Name,
+			member.TokenHash,
+		).Scan(&member.ID, &member.CreatedAt)
+	})
+}
+
 // Update record in users table
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
@@ -128,9 +179,10 @@ func (r *UserRepository) Delete(ctx context.Context, id int) error {
 }
 
 // GetByEventAndUsername finds a user by event ID and username
+// The returned user includes the rejoin token hash for credential checks
 func (r *UserRepository) GetByEventAndUsername(ctx context.Context, eventID int, username string) (*models.User, error) {
 	query := `
-		SELECT id, event_id, username, created_at
+		SELECT id, event_id, username, created_at, rejoin_token_hash
 		FROM users
 		WHERE event_id = $1 AND LOWER(username) = LOWER($2)
 	`
@@ -141,6 +193,49 @@ func (r *UserRepository) GetByEventAndUsername(ctx context.Context, eventID int,
 		&user.EventID,
 		&user.Username,
 		&user.CreatedAt,
+		&user.RejoinTokenHash,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SetRejoinTokenHash stores the rejoin token hash for a user,
+// replacing (and so revoking) any previous rejoin token
+func (r *UserRepository) SetRejoinTokenHash(ctx context.Context, id int, hash string) error {
+	query := `UPDATE users SET rejoin_token_hash = $1 WHERE id = $2`
+
+	commandTag, err := r.pool.Exec(ctx, query, hash, id)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetUserByRejoinToken retrieves the team a rejoin token belongs to
+// (implements draft.TeamAuthenticator interface)
+func (r *UserRepository) GetUserByRejoinToken(ctx context.Context, token string) (*models.User, error) {
+	query := `
+		SELECT id, event_id, username, created_at, rejoin_token_hash
+		FROM users
+		WHERE rejoin_token_hash = $1
+	`
+
+	var user models.User
+	err := r.pool.QueryRow(ctx, query, auth.HashSecret(token)).Scan(
+		&user.ID,
+		&user.EventID,
+		&user.Username,
+		&user.CreatedAt,
+		&user.RejoinTokenHash,
 	)
 
 	if err != nil {
@@ -150,6 +245,39 @@ func (r *UserRepository) GetByEventAndUsername(ctx context.Context, eventID int,
 	return &user, nil
 }
 
+// GetUserByMemberToken retrieves the team member a member token belongs to, and their team
+// (implements draft.TeamAuthenticator interface)
+func (r *UserRepository) GetUserByMemberToken(ctx context.Context, token string) (*models.User, *models.TeamMember, error) {
+	query := `
+		SELECT u.id, u.event_id, u.username, u.created_at, u.rejoin_token_hash,
+		       m.id, m.user_id, m.name, m.created_at, m.token_hash
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.token_hash = $1
+	`
+
+	var user models.User
+	var member models.TeamMember
+	err := r.pool.QueryRow(ctx, query, auth.HashSecret(token)).Scan(
+		&user.ID,
+		&user.EventID,
+		&user.Username,
+		&user.CreatedAt,
+		&user.RejoinTokenHash,
+		&member.ID,
+		&member.UserID,
+		&member.Name,
+		&member.CreatedAt,
+		&member.TokenHash,
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &user, &member, nil
+}
+
 // GetByEventID retrieves all users for a specific event
 func (r *UserRepository) GetByEventID(ctx context.Context, eventID int) ([]models.User, error) {
 	query := `
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sblackwood23/fantasy-draft-app/internal/auth"
+	"github.com/sblackwood23/fantasy-draft-app/internal/database/dbtest"
+	"github.com/sblackwood23/fantasy-draft-app/internal/models"
+)
+
+func TestGetUserByRejoinToken(t *testing.T) {
+	pool := dbtest.New(t)
+	users := NewUserRepository(pool)
+	ctx := context.Background()
+
+	event := createEvent(t, NewEventRepository(pool), "dye", models.EventStatusNotStarted)
+	hash := auth.HashSecret("team-token")
+	user := &models.User{EventID: event.ID, Username: "Team 1", RejoinTokenHash: &hash}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := users.GetUserByRejoinToken(ctx, "team-token")
+	if err != nil || found.ID != user.ID || found.EventID != event.ID {
+		t.Fatalf("GetUserByRejoinToken: %+v, %v; want user %d", found, err, user.ID)
+	}
+	if _, err := users.GetUserByRejoinToken(ctx, "other-token"); err == nil {
+		t.Fatal("GetUserByRejoinToken found a team for an unknown token")
+	}
+
+	// Issuing a new token revokes the old one
+	if err := users.SetRejoinTokenHash(ctx, user.ID, auth.HashSecret("new-token")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetUserByRejoinToken(ctx, "team-token"); err == nil {
+		t.Fatal("the old rejoin token still works")
+	}
+}
+
+func TestRegisterTeamLeavesInviteUnusedOnFailure(t *testing.T) {
+	pool := dbtest.New(t)
+	users := NewUserRepository(pool)
+	ctx := context.Background()
+
+	event := createEvent(t, NewEventRepository(pool), "dye", models.EventStatusNotStarted)
+	invite := &models.Invite{EventID: event.ID, TokenHash: auth.HashSecret("invite"), MaxUses: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := NewInviteRepository(pool).Create(ctx, invite); err != nil {
+		t.Fatal(err)
+	}
+
+	// The member insert fails after the invite is redeemed: names are VARCHAR(100)
+	user := &models.User{EventID: event.ID, Username: "Team 1"}
+	err := users.RegisterTeam(ctx, user, &models.TeamMember{Name: strings.Repeat("x", 101)}, &invite.ID)
+	if err == nil {
+		t.Fatal("RegisterTeam succeeded with a member name that's too long")
+	}
+	if count, _ := users.CountByEvent(ctx, event.ID); count != 0 {
+		t.Fatalf("%d teams saved by a failed registration", count)
+	}
+
+	member := &models.TeamMember{Name: "Sam"}
+	if err := users.RegisterTeam(ctx, user, member, &invite.ID); err != nil {
+		t.Fatalf("RegisterTeam after the failure: %v", err)
+	}
+	if member.UserID != user.ID {
+		t.Fatalf("member belongs to user %d, want %d", member.UserID, user.ID)
+	}
+	if err := users.RegisterTeam(ctx, &models.User{EventID: event.ID, Username: "Team 2"}, &models.TeamMember{Name: "Alex"}, &invite.ID); err != ErrInviteUsedUp {
+		t.Fatalf("RegisterTeam on a used-up invite: %v, want ErrInviteUsedUp", err)
+	}
+}
@@ -0,0 +1,10 @@
+-- Remove rejoin tokens from users table
+ALTER TABLE users DROP COLUMN IF EXISTS rejoin_token_hash;
+
+-- Remove active passkey uniqueness
+DROP INDEX IF EXISTS idx_events_passkey_hash_active;
+
+-- Hashes cannot be reversed, so existing passkeys are cleared and must be set again
+ALTER TABLE events RENAME COLUMN passkey_hash TO passkey;
+ALTER TABLE events ALTER COLUMN passkey TYPE VARCHAR(100);
+UPDATE events SET passkey = NULL;
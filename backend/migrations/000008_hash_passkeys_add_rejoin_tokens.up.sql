@@ -0,0 +1,10 @@
+-- Store event passkeys as SHA-256 hashes instead of plaintext
+ALTER TABLE events RENAME COLUMN passkey TO passkey_hash;
+UPDATE events SET passkey_hash = encode(sha256(convert_to(passkey_hash, 'UTF8')), 'hex') WHERE passkey_hash IS NOT NULL;
+ALTER TABLE events ALTER COLUMN passkey_hash TYPE VARCHAR(64);
+
+-- Passkeys must be unique among events that are not yet completed
+CREATE UNIQUE INDEX idx_events_passkey_hash_active ON events(passkey_hash) WHERE status <> 'completed';
+
+-- Hashed rejoin token issued to a team the first time it joins
+ALTER TABLE users ADD COLUMN rejoin_token_hash VARCHAR(64);
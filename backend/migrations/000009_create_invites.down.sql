@@ -0,0 +1,2 @@
+-- Drop invites table
+DROP TABLE IF EXISTS invites;
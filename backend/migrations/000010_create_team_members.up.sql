@@ -0,0 +1,14 @@
+-- Create team_members table so several people can manage one team (users row)
+CREATE TABLE team_members (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Member names are unique per team, ignoring case
+CREATE UNIQUE INDEX idx_team_members_user_name ON team_members(user_id, LOWER(name));
+
+-- Existing teams get a founding member named after the team
+INSERT INTO team_members (user_id, name)
+SELECT id, username FROM users;
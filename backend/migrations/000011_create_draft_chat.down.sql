@@ -0,0 +1,2 @@
+-- Drop draft_chat table
+DROP TABLE IF EXISTS draft_chat;
@@ -0,0 +1,13 @@
+-- Create draft_chat table for in-draft chat messages
+CREATE TABLE draft_chat (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    member_id INTEGER REFERENCES team_members(id) ON DELETE SET NULL,
+    sender_name VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for loading recent chat history by event
+CREATE INDEX idx_draft_chat_event_created ON draft_chat(event_id, created_at);
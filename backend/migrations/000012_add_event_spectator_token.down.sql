@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_events_spectator_token_hash;
+ALTER TABLE events DROP COLUMN spectator_token_hash;
@@ -0,0 +1,3 @@
+-- Add hashed spectator link token so non-members can watch a draft read-only
+ALTER TABLE events ADD COLUMN spectator_token_hash VARCHAR(64);
+CREATE UNIQUE INDEX idx_events_spectator_token_hash ON events(spectator_token_hash);
@@ -0,0 +1,2 @@
+-- Remove world ranking from players
+ALTER TABLE players DROP COLUMN IF EXISTS ranking;
@@ -0,0 +1,2 @@
+-- Add world ranking to players, used to judge draft picks as reaches or values
+ALTER TABLE players ADD COLUMN ranking INTEGER CHECK (ranking > 0);
@@ -0,0 +1,3 @@
+-- Remove turn times from draft_results
+ALTER TABLE draft_results DROP COLUMN IF EXISTS turn_deadline;
+ALTER TABLE draft_results DROP COLUMN IF EXISTS turn_started_at;
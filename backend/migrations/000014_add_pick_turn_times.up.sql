@@ -0,0 +1,3 @@
+-- Record when each pick's turn started and its deadline, so pick durations are exact
+ALTER TABLE draft_results ADD COLUMN turn_started_at TIMESTAMP;
+ALTER TABLE draft_results ADD COLUMN turn_deadline TIMESTAMP;
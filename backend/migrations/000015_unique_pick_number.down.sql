@@ -0,0 +1,3 @@
+-- Restore the non-unique pick number index
+ALTER TABLE draft_results DROP CONSTRAINT IF EXISTS draft_results_event_pick_number_key;
+CREATE INDEX idx_draft_results_pick_number ON draft_results(event_id, pick_number);
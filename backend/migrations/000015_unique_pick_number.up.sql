@@ -0,0 +1,3 @@
+-- Each pick number is made once per event; picks are saved idempotently on it
+DROP INDEX IF EXISTS idx_draft_results_pick_number;
+ALTER TABLE draft_results ADD CONSTRAINT draft_results_event_pick_number_key UNIQUE (event_id, pick_number);
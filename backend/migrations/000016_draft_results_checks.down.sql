@@ -0,0 +1,3 @@
+-- Remove the pick number and round checks
+ALTER TABLE draft_results DROP CONSTRAINT IF EXISTS draft_results_round_check;
+ALTER TABLE draft_results DROP CONSTRAINT IF EXISTS draft_results_pick_number_check;
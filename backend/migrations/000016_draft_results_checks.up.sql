@@ -0,0 +1,3 @@
+-- Back SavePick's validation with constraints: pick numbers and rounds start at 1
+ALTER TABLE draft_results ADD CONSTRAINT draft_results_pick_number_check CHECK (pick_number > 0);
+ALTER TABLE draft_results ADD CONSTRAINT draft_results_round_check CHECK (round > 0);
@@ -0,0 +1,2 @@
+-- Drop draft_events table
+DROP TABLE IF EXISTS draft_events;
@@ -0,0 +1,12 @@
+-- Create draft_events table: the append-only log of every change to a draft.
+-- created_at is the time the change took effect; turn deadlines are derived from it
+-- when the log is replayed, so it keeps its time zone.
+CREATE TABLE draft_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL CHECK (seq > 0),
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT draft_events_event_seq_key UNIQUE (event_id, seq)
+);
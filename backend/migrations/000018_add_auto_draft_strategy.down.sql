@@ -0,0 +1,3 @@
+-- Remove the auto-draft strategy from picks
+ALTER TABLE draft_results DROP CONSTRAINT IF EXISTS draft_results_auto_draft_strategy_check;
+ALTER TABLE draft_results DROP COLUMN IF EXISTS auto_draft_strategy;
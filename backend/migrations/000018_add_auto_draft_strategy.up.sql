@@ -0,0 +1,4 @@
+-- Record which auto-draft strategy chose each auto-drafted pick
+ALTER TABLE draft_results ADD COLUMN auto_draft_strategy VARCHAR(30);
+ALTER TABLE draft_results ADD CONSTRAINT draft_results_auto_draft_strategy_check
+    CHECK (auto_draft_strategy IS NULL OR is_auto_draft);
@@ -0,0 +1,5 @@
+-- Salted hashes cannot be turned back into digests, so existing passkeys are cleared and must be set again
+UPDATE events SET passkey_hash = NULL;
+
+-- Digests are unique among events that are not yet completed
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_passkey_hash_active ON events(passkey_hash) WHERE status <> 'completed';
@@ -0,0 +1,8 @@
+-- Store event passkeys as salted bcrypt hashes of their SHA-256 digest, so a
+-- leaked table can't be checked against a wordlist in one pass
+CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public;
+
+-- Salted hashes can't be indexed for uniqueness; the server checks it instead
+DROP INDEX IF EXISTS idx_events_passkey_hash_active;
+
+UPDATE events SET passkey_hash = public.crypt(passkey_hash, public.gen_salt('bf', 10)) WHERE passkey_hash IS NOT NULL;
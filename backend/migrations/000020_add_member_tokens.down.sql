@@ -0,0 +1,3 @@
+-- Remove member tokens
+DROP INDEX IF EXISTS idx_team_members_token_hash;
+ALTER TABLE team_members DROP COLUMN IF EXISTS token_hash;
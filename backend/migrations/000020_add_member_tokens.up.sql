@@ -0,0 +1,4 @@
+-- Hashed token issued to each team member when they join, so co-managers
+-- connect as themselves rather than with the team's shared rejoin token
+ALTER TABLE team_members ADD COLUMN token_hash VARCHAR(64);
+CREATE UNIQUE INDEX idx_team_members_token_hash ON team_members(token_hash);
@@ -4,6 +4,7 @@
 BEGIN;
 
 DELETE FROM draft_results;
+DELETE FROM draft_events;
 DELETE FROM users;
 
 UPDATE events
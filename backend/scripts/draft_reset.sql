@@ -4,8 +4,9 @@
 
 BEGIN;
 
--- Clear all draft picks
+-- Clear all draft picks and the draft log
 DELETE FROM draft_results;
+DELETE FROM draft_events;
 
 -- Reset all events back to not_started
 UPDATE events
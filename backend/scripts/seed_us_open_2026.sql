@@ -74,8 +74,8 @@ WHERE NOT EXISTS (
 );
 
 -- Create the event
-INSERT INTO events (name, max_picks_per_team, max_teams_per_player, status, passkey, event_date, stipulations)
-VALUES ('The United States Open 2026', 6, 1, 'not_started', :passkey, '2026-06-18 00:00:00-04'::timestamptz, '{"tournament": "US Open", "year": 2026}'::jsonb)
+INSERT INTO events (name, max_picks_per_team, max_teams_per_player, status, passkey_hash, event_date, stipulations)
+VALUES ('The United States Open 2026', 6, 1, 'not_started', crypt(encode(sha256(convert_to(:passkey, 'UTF8')), 'hex'), gen_salt('bf', 10)), '2026-06-18 00:00:00-04'::timestamptz, '{"tournament": "US Open", "year": 2026}'::jsonb)
 RETURNING id AS new_event_id;
 
 -- Link players to the event by name
@@ -252,7 +252,7 @@ COMMIT;
 -- Summary
 \echo ''
 \echo '=== New Event Created ==='
-SELECT id, name, passkey, max_picks_per_team, status
+SELECT id, name, max_picks_per_team, status
 FROM events ORDER BY id DESC LIMIT 1;
 
 SELECT COUNT(*) AS players_linked
@@ -5,14 +5,23 @@ const API_BASE = import.meta.env.VITE_API_BASE || '';
 interface FetchOptions {
   method?: 'GET' | 'POST' | 'PUT' | 'DELETE';
   body?: unknown;
+  token?: string; // Sent as a bearer token
 }
 
 async function fetchJSON<T>(url: string, options: FetchOptions = {}): Promise<T> {
-  const { method = 'GET', body } = options;
+  const { method = 'GET', body, token } = options;
+
+  const headers: Record<string, string> = {};
+  if (body) {
+    headers['Content-Type'] = 'application/json';
+  }
+  if (token) {
+    headers['Authorization'] = `Bearer ${token}`;
+  }
 
   const response = await fetch(`${API_BASE}${url}`, {
     method,
-    headers: body ? { 'Content-Type': 'application/json' } : undefined,
+    headers,
     body: body ? JSON.stringify(body) : undefined,
   });
 
@@ -56,10 +65,13 @@ export async function getUser(id: number): Promise<User> {
   return fetchJSON<User>(`/users/${id}`);
 }
 
-export async function joinDraft(teamName: string, passkey: string): Promise<User> {
+// memberToken proves this browser is the member it rejoins as; without it the
+// server won't replace an existing member's token
+export async function joinDraft(teamName: string, passkey: string, rejoinToken?: string, memberToken?: string): Promise<User> {
   return fetchJSON<User>(`/events/join`, {
     method: 'POST',
-    body: { teamName, passkey },
+    body: { teamName, passkey, rejoinToken },
+    token: memberToken,
   });
 }
 
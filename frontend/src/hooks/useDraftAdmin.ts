@@ -22,6 +22,7 @@ export function buildAutopickMessage(): { message: ClientMessage; playerID: numb
 }
 
 interface DraftAdmin {
+  login: (adminKey: string) => void;
   startDraft: (pickOrder: number[], totalRounds: number, timerDuration: number) => void;
   pause: () => void;
   resume: () => void;
@@ -41,6 +42,9 @@ declare global {
 export function useDraftAdmin(sendMessage: (message: ClientMessage) => void) {
   useEffect(() => {
     window.draftAdmin = {
+      login: (adminKey: string) => {
+        sendMessage({ type: 'authenticate', adminKey });
+      },
       startDraft: async (pickOrder: number[], totalRounds: number, timerDuration: number) => {
         const eventID = useLocalStore.getState().eventID ?? 0;
         try {
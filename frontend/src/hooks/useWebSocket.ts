@@ -1,5 +1,6 @@
 import { useCallback, useEffect, useRef } from 'react';
 import { useDraftStore } from '../store/draftStore';
+import { useLocalStore } from '../store/localStore';
 import type { ClientMessage, ServerMessage } from '../types';
 
 const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
@@ -43,12 +44,21 @@ export function useWebSocket(userID: number | null) {
       reconnectTimerRef.current = null;
     }
 
+    const memberToken = useLocalStore.getState().memberToken;
+    if (!memberToken) {
+      console.error('Cannot connect WebSocket: no member token');
+      return;
+    }
+
     setConnectionStatus('connecting');
-    const registeredUsers = useDraftStore.getState().registeredUsers;
-    const username = registeredUsers.find((u) => u.id === userID)?.username;
     const params = new URLSearchParams({ userID: String(userID) });
-    if (username) params.set('username', username);
-    const ws = new WebSocket(`${WS_BASE_URL}?${params}`);
+    // The draft room page opened with ?mockID= connects to that mock draft instead
+    const mockID = new URLSearchParams(window.location.search).get('mockID');
+    if (mockID) {
+      params.set('mockID', mockID);
+    }
+    // Browsers can't set headers on a WebSocket, so the token rides as a subprotocol
+    const ws = new WebSocket(`${WS_BASE_URL}?${params}`, ['draft', `bearer.${memberToken}`]);
 
     ws.onopen = () => {
       setConnectionStatus('connected');
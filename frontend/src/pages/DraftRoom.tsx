@@ -96,7 +96,8 @@ export function DraftRoom() {
 
   function pickPlayer(playerID: number) {
     if (userID != null) {
-      sendMessage({ type: 'make_pick', userID, playerID });
+      const pickNumber = useDraftStore.getState().pickHistory.length + 1;
+      sendMessage({ type: 'make_pick', userID, playerID, pickNumber });
     }
   }
 
@@ -7,6 +7,11 @@ export function JoinPage() {
   const navigate = useNavigate();
   const setEventID = useLocalStore((state) => state.setEventID);
   const setUserID = useLocalStore((state) => state.setUserID);
+  const setMember = useLocalStore((state) => state.setMember);
+  const rejoinTokens = useLocalStore((state) => state.rejoinTokens);
+  const setRejoinToken = useLocalStore((state) => state.setRejoinToken);
+  const memberToken = useLocalStore((state) => state.memberToken);
+  const setMemberToken = useLocalStore((state) => state.setMemberToken);
   const [teamName, setTeamName] = useState<string>('');
   const [passKey, setPassKey] = useState<string>('');
   const [error, setError] = useState<string | null>(null);
@@ -16,10 +21,19 @@ export function JoinPage() {
     if (!trimmedTeamName || !passKey) return;
     // Clear out error before attempting to join draft
     setError(null);
-    joinDraft(trimmedTeamName, passKey)
+    joinDraft(trimmedTeamName, passKey, rejoinTokens[trimmedTeamName.toLowerCase()], memberToken ?? undefined)
       .then((user) => {
+        if (user.rejoinToken) {
+          setRejoinToken(trimmedTeamName, user.rejoinToken);
+        }
+        if (user.memberToken) {
+          setMemberToken(user.memberToken);
+        }
         setEventID(user.eventID);
         setUserID(user.id);
+        if (user.member) {
+          setMember(user.member);
+        }
         navigate('/draft');
       })
       .catch((err: Error) => setError(err.message || 'Failed to join draft'));
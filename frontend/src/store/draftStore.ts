@@ -169,6 +169,10 @@ export const useDraftStore = create<DraftState>((set) => ({
         break;
 
       case 'user_left':
+        // Other co-managers of the team are still connected
+        if (message.members?.length) {
+          break;
+        }
         set((state) => ({
           connectedUsers: state.connectedUsers.filter(
             (u) => u.id !== message.userID
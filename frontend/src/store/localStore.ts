@@ -1,12 +1,21 @@
 import { create } from 'zustand';
 import { persist } from 'zustand/middleware';
+import type { TeamMember } from '../types';
 
 interface LocalState {
   eventID: number | null;
   userID: number | null;
+  member: TeamMember | null;
+  // Rejoin tokens keyed by lowercased team name, needed to reconnect as an existing team
+  rejoinTokens: Record<string, string>;
+  // Token of the member this browser is joined as; authenticates the WebSocket
+  memberToken: string | null;
   theme: 'dark' | 'light';
   setEventID: (eventID: number) => void;
   setUserID: (userID: number) => void;
+  setMember: (member: TeamMember) => void;
+  setRejoinToken: (teamName: string, token: string) => void;
+  setMemberToken: (token: string) => void;
   toggleTheme: () => void;
   clear: () => void;
 }
@@ -16,11 +25,18 @@ export const useLocalStore = create<LocalState>()(
     (set) => ({
       eventID: null,
       userID: null,
+      member: null,
+      rejoinTokens: {},
+      memberToken: null,
       theme: 'dark' as const,
       setEventID: (eventID) => set({ eventID }),
       setUserID: (userID) => set({ userID }),
+      setMember: (member) => set({ member }),
+      setRejoinToken: (teamName, token) =>
+        set((state) => ({ rejoinTokens: { ...state.rejoinTokens, [teamName.toLowerCase()]: token } })),
+      setMemberToken: (memberToken) => set({ memberToken }),
       toggleTheme: () => set((state) => ({ theme: state.theme === 'dark' ? 'light' : 'dark' })),
-      clear: () => set({ eventID: null, userID: null }),
+      clear: () => set({ eventID: null, userID: null, member: null, memberToken: null }),
     }),
     { name: 'draft-local-store' },
   ),
@@ -19,6 +19,7 @@ export interface Player {
   lastName: string;
   status: string;
   countryCode: string;
+  ranking?: number; // World ranking, 1 is best
 }
 
 export interface User {
@@ -26,6 +27,39 @@ export interface User {
   eventID: number;
   username: string;
   createdAt: string;
+  rejoinToken?: string; // Only present when the team is created or the commissioner issues a new token
+  member?: TeamMember; // Present in join responses
+  memberToken?: string; // Present in join responses; authenticates the member's WebSocket
+}
+
+export interface TeamMember {
+  id: number;
+  userID: number;
+  name: string;
+  createdAt: string;
+}
+
+export interface MemberPresence {
+  memberID: number;
+  name: string;
+}
+
+// Practice draft against bot teams; connect at /ws/draft?mockID={id}
+export interface MockDraft {
+  id: string;
+  eventID: number;
+  userID: number;
+  pickOrder: number[]; // Bots have negative user IDs
+  totalRounds: number;
+  timerDuration: number; // Seconds
+  bots: MockBot[];
+  expiresAt: string;
+}
+
+export interface MockBot {
+  userID: number;
+  username: string;
+  strategy: AutoDraftStrategy;
 }
 
 // Draft State
@@ -36,6 +70,13 @@ export interface Pick {
   pickNumber: number;
   round: number;
   autoDraft: boolean;
+  strategy?: AutoDraftStrategy; // Present on auto-drafted picks
+}
+
+export interface ScheduledBreak {
+  afterRound: number;
+  duration: number; // Seconds
+  reason?: string; // Defaults to "Break after round N"
 }
 
 // Player List Sorting
@@ -57,6 +98,7 @@ export interface StartDraftMessage {
   totalRounds: number;
   timerDuration: number;
   availablePlayers: number[];
+  scheduledBreaks?: ScheduledBreak[];
 }
 
 export interface MakePickMessage {
@@ -64,21 +106,51 @@ export interface MakePickMessage {
   userID: number;
   playerID: number;
   autoDraft?: boolean;
+  pickNumber?: number; // Rejected if this pick has already been made
 }
 
 export interface PauseDraftMessage {
   type: 'pause_draft';
+  reason?: string;
+  duration?: number; // Seconds until the draft resumes on its own
 }
 
 export interface ResumeDraftMessage {
   type: 'resume_draft';
 }
 
+export interface SetAutoPilotMessage {
+  type: 'set_auto_pilot';
+  enabled: boolean;
+}
+
+export interface SetQueueMessage {
+  type: 'set_queue';
+  playerIDs: number[];
+}
+
+export type AutoDraftStrategy = 'random' | 'queue' | 'best_available' | 'roster_need';
+
+export interface SetAutoDraftStrategyMessage {
+  type: 'set_auto_draft_strategy';
+  strategy: AutoDraftStrategy | ''; // Empty goes back to the default
+}
+
+// Makes this connection the commissioner's; sent as a message so the key stays out of URLs
+export interface AuthenticateMessage {
+  type: 'authenticate';
+  adminKey: string;
+}
+
 export type ClientMessage =
+  | AuthenticateMessage
   | StartDraftMessage
   | MakePickMessage
   | PauseDraftMessage
-  | ResumeDraftMessage;
+  | ResumeDraftMessage
+  | SetAutoPilotMessage
+  | SetQueueMessage
+  | SetAutoDraftStrategyMessage;
 
 // WebSocket Messages: Server -> Client
 
@@ -91,6 +163,7 @@ export interface DraftStartedMessage {
   pickOrder: number[];
   totalRounds: number;
   availablePlayers: number[];
+  scheduledBreaks?: ScheduledBreak[];
 }
 
 export interface PickMadeMessage {
@@ -99,6 +172,7 @@ export interface PickMadeMessage {
   playerID: number;
   round: number;
   autoDraft: boolean;
+  strategy?: AutoDraftStrategy; // Present on auto-drafted picks
 }
 
 export interface TurnChangedMessage {
@@ -119,6 +193,8 @@ export interface DraftPausedMessage {
   type: 'draft_paused';
   eventID: number;
   remainingTime: number;
+  reason: string;
+  resumeAt?: number; // Unix timestamp; absent if waiting for the commissioner
 }
 
 export interface DraftResumedMessage {
@@ -143,24 +219,93 @@ export interface DraftStateMessage {
   remainingTime: number;
   pickHistory: Pick[];
   connectedUserIDs: number[];
+  connectedMembers: Record<number, MemberPresence[]>;
+  pauseReason?: string;
+  resumeAt?: number;
+  scheduledBreaks?: ScheduledBreak[];
+  autoPilotUserIDs: number[];
+  queue?: number[]; // The receiving team's preference queue
+  strategy?: AutoDraftStrategy; // The receiving team's chosen auto-draft strategy
 }
 
 export interface UserJoinedMessage {
   type: 'user_joined';
   userID: number;
   username: string;
+  members: MemberPresence[];
 }
 
 export interface UserLeftMessage {
   type: 'user_left';
   userID: number;
+  memberID: number;
+  members: MemberPresence[]; // Members of the team still connected
+}
+
+export interface PresenceChangedMessage {
+  type: 'presence_changed';
+  userID: number;
+  status: 'idle' | 'active';
+  autoPilot: boolean;
+}
+
+// Sent to the team whose queue changed
+export interface QueueUpdatedMessage {
+  type: 'queue_updated';
+  userID: number;
+  playerIDs: number[];
 }
 
+// Sent to commissioners only
+export interface PersistenceFailedMessage {
+  type: 'persistence_failed';
+  eventID: number;
+  pickNumber: number; // Oldest pick not yet saved
+  pendingPicks: number;
+  error: string;
+  reason?: PickRejectionReason; // Present when the database rejected the pick
+}
+
+export type PickRejectionReason =
+  | 'EVENT_NOT_FOUND'
+  | 'PICK_CONFLICT'
+  | 'PICK_OUT_OF_SEQUENCE'
+  | 'TEAM_NOT_IN_EVENT'
+  | 'PLAYER_NOT_IN_EVENT'
+  | 'DUPLICATE_PLAYER'
+  | 'TEAM_FULL'
+  | 'PLAYER_TAKEN';
+
+// Sent to commissioners only
+export interface PersistenceRecoveredMessage {
+  type: 'persistence_recovered';
+  eventID: number;
+  pickNumber: number;
+  pendingPicks: number;
+}
+
+export type ErrorCode =
+  | 'NOT_YOUR_TURN'
+  | 'PLAYER_UNAVAILABLE'
+  | 'DRAFT_NOT_ACTIVE'
+  | 'RULE_VIOLATION'
+  | 'FORBIDDEN'
+  | 'RATE_LIMITED'
+  | 'INVALID_MESSAGE'
+  | 'INTERNAL_ERROR';
+
 export interface ErrorMessage {
   type: 'error';
+  requestID?: string; // Echoed from the message that failed
+  code: ErrorCode;
   error: string;
 }
 
+export interface AckMessage {
+  type: 'ack';
+  requestID: string;
+}
+
 export type ServerMessage =
   | DraftStartedMessage
   | PickMadeMessage
@@ -171,4 +316,9 @@ export type ServerMessage =
   | DraftStateMessage
   | UserJoinedMessage
   | UserLeftMessage
-  | ErrorMessage;
+  | PresenceChangedMessage
+  | QueueUpdatedMessage
+  | PersistenceFailedMessage
+  | PersistenceRecoveredMessage
+  | ErrorMessage
+  | AckMessage;